@@ -0,0 +1,251 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ckayt/tetra/internal/backup"
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/speed"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/ckayt/tetra/internal/storage"
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog/log"
+)
+
+// runCommand executes a CLI subcommand instead of starting the bot.
+func runCommand(name string, args []string) error {
+	switch name {
+	case "backup":
+		return backupCommand(args)
+	case "restore":
+		return restoreCommand(args)
+	case "export":
+		return exportCommand(args)
+	case "import":
+		return importCommand(args)
+	default:
+		return fmt.Errorf("unknown command '%s' (available: backup, restore, export, import)", name)
+	}
+}
+
+// backupEntries lists the files that make up tetra's state for the given config.
+func backupEntries(cfg *config.Config, envPath string) []backup.Entry {
+	entries := []backup.Entry{{Name: "env", Path: envPath}}
+	switch cfg.StorageBackend {
+	case storage.BackendMemory:
+	case storage.BackendBolt:
+		path := cfg.StoragePath
+		entries = append(entries, backup.Entry{Name: "results", Path: path, Read: func() ([]byte, error) { return storage.SnapshotBolt(path) }})
+	default:
+		entries = append(entries, backup.Entry{Name: "results", Path: cfg.StoragePath})
+	}
+	if cfg.TelegramQueuePath != "" {
+		entries = append(entries, backup.Entry{Name: "queue", Path: cfg.TelegramQueuePath})
+	}
+	if cfg.SettingsPath != "" {
+		entries = append(entries, backup.Entry{Name: "settings", Path: cfg.SettingsPath})
+	}
+	return entries
+}
+
+func backupCommand(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("o", fmt.Sprintf("tetra-backup-%s.tar.gz", time.Now().Format("20060102-150405")), "output archive path")
+	envPath := fs.String("env", ".env", "path to the .env file with config overrides")
+	_ = fs.Parse(args)
+
+	_ = godotenv.Load(*envPath)
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	m, err := backup.Create(*out, cfg.StorageBackend, backupEntries(cfg, *envPath))
+	if err != nil {
+		return err
+	}
+	for _, e := range m.Entries {
+		log.Info().Str("name", e.Name).Str("path", e.Path).Msg("Added to backup")
+	}
+	log.Info().Str("archive", *out).Msg("Backup created")
+	return nil
+}
+
+func restoreCommand(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	envPath := fs.String("env", ".env", "where to restore the .env file")
+	force := fs.Bool("force", false, "overwrite existing files")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: tetra restore [-env path] [-force] <archive>")
+	}
+
+	m, files, err := backup.Read(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	log.Info().Time("created", m.Created).Msg("Restoring backup")
+
+	// Config comes first: it decides where the remaining files go on this host
+	if data, ok := files["env"]; ok {
+		if err := backup.WriteEntry(*envPath, data, *force); err != nil {
+			return err
+		}
+		log.Info().Str("path", *envPath).Msg("Restored config")
+	}
+
+	_ = godotenv.Load(*envPath)
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if data, ok := files["results"]; ok {
+		if cfg.StorageBackend != m.StorageBackend {
+			return fmt.Errorf("backup contains %s results but STORAGE_BACKEND is %s", m.StorageBackend, cfg.StorageBackend)
+		}
+		if err := backup.WriteEntry(cfg.StoragePath, data, *force); err != nil {
+			return err
+		}
+		log.Info().Str("path", cfg.StoragePath).Msg("Restored results")
+	}
+
+	if data, ok := files["queue"]; ok {
+		if cfg.TelegramQueuePath == "" {
+			log.Warn().Msg("Backup contains a message queue but TELEGRAM_QUEUE_PATH is not set, skipping")
+		} else {
+			if err := backup.WriteEntry(cfg.TelegramQueuePath, data, *force); err != nil {
+				return err
+			}
+			log.Info().Str("path", cfg.TelegramQueuePath).Msg("Restored message queue")
+		}
+	}
+
+	if data, ok := files["settings"]; ok {
+		if cfg.SettingsPath == "" {
+			log.Warn().Msg("Backup contains runtime settings but SETTINGS_PATH is not set, skipping")
+		} else {
+			if err := backup.WriteEntry(cfg.SettingsPath, data, *force); err != nil {
+				return err
+			}
+			log.Info().Str("path", cfg.SettingsPath).Msg("Restored settings")
+		}
+	}
+
+	log.Info().Msg("Restore complete")
+	return nil
+}
+
+func exportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "parquet", "output format: parquet, csv or jsonl")
+	out := fs.String("o", "", "output file (default tetra-results.<format>)")
+	_ = fs.Parse(args)
+
+	if *out == "" {
+		*out = "tetra-results." + *format
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !storage.IsPersistent(cfg) {
+		return fmt.Errorf("STORAGE_BACKEND is memory, there is no stored history to export")
+	}
+	store, err := storage.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	history, err := store.Query(time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch *format {
+	case "parquet":
+		err = stats.WriteParquet(f, history)
+	case "csv":
+		err = stats.WriteCSV(f, history)
+	case "jsonl":
+		err = stats.WriteJSONL(f, history)
+	default:
+		return fmt.Errorf("unsupported format '%s'", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	log.Info().Str("file", *out).Int("results", len(history)).Msg("Export written")
+	return f.Close()
+}
+
+// importCommand seeds the result store from `speedtest --format=json` output files.
+// Results already present (same timestamp) are skipped, so re-running an import is safe.
+func importCommand(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: tetra import <file.json>...")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !storage.IsPersistent(cfg) {
+		return fmt.Errorf("STORAGE_BACKEND is memory, imported results would be lost; configure bolt or jsonl")
+	}
+	store, err := storage.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	existing, err := store.Query(time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	seen := make(map[int64]bool, len(existing))
+	for _, r := range existing {
+		seen[r.Time.UnixNano()] = true
+	}
+
+	imported, skipped := 0, 0
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		results, err := speed.ParseOoklaJSON(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, r := range results {
+			if seen[r.Time.UnixNano()] {
+				skipped++
+				continue
+			}
+			if err := store.Add(r); err != nil {
+				return err
+			}
+			seen[r.Time.UnixNano()] = true
+			imported++
+		}
+		log.Info().Str("file", path).Int("results", len(results)).Msg("Parsed Ookla results")
+	}
+
+	log.Info().Int("imported", imported).Int("skipped", skipped).Msg("Import complete")
+	return nil
+}
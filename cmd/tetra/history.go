@@ -0,0 +1,89 @@
+package main
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// alertRulePrefix starts the metric of events from ALERT_RULES, followed by the rule name.
+const alertRulePrefix = "rule:"
+
+// recordAlerts keeps the alert history in step with a scheduled test cycle: a metric that
+// alerted starts an event, and a full test passing on it ends the event. chats are the
+// values of the chats that get alerts.
+func recordAlerts(h *stats.AlertHistory, o *testOutcome, chats []config.ChatValues) {
+	for _, r := range o.results {
+		if r.Error != nil || r.Lite || o.ruled {
+			continue
+		}
+		// A test below the thresholds of any chat alerts, so the strictest ones count
+		var download, upload float64
+		for _, v := range chats {
+			v = o.thresholdsAt(r, v)
+			download, upload = max(download, v.DownloadThreshold), max(upload, v.UploadThreshold)
+		}
+		trackAlert(h, r, stats.AlertDownload, r.Download, download, r.Download < download)
+		if !r.NoUpload {
+			trackAlert(h, r, stats.AlertUpload, r.Upload, upload, r.Upload < upload)
+		}
+		trackAlert(h, r, stats.AlertJitter, float64(r.Jitter.Milliseconds()), float64(o.jitterLimit.Milliseconds()), jitterAbove(r, o.jitterLimit))
+	}
+	for _, m := range o.ruleAlerts {
+		h.Trigger(stats.AlertEvent{Metric: alertRulePrefix + m.Rule.Name, Target: m.Result.Label(), Start: m.Result.Time})
+	}
+	for _, m := range o.ruleCleared {
+		h.Recover(alertRulePrefix+m.Rule.Name, m.Result.Label(), m.Result.Time)
+	}
+}
+
+// trackAlert starts an event for a metric of a result that alerted, or ends it once the metric is fine.
+func trackAlert(h *stats.AlertHistory, r stats.Result, metric string, value, threshold float64, bad bool) {
+	switch {
+	case bad && r.AlertSent:
+		h.Trigger(stats.AlertEvent{Metric: metric, Target: r.Label(), Value: value, Threshold: threshold, Start: r.Time})
+	case !bad:
+		h.Recover(metric, r.Label(), r.Time)
+	}
+}
+
+// formatAlertHistory lists alert events for /alerts, newest first, with how long they lasted.
+func formatAlertHistory(events []stats.AlertEvent, now time.Time, loc *time.Location) string {
+	if len(events) == 0 {
+		return i18n.T("alerts.empty")
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("alerts.title", len(events)))
+	for _, e := range events {
+		var what string
+		switch e.Metric {
+		case stats.AlertDownload:
+			what = i18n.T("alerts.download", e.Value, e.Threshold)
+		case stats.AlertUpload:
+			what = i18n.T("alerts.upload", e.Value, e.Threshold)
+		case stats.AlertJitter:
+			what = i18n.T("alerts.jitter", e.Value, e.Threshold)
+		case stats.AlertBaseline:
+			what = i18n.T("alerts.baseline", e.Value, e.Threshold)
+		case stats.AlertOutage:
+			what = i18n.T("alerts.outage")
+		default:
+			what = i18n.T("alerts.rule", html.EscapeString(strings.TrimPrefix(e.Metric, alertRulePrefix)))
+		}
+		if e.Target != "" {
+			what += " · " + html.EscapeString(e.Target)
+		}
+		duration := stats.FormatPeriod(e.Duration(now).Round(time.Minute))
+		if e.Ongoing() {
+			duration = i18n.T("alerts.ongoing", duration)
+		} else {
+			duration = i18n.T("alerts.lasted", duration)
+		}
+		sb.WriteString(i18n.T("alerts.entry", e.Start.In(loc).Format("2006-01-02 15:04"), what, duration))
+	}
+	return sb.String()
+}
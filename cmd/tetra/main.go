@@ -1,19 +1,35 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"errors"
+	"html"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/ckayt/tetra/internal/archive"
+	"github.com/ckayt/tetra/internal/chart"
 	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/diag"
+	"github.com/ckayt/tetra/internal/dnsprobe"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/monitor"
+	"github.com/ckayt/tetra/internal/notify"
+	"github.com/ckayt/tetra/internal/rules"
+	"github.com/ckayt/tetra/internal/sink"
 	"github.com/ckayt/tetra/internal/speed"
 	"github.com/ckayt/tetra/internal/stats"
+	"github.com/ckayt/tetra/internal/storage"
 	"github.com/ckayt/tetra/internal/telegram"
+	"github.com/ckayt/tetra/internal/templates"
+	"github.com/ckayt/tetra/internal/uptime"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -23,6 +39,14 @@ func main() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
 
+	// Run CLI subcommand if given
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msgf("Command '%s' failed", os.Args[1])
+		}
+		return
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -35,66 +59,447 @@ func main() {
 		zerolog.SetGlobalLevel(level)
 	}
 
-	log.Info().Str("config", cfg.String()).Msg("Starting Tetra")
+	log.Info().Str("config", cfg.String()).Str("version", buildVersion()).Msg("Starting Tetra")
+
+	if err := i18n.SetLang(cfg.BotLang); err != nil {
+		log.Fatal().Err(err).Msg("Invalid BOT_LANG")
+	}
+	if err := templates.Load(cfg.TemplatesDir); err != nil {
+		log.Fatal().Err(err).Msg("Invalid message templates")
+	}
+	alertRules, err := rules.Load(cfg.AlertRules)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid alert rules")
+	}
 
 	// Init components
-	statsMgr := stats.NewManager(100) // Keep ~100 results (approx 2 days at 30min interval)
-	speedRunner := speed.NewRunner()
+	store, err := storage.Open(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open storage")
+	}
+	defer store.Close()
+
+	retention := cfg.Retention
+	if retention == 0 && !storage.IsPersistent(cfg) {
+		retention = stats.DefaultRetention
+	}
+	statsMgr := stats.NewManagerWithStorage(retention, store)
+	statsMgr.SetJitterThreshold(time.Duration(cfg.JitterThreshold * float64(time.Millisecond)))
+	statsMgr.SetSpeedBuckets(cfg.SpeedBuckets)
+	statsMgr.SetPlan(stats.Plan{Download: cfg.PlanDownload, Upload: cfg.PlanUpload})
+	log.Info().Str("backend", cfg.StorageBackend).Dur("retention", retention).Msg("Result storage ready")
+
+	// Thresholds, interval and report hour can be changed from the bot at runtime
+	settings, err := config.NewSettings(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load settings")
+	}
+	// Alert events for /alerts, kept apart from the results
+	alertHistory, err := stats.NewAlertHistory(cfg.AlertHistoryPath, 500)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load alert history")
+	}
+
+	engines, err := speed.NewEngines(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure speed test engine")
+	}
+	speedRunner := speed.NewRunner(engines...)
+	log.Info().Strs("engines", cfg.SpeedtestEngines).Msg("Speed test engines ready")
+	// A server picked with /server overrides SPEEDTEST_SERVER_ID
+	serverID := settings.Get().PinnedServer()
+	speedRunner.PinServer(serverID)
+
+	sinks, err := sink.FromConfig(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure result sinks")
+	}
+	for _, s := range sinks {
+		log.Info().Str("sink", s.Name()).Msg("Result sink enabled")
+	}
+
+	var dnsProber *dnsprobe.Prober
+	if len(cfg.DNSProbeHosts) > 0 {
+		dnsProber = dnsprobe.New(cfg)
+		log.Info().Strs("hosts", cfg.DNSProbeHosts).Strs("resolvers", cfg.DNSProbeResolvers).Msg("DNS probes enabled")
+	}
+
+	var tracer *diag.Tracer
+	if cfg.TraceroutePath != "" {
+		tracer, err = diag.NewTracer(cfg.TraceroutePath, cfg.TracerouteTarget)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to configure traceroute")
+		}
+	}
+
+	var endpointChecker *uptime.Checker
+	if len(cfg.EndpointChecks) > 0 {
+		endpointChecker = uptime.New(cfg)
+		log.Info().Strs("urls", cfg.EndpointChecks).Msg("Endpoint checks enabled")
+	}
+
+	// Lite checks replace scheduled tests once the monthly data budget is used up
+	liteRunner := speed.NewRunner(speed.NewLiteEngine(cfg.LiteCheckURL, speed.Network{Proxy: cfg.SpeedtestProxy}))
+	budgetLoc, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		budgetLoc = time.UTC
+	}
+
+	quiet := newQuietHours(cfg, budgetLoc)
+	if quiet != nil {
+		log.Info().Str("from", formatClock(quiet.start)).Str("to", formatClock(quiet.end)).Bool("digest", quiet.digest).Msg("Quiet hours enabled")
+	}
+	dataUsedThisMonth := func() uint64 {
+		now := time.Now().In(budgetLoc)
+		used, err := statsMgr.DataUsed(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, budgetLoc), now)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to compute data usage")
+		}
+		return used
+	}
+	var budgetExhausted bool
+
+	// liteDegraded reports whether lite check results warrant a full test
+	liteDegraded := func(results []stats.Result) bool {
+		for _, r := range results {
+			if r.Error != nil ||
+				(cfg.LitePingThreshold > 0 && r.Ping > time.Duration(cfg.LitePingThreshold*float64(time.Millisecond))) ||
+				r.Download < cfg.LiteDownloadThreshold {
+				return true
+			}
+		}
+		return false
+	}
+	var cycle int
+
+	// Health shown by /status, times are unix nanoseconds with zero meaning unknown
+	started := time.Now()
+	var lastSuccess, nextTest atomic.Int64
 
 	// Define test action wrapper with mutex to avoid concurrent speed tests
 	var testMu sync.Mutex
-	runTest := func(ctx context.Context, manual bool) string {
+	var bot *telegram.Bot
+	var out *outbox
+	// Bad test cycles per chat, to alert after ALERT_AFTER_FAILURES, escalate and announce recoveries
+	speedAlerts := newSpeedAlerts(cfg)
+	outages := &outageTracker{after: cfg.OutageAfterFailures}
+	sla := newSLAWatch(cfg)
+	var networks *networkWatch
+	if cfg.NetworkChangeAlerts {
+		networks = newNetworkWatch(statsMgr, time.Now())
+	}
+	runTest := func(ctx context.Context, manual bool) *testOutcome {
 		testMu.Lock()
 		defer testMu.Unlock()
 
-		start := time.Now()
-		log.Info().Bool("manual", manual).Msg("Running speed test...")
+		runner := speedRunner
+		var notices []string
+		if cfg.DataBudget > 0 && !manual {
+			used := dataUsedThisMonth()
+			exhausted := used >= cfg.DataBudget
+			if exhausted && !budgetExhausted {
+				log.Warn().Uint64("used", used).Uint64("budget", cfg.DataBudget).Msg("Monthly data budget used up, switching to lite checks")
+				notices = append(notices, i18n.T("budget.reached",
+					stats.FormatBytes(used), stats.FormatBytes(cfg.DataBudget)))
+			}
+			budgetExhausted = exhausted
+			if exhausted {
+				runner = liteRunner
+			}
+		}
+		// Between full tests only run lite checks
+		if !manual && cfg.FullTestEvery > 1 {
+			if cycle%cfg.FullTestEvery != 0 {
+				runner = liteRunner
+			}
+			cycle++
+		}
 
-		res := speedRunner.Run(ctx)
+		start := time.Now()
+		// Tests keep running during maintenance windows, they just don't alert
+		maintenance := !manual && cfg.InMaintenance(start.In(budgetLoc))
+		log.Info().Bool("manual", manual).Bool("lite", runner == liteRunner).Bool("maintenance", maintenance).Msg("Running speed test...")
+
+		results := runner.Run(ctx)
+		if runner == liteRunner && !budgetExhausted && liteDegraded(results) {
+			log.Info().Msg("Lite check looks degraded, running full speed test")
+			results = append(results, speedRunner.Run(ctx)...)
+		}
 		duration := time.Since(start)
+		for _, r := range results {
+			if r.Error == nil {
+				lastSuccess.Store(r.Time.UnixNano())
+			}
+		}
+
+		outcome := &testOutcome{
+			manual:      manual,
+			jitterLimit: time.Duration(cfg.JitterThreshold * float64(time.Millisecond)),
+			critical:    newCriticalTier(cfg),
+			schedule:    cfg.ThresholdSchedule,
+			loc:         budgetLoc,
+			results:     results,
+			traces:      make([]string, len(results)),
+			notices:     notices,
+			maintenance: maintenance,
+			ruled:       alertRules != nil,
+		}
+		// Tests failing in a row mean the internet is down rather than slow
+		if !manual && !maintenance {
+			started, ended, recovered := outages.observe(results)
+			switch {
+			case started:
+				log.Warn().Time("since", outages.start).Int("failures", outages.failures).Msg("Internet appears down")
+				outcome.outage = outages.downMessage(budgetLoc)
+				alertHistory.Trigger(stats.AlertEvent{Metric: stats.AlertOutage, Start: outages.start})
+			case recovered:
+				statsMgr.AddOutage(ended)
+				alertHistory.Recover(stats.AlertOutage, "", ended.End)
+				log.Warn().Dur("duration", ended.Duration()).Msg("Internet is back")
+				outcome.recoveries = append(outcome.recoveries, restoredMessage(ended, budgetLoc))
+				outcome.back = true
+			}
+			outcome.down = outages.down
+		}
+		for i := range results {
+			res := &results[i]
+			log.Info().
+				Str("engine", res.Engine).
+				Str("ip_version", res.IPVersion).
+				Float64("download", res.Download).
+				Float64("upload", res.Upload).
+				Dur("ping", res.Ping).
+				Dur("jitter", res.Jitter).
+				Err(res.Error).
+				Dur("duration", duration).
+				Msg("Speed test completed")
+
+			// A result counts as an alert if it is below the thresholds of any chat that wants alerts
+			// and has seen enough bad tests in a row
+			alert := false
+			// Also when it is far below the usual speed at this hour, which it isn't part of yet
+			if !manual && !maintenance && cfg.BaselineDrop > 0 {
+				baseline := statsMgr.GetBaseline(res.Label(), res.Time, cfg.BaselineDays, budgetLoc)
+				if notes := stats.BelowBaseline(*res, baseline, cfg.BaselineDrop); len(notes) > 0 {
+					log.Warn().Strs("notes", notes).Int("samples", baseline.Samples).Msg("Speed below the usual for this hour")
+					outcome.details = append(outcome.details, i18n.T("baseline.title", html.EscapeString(res.Label()))+"\n- "+strings.Join(notes, "\n- "))
+					alert = true
+					res.AlertSent = true
+					alertHistory.Trigger(stats.AlertEvent{Metric: stats.AlertBaseline, Target: res.Label(), Value: res.Download, Threshold: baseline.Download, Start: res.Time})
+				} else if res.Error == nil && !res.Lite {
+					alertHistory.Recover(stats.AlertBaseline, res.Label(), res.Time)
+				}
+			}
+			switch {
+			case manual || maintenance:
+			case alertRules != nil:
+				matches, cleared := alertRules.Evaluate(*res, res.Time.In(budgetLoc))
+				for _, m := range matches {
+					log.Warn().Str("rule", m.Rule.Name).Strs("met", m.Met).Int("streak", m.Streak).Msg("Alert rule matched")
+					alert = true
+					res.AlertSent = true
+					if res.Severity != stats.SeverityCritical {
+						res.Severity = m.Rule.Severity
+					}
+				}
+				outcome.ruleAlerts = append(outcome.ruleAlerts, matches...)
+				outcome.ruleCleared = append(outcome.ruleCleared, cleared...)
+			case !alert:
+				for _, id := range out.audience() {
+					if v := settings.ForChat(id); v.Verbosity != config.VerbosityOff && belowThresholds(*res, outcome.thresholdsAt(*res, v), outcome.jitterLimit) && speedAlerts.alertsNext(id) {
+						alert = true
+						res.AlertSent = true
+						break
+					}
+				}
+			}
 
-		log.Info().
-			Float64("download", res.Download).
-			Float64("upload", res.Upload).
-			Dur("ping", res.Ping).
-			Err(res.Error).
-			Dur("duration", duration).
-			Msg("Speed test completed")
+			if res.AlertSent && res.Severity == "" {
+				res.Severity = outcome.critical.severity(*res)
+			}
+			res.Maintenance = maintenance
+			statsMgr.Add(*res)
+			if len(sinks) > 0 {
+				go sink.WriteAll(ctx, sinks, *res)
+			}
 
-		msg := formatResult(res)
+			if res.Error != nil && !manual {
+				outcome.failed = true
+			}
+			// Attach the route to the server when the test failed or was slow
+			if tracer != nil && !manual && (alert || res.Error != nil) {
+				host, report, err := tracer.Trace(ctx, res.Server)
+				if err != nil {
+					log.Warn().Err(err).Str("host", host).Msg("Traceroute failed")
+				} else {
+					outcome.traces[i] = diag.Format(host, report)
+				}
+			}
+		}
 
-		// Check thresholds if not error
-		alertTriggered := false
-		if res.Error == nil && !manual {
-			if res.Download < cfg.DownloadThreshold || res.Upload < cfg.UploadThreshold {
-				alertTriggered = true
-				res.AlertSent = true
+		if !manual && !maintenance {
+			var chats []config.ChatValues
+			for _, id := range out.audience() {
+				if v := settings.ForChat(id); v.Verbosity != config.VerbosityOff {
+					chats = append(chats, v)
+				}
 			}
+			recordAlerts(alertHistory, outcome, chats)
 		}
 
-		statsMgr.Add(res)
+		if networks != nil && !manual {
+			outcome.notices = append(outcome.notices, networks.check(results)...)
+		}
 
-		if alertTriggered {
-			return fmt.Sprintf("🚨 <b>Internet Quality Alert!</b>\n%s", msg)
+		// Alert once per billing month when the SLA can't be met anymore
+		if sla != nil && !manual {
+			breaches := sla.check(statsMgr, time.Now().In(budgetLoc), settings.Get().CheckInterval)
+			if len(breaches) > 0 {
+				log.Warn().Int("breaches", len(breaches)).Msg("SLA breached")
+				outcome.notices = append(outcome.notices, breaches...)
+			}
 		}
-		if manual {
-			return fmt.Sprintf("✅ <b>Manual Test Result:</b>\n%s", msg)
+
+		// Alert when one IP family degrades while the other is fine
+		if notes := stats.CompareFamilies(results, cfg.IPFamilyMaxDiff); len(notes) > 0 {
+			log.Warn().Strs("notes", notes).Msg("IP family degraded")
+			outcome.details = append(outcome.details, i18n.T("family.title")+"\n- "+strings.Join(notes, "\n- "))
 		}
-		return ""
+
+		if dnsProber != nil {
+			dnsResults := dnsProber.Run(ctx)
+			statsMgr.AddDNS(dnsResults)
+			for _, r := range dnsResults {
+				if r.Error != nil || r.Slow {
+					log.Warn().Str("host", r.Host).Str("resolver", r.Resolver).Dur("duration", r.Duration).Err(r.Error).Msg("DNS lookup degraded")
+				}
+			}
+			if report := dnsprobe.Format(dnsResults, manual); report != "" {
+				outcome.details = append(outcome.details, report)
+			}
+		}
+
+		if endpointChecker != nil {
+			endpointResults := endpointChecker.Run(ctx)
+			statsMgr.AddEndpoints(endpointResults)
+			// Up/down changes are reported as notices rather than quality alerts
+			down, up := endpointChecker.Changes(endpointResults)
+			if down != "" {
+				log.Warn().Msg("Endpoints went down")
+				outcome.notices = append(outcome.notices, down)
+			}
+			if up != "" {
+				log.Info().Msg("Endpoints came back up")
+				outcome.recoveries = append(outcome.recoveries, up)
+			}
+			outcome.extras = append(outcome.extras, uptime.Format(endpointResults))
+		}
+		return outcome
 	}
 
 	// Define stats action
-	getStats := func(ctx context.Context) string {
-		summary := statsMgr.GetLast24hSummary(time.Now(), cfg.DownloadThreshold, cfg.UploadThreshold)
+	getStats := func(ctx context.Context, chatID int64, period time.Duration) string {
+		values := settings.ForChat(chatID)
+		summary := statsMgr.GetSummary(time.Now(), period, values.DownloadThreshold, values.UploadThreshold, budgetLoc)
+		if !textCharts(cfg, chatID, values) {
+			summary.Timeline = stats.Timeline{}
+		}
+		usage := i18n.T("usage.month", stats.FormatBytes(dataUsedThisMonth()))
+		if cfg.DataBudget > 0 {
+			usage += i18n.T("usage.of", stats.FormatBytes(cfg.DataBudget))
+		}
+		return summary.String() + usage
+	}
+	getRangeStats := func(ctx context.Context, chatID int64, from, to time.Time) string {
+		values := settings.ForChat(chatID)
+		summary := statsMgr.Summarize(from, to, stats.Thresholds{Download: values.DownloadThreshold, Upload: values.UploadThreshold}, budgetLoc)
+		if !textCharts(cfg, chatID, values) {
+			summary.Timeline = stats.Timeline{}
+		}
 		return summary.String()
 	}
 
+	// Define export action
+	exportResults := func(ctx context.Context) ([]byte, error) {
+		history, err := statsMgr.History()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := stats.WriteCSV(&buf, history); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
 	// Init Telegram Bot with retry
-	var bot *telegram.Bot
 	for {
-		bot, err = telegram.New(cfg, func(ctx context.Context) string {
-			return runTest(ctx, true)
-		}, getStats)
+		bot, err = telegram.New(cfg, settings, telegram.Actions{
+			Test: func(ctx context.Context, verbose bool) (string, []byte) {
+				outcome := runTest(ctx, true)
+				msg, _ := outcome.message(config.ChatValues{})
+				if !verbose {
+					return msg, nil
+				}
+				return msg, outcome.rawJSON()
+			},
+			Stats:  getStats,
+			Range:  getRangeStats,
+			Export: exportResults,
+			Servers: func(ctx context.Context) ([]speed.ServerInfo, error) {
+				return speed.ListServers(ctx, speed.Network{Proxy: cfg.SpeedtestProxy}, 8)
+			},
+			Report: func(ctx context.Context, chatID int64) {
+				sendDailyReport(cfg, statsMgr, out, time.Now(), budgetLoc, []int64{chatID})
+			},
+			Compare: func(ctx context.Context, period time.Duration) string {
+				return statsMgr.Compare(time.Now(), period, budgetLoc).String()
+			},
+			Aggregate: func(ctx context.Context, days int) string {
+				return statsMgr.GetAggregate(time.Now(), days, budgetLoc).String()
+			},
+			Hours: func(ctx context.Context, days int) string {
+				if days == 0 {
+					days = cfg.HoursDays
+				}
+				return statsMgr.GetHourAnalysis(time.Now(), days, budgetLoc).String()
+			},
+			Graph: func(ctx context.Context, metric chart.Metric, period time.Duration) ([]byte, error) {
+				now := time.Now()
+				results, err := statsMgr.Query(now.Add(-period), now)
+				if err != nil {
+					return nil, err
+				}
+				return chart.Render(results, metric, budgetLoc)
+			},
+			Alerts: func(ctx context.Context, n int) string {
+				return formatAlertHistory(alertHistory.Recent(n), time.Now(), budgetLoc)
+			},
+			Last: func(ctx context.Context) string {
+				r, ok, err := statsMgr.Latest()
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to read latest result")
+					return i18n.T("last.failed")
+				}
+				if !ok {
+					return i18n.T("last.none")
+				}
+				return formatLast(r, time.Now(), budgetLoc)
+			},
+			Status: func(ctx context.Context) telegram.Status {
+				st := telegram.Status{
+					Version:     buildVersion(),
+					Started:     started,
+					LastSuccess: unixTime(lastSuccess.Load()),
+					Failing:     out.dispatch.Failures(),
+				}
+				if !settings.Get().Paused {
+					st.NextTest = unixTime(nextTest.Load())
+				}
+				st.FailureStreak, st.LongestStreak = statsMgr.FailureStreaks(time.Now())
+				return st
+			},
+		})
 		if err == nil {
 			break
 		}
@@ -102,6 +507,8 @@ func main() {
 		time.Sleep(5 * time.Second)
 	}
 
+	out = newOutbox(cfg, bot, settings, quiet)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -109,20 +516,62 @@ func main() {
 	go bot.Start(ctx)
 
 	// Start Ticker
-	ticker := time.NewTicker(cfg.CheckInterval)
+	interval := settings.Get().CheckInterval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	nextTest.Store(time.Now().Add(interval).UnixNano())
 
 	// Daily Report Scheduler
-	go dailyReportLoop(ctx, cfg, statsMgr, bot)
+	go dailyReportLoop(ctx, cfg, statsMgr, out)
+
+	// Alerts held back during quiet hours are sent as a digest when they end
+	go quiet.Loop(ctx, out)
+
+	// Result archive upload
+	if cfg.ArchiveS3Endpoint != "" {
+		archiver, err := archive.New(cfg, statsMgr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to configure result archive")
+		}
+		go archiver.Loop(ctx)
+	}
+
+	// Continuous ping monitor between speed tests
+	if cfg.PingMonitorHost != "" {
+		notify := func(msg string, restored bool) {
+			if restored {
+				alertHistory.Recover(stats.AlertOutage, cfg.PingMonitorHost, time.Now())
+			}
+			if cfg.InMaintenance(time.Now().In(budgetLoc)) {
+				log.Info().Bool("restored", restored).Msg("Maintenance window, not sending ping monitor message")
+				return
+			}
+			if !restored {
+				alertHistory.Trigger(stats.AlertEvent{Metric: stats.AlertOutage, Target: cfg.PingMonitorHost, Start: time.Now()})
+			}
+			class := telegram.ClassAlert
+			if restored {
+				class = telegram.ClassRecovery
+			}
+			out.broadcastAlert(alertMonitor, !restored, func(_ int64, v config.ChatValues) (string, telegram.Class) {
+				if v.Verbosity == config.VerbosityOff {
+					return "", class
+				}
+				return msg, class
+			})
+		}
+		go monitor.New(cfg, statsMgr, notify).Loop(ctx)
+	}
 
 	// Run initial test immediately in background (after a short delay to let things settle)
 	go func() {
 		time.Sleep(5 * time.Second)
-		log.Info().Msg("Taking initial speed test...")
-		alertMsg := runTest(ctx, false)
-		if alertMsg != "" {
-			bot.Send(alertMsg)
+		if settings.Get().Paused {
+			log.Info().Msg("Monitoring paused, skipping initial speed test")
+			return
 		}
+		log.Info().Msg("Taking initial speed test...")
+		announce(out, speedAlerts, runTest(ctx, false))
 	}()
 
 	// Start Health Check Server
@@ -137,8 +586,20 @@ func main() {
 			_, _ = w.Write([]byte("ready"))
 		})
 
-		log.Info().Msg("Starting health check server on :8080")
-		if err := http.ListenAndServe(":8080", nil); err != nil {
+		// Telegram posts updates here in webhook mode
+		if path := bot.WebhookPath(); path != "" {
+			http.Handle(path, bot.WebhookHandler())
+		}
+
+		var err error
+		if cfg.HTTPTLSCert != "" {
+			log.Info().Msg("Starting health check server on :8080 (HTTPS)")
+			err = http.ListenAndServeTLS(":8080", cfg.HTTPTLSCert, cfg.HTTPTLSKey, nil)
+		} else {
+			log.Info().Msg("Starting health check server on :8080")
+			err = http.ListenAndServe(":8080", nil)
+		}
+		if err != nil {
 			log.Error().Err(err).Msg("Health check server failed")
 		}
 	}()
@@ -158,40 +619,95 @@ func main() {
 			time.Sleep(1 * time.Second)
 			return
 		case <-ticker.C:
-			alertMsg := runTest(ctx, false)
-			if alertMsg != "" {
-				bot.Send(alertMsg)
+			nextTest.Store(time.Now().Add(interval).UnixNano())
+			if settings.Get().Paused {
+				log.Info().Msg("Monitoring paused, skipping scheduled test")
+				continue
+			}
+			announce(out, speedAlerts, runTest(ctx, false))
+		case <-settings.Changed():
+			v := settings.Get()
+			if v.CheckInterval != interval {
+				interval = v.CheckInterval
+				ticker.Reset(interval)
+				nextTest.Store(time.Now().Add(interval).UnixNano())
+				log.Info().Dur("interval", interval).Msg("Check interval changed")
+			}
+			if v.PinnedServer() != serverID {
+				serverID = v.PinnedServer()
+				speedRunner.PinServer(serverID)
+				log.Info().Str("server_id", serverID).Msg("Speedtest server changed")
 			}
 		}
 	}
 }
 
-func dailyReportLoop(ctx context.Context, cfg *config.Config, statsMgr *stats.Manager, bot *telegram.Bot) {
+// unixTime converts unix nanoseconds back to a time, keeping zero as the zero time.
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// dailyReportLoop sends every chat its report at the chat's own report hour, including the
+// private chats of users who want it.
+func dailyReportLoop(ctx context.Context, cfg *config.Config, statsMgr *stats.Manager, out *outbox) {
 	loc, err := time.LoadLocation(cfg.TimeZone)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to load timezone, using UTC")
 		loc = time.UTC
 	}
 
+	settings := out.settings
 	for {
+		changed := settings.Changed()
 		now := time.Now().In(loc)
-		nextReport := time.Date(now.Year(), now.Month(), now.Day(), cfg.DailyReportHour, 0, 0, 0, loc)
 
-		if nextReport.Before(now) {
-			nextReport = nextReport.Add(24 * time.Hour)
+		// Find the earliest upcoming report and the chats due at that time
+		var nextReport time.Time
+		var due []int64
+		for _, id := range out.audience() {
+			if !settings.ForUser(id).Wants(string(telegram.ClassReport), false) {
+				continue
+			}
+			next := time.Date(now.Year(), now.Month(), now.Day(), settings.ForChat(id).DailyReportHour, 0, 0, 0, loc)
+			if next.Before(now) {
+				next = next.Add(24 * time.Hour)
+			}
+			switch {
+			case due == nil || next.Before(nextReport):
+				nextReport, due = next, []int64{id}
+			case next.Equal(nextReport):
+				due = append(due, id)
+			}
+		}
+		if due == nil {
+			// Nobody wants a report yet, e.g. before a chat is bound or a user subscribes
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				continue
+			}
 		}
 
 		wait := nextReport.Sub(now)
-		log.Info().Time("next_report", nextReport).Dur("wait", wait).Msg("Scheduled daily report")
+		log.Info().Time("next_report", nextReport).Dur("wait", wait).Int("chats", len(due)).Msg("Scheduled daily report")
 
 		select {
 		case <-ctx.Done():
 			return
+		case <-changed:
+			// Reschedule in case a report hour changed
+			continue
 		case <-time.After(wait):
 			// Generate report
 			log.Info().Msg("Generating daily report...")
-			summary := statsMgr.GetLast24hSummary(time.Now(), cfg.DownloadThreshold, cfg.UploadThreshold)
-			bot.Send(summary.String())
+			sendDailyReport(cfg, statsMgr, out, time.Now(), loc, due)
+			for _, report := range periodicReports(cfg, statsMgr, time.Now(), loc) {
+				out.deliver(notify.Message{Class: string(telegram.ClassReport), Text: report}, due)
+			}
 
 			// Wait a bit to avoid double send due to slight time discrepancies (unlikely with time.After but good practice)
 			time.Sleep(1 * time.Minute)
@@ -199,14 +715,109 @@ func dailyReportLoop(ctx context.Context, cfg *config.Config, statsMgr *stats.Ma
 	}
 }
 
+// sendDailyReport queues the report of the 24h before now for the given chats, each with its own thresholds.
+// The other backends get the report without the chart.
+func sendDailyReport(cfg *config.Config, statsMgr *stats.Manager, out *outbox, now time.Time, loc *time.Location, chatIDs []int64) {
+	var chats []int64
+	var declines []string
+	if cfg.TrendDrop > 0 {
+		declines = statsMgr.GetTrend(now, cfg.TrendDays, loc).Declines(cfg.TrendDrop)
+	}
+	for _, id := range chatIDs {
+		v := out.settings.ForChat(id)
+		summary := statsMgr.GetLast24hSummary(now, v.DownloadThreshold, v.UploadThreshold, loc)
+		summary.Declines = declines
+		if !textCharts(cfg, id, v) {
+			summary.Timeline = stats.Timeline{}
+			chats = append(chats, id)
+		}
+		text := templates.Render(templates.Report, summary, summary.String())
+		out.deliver(notify.Message{Class: string(telegram.ClassReport), Text: text, Pin: cfg.ReportPin}, []int64{id})
+	}
+	if len(chats) > 0 {
+		sendReportChart(out.bot, statsMgr, now, loc, chats)
+	}
+}
+
+// textCharts reports whether a chat's reports show speed over time as a sparkline rather than a
+// chart image: without REPORT_CHART, when the chat chose so, and for the other backends, which
+// get no images.
+func textCharts(cfg *config.Config, chatID int64, v config.ChatValues) bool {
+	return !cfg.ReportChart || v.TextCharts || chatID == otherBackends
+}
+
+// periodicReports returns the reports that follow the daily one on some days: the weekly roll-up
+// on Mondays, the monthly one on the 1st and, on the billing day, how the last billing month
+// measured up against the SLA and the advertised plan.
+func periodicReports(cfg *config.Config, statsMgr *stats.Manager, now time.Time, loc *time.Location) []string {
+	var reports []string
+	local := now.In(loc)
+	if cfg.WeeklyReport && local.Weekday() == time.Monday {
+		reports = append(reports, statsMgr.GetWeeklyRollup(now, loc).String())
+	}
+	if cfg.MonthlyReport && local.Day() == 1 {
+		reports = append(reports, statsMgr.GetMonthlyRollup(now, loc).String())
+	}
+	if report, ok := slaMonthReport(cfg, statsMgr, now, loc); ok {
+		reports = append(reports, report)
+	}
+	if report, ok := planMonthReport(cfg, statsMgr, now, loc); ok {
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// sendReportChart attaches a chart of the last 24h to the daily report. Charts are a nice-to-have,
+// so failures are only logged.
+func sendReportChart(bot *telegram.Bot, statsMgr *stats.Manager, now time.Time, loc *time.Location, chatIDs []int64) {
+	results, err := statsMgr.Query(now.Add(-24*time.Hour), now)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results for report chart")
+		return
+	}
+	png, err := chart.Render(results, chart.MetricAll, loc)
+	if errors.Is(err, chart.ErrNotEnoughData) {
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to render report chart")
+		return
+	}
+	bot.SendPhotoTo(telegram.ClassReport, png, i18n.T("chart.caption"), chatIDs...)
+}
+
+// formatLast renders a stored result for /last with when it was taken and whether it alerted.
+func formatLast(r stats.Result, now time.Time, loc *time.Location) string {
+	msg := i18n.T("last.title", r.Time.In(loc).Format("2006-01-02 15:04"), stats.FormatPeriod(now.Sub(r.Time).Round(time.Minute)))
+	msg += "\n🔧 " + html.EscapeString(r.Label())
+	if r.Lite {
+		msg += i18n.T("last.lite")
+	}
+	msg += "\n" + formatResult(r) + "\n\n"
+	if r.AlertSent {
+		return msg + i18n.T("last.alert")
+	}
+	return msg + i18n.T("last.no_alert")
+}
+
+// formatResult renders a result with the result template, if there is one.
 func formatResult(r stats.Result) string {
+	return templates.Render(templates.Result, r, builtinResult(r))
+}
+
+func builtinResult(r stats.Result) string {
 	if r.Error != nil {
-		return fmt.Sprintf("⚠️ <b>Test Failed:</b> %v", r.Error)
-	}
-	return fmt.Sprintf(
-		"⬇️ <b>Download:</b> %.2f Mbps\n"+
-			"⬆️ <b>Upload:</b> %.2f Mbps\n"+
-			"📶 <b>Ping:</b> %d ms",
-		r.Download, r.Upload, r.Ping.Milliseconds(),
-	)
+		return i18n.T("result.failed", html.EscapeString(r.Error.Error()))
+	}
+	msg := i18n.T("result.speed", r.Download, r.Upload, r.Ping.Milliseconds())
+	if r.Jitter > 0 {
+		msg += i18n.T("result.jitter", r.Jitter.Milliseconds())
+	}
+	if increase, ok := r.BufferbloatIncrease(); ok {
+		msg += i18n.T("result.bufferbloat", r.BufferbloatGrade(), increase.Milliseconds())
+	}
+	if r.ShareURL != "" {
+		msg += i18n.T("result.share", html.EscapeString(r.ShareURL))
+	}
+	return msg
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"html"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// networkSeedPeriod is how far back the last known public IP and ISP are looked up at startup.
+const networkSeedPeriod = 7 * 24 * time.Hour
+
+// publicNetwork is what the speed test provider saw the test come from.
+type publicNetwork struct {
+	ip, isp string
+}
+
+// networkWatch reports changes of the public IP or ISP, which reveal CGNAT moves, failovers to a
+// backup line or DHCP renumbering. Results are tracked per label, since tests bound to another
+// interface or IP family see another address. It is only used by runTest, which runs one test at a time.
+type networkWatch struct {
+	last map[string]publicNetwork
+}
+
+// newNetworkWatch starts from the networks of the stored results, so a change across a restart
+// still alerts.
+func newNetworkWatch(statsMgr *stats.Manager, now time.Time) *networkWatch {
+	w := &networkWatch{last: make(map[string]publicNetwork)}
+	results, err := statsMgr.Query(now.Add(-networkSeedPeriod), now)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load the last public IP")
+		return w
+	}
+	for _, r := range results {
+		if n, ok := networkOf(r); ok {
+			w.last[r.Label()] = n
+		}
+	}
+	return w
+}
+
+// networkOf returns the public network of a result, if the engine reported it.
+func networkOf(r stats.Result) (publicNetwork, bool) {
+	n := publicNetwork{ip: r.Meta["public_ip"], isp: r.Meta["isp"]}
+	return n, r.Error == nil && (n.ip != "" || n.isp != "")
+}
+
+// check returns a notice for every result whose public IP or ISP differs from the last one seen.
+func (w *networkWatch) check(results []stats.Result) []string {
+	var notices []string
+	for _, r := range results {
+		n, ok := networkOf(r)
+		if !ok {
+			continue
+		}
+		label := r.Label()
+		prev := w.last[label]
+		var changes string
+		switch {
+		case n.ip == "":
+			n.ip = prev.ip
+		case prev.ip != "" && n.ip != prev.ip:
+			changes += i18n.T("network.ip", prev.ip, n.ip)
+		}
+		switch {
+		case n.isp == "":
+			n.isp = prev.isp
+		case prev.isp != "" && n.isp != prev.isp:
+			changes += i18n.T("network.isp", html.EscapeString(prev.isp), html.EscapeString(n.isp))
+		}
+		w.last[label] = n
+		if changes != "" {
+			log.Warn().Str("engine", label).Str("ip", n.ip).Str("isp", n.isp).Msg("Public network changed")
+			notices = append(notices, i18n.T("network.changed", html.EscapeString(label))+changes)
+		}
+	}
+	return notices
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/ckayt/tetra/internal/templates"
+)
+
+// outageTracker reports the internet as down after OUTAGE_AFTER_FAILURES scheduled test
+// cycles in a row failed completely, and measures how long that lasted once a test gets
+// through again. It is only used by runTest, which runs one test at a time.
+type outageTracker struct {
+	after    int // zero disables outage detection
+	failures int
+	start    time.Time // time of the first failed test in a row
+	down     bool
+}
+
+// observe records the results of a scheduled cycle. It reports whether this cycle started an
+// outage, or returns the outage it ended.
+func (t *outageTracker) observe(results []stats.Result) (started bool, ended stats.Outage, recovered bool) {
+	if t.after == 0 || len(results) == 0 {
+		return false, stats.Outage{}, false
+	}
+	for _, r := range results {
+		if r.Error != nil {
+			continue
+		}
+		if t.down {
+			ended, recovered = stats.Outage{Start: t.start, End: r.Time, Target: r.Label()}, true
+		}
+		t.failures, t.down = 0, false
+		return false, ended, recovered
+	}
+
+	if t.failures == 0 {
+		t.start = results[0].Time
+	}
+	t.failures++
+	if !t.down && t.failures >= t.after {
+		t.down = true
+		return true, stats.Outage{}, false
+	}
+	return false, stats.Outage{}, false
+}
+
+// downMessage is the alert sent when an outage starts, with times in loc.
+func (t *outageTracker) downMessage(loc *time.Location) string {
+	return i18n.T("outage.down", t.failures, t.start.In(loc).Format("15:04"))
+}
+
+// restoredMessage announces the end of an outage and how long it lasted, with times in loc.
+func restoredMessage(o stats.Outage, loc *time.Location) string {
+	text := i18n.T("outage.restored", stats.FormatPeriod(o.Duration().Round(time.Minute)), o.Start.In(loc).Format("15:04"), o.End.In(loc).Format("15:04"))
+	data := templates.RecoveryData{Target: o.Target, Downtime: o.Duration(), Start: o.Start, End: o.End, Text: text}
+	return templates.Render(templates.Recovery, data, text)
+}
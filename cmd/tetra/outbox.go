@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/notify"
+	"github.com/ckayt/tetra/internal/telegram"
+	"github.com/rs/zerolog/log"
+)
+
+// otherBackends is the chat ID under which the notification backends besides Telegram are
+// kept track of, as one more chat with the default preferences. No Telegram chat has ID 0.
+const otherBackends int64 = 0
+
+// outbox sends alerts and reports to the Telegram chats and to the other backends in NOTIFIERS.
+type outbox struct {
+	bot      *telegram.Bot
+	settings *config.Settings
+	quiet    *quietHours
+	telegram bool // NOTIFIERS includes telegram, commands are answered either way
+	others   bool // NOTIFIERS includes other backends
+	dispatch *notify.Dispatcher
+}
+
+func newOutbox(cfg *config.Config, bot *telegram.Bot, settings *config.Settings, quiet *quietHours) *outbox {
+	others := notify.FromConfig(cfg)
+	return &outbox{
+		bot:      bot,
+		settings: settings,
+		quiet:    quiet,
+		telegram: cfg.NotifiesVia("telegram"),
+		others:   len(others) > 0,
+		dispatch: notify.NewDispatcher(cfg, append([]notify.Notifier{bot}, others...)...),
+	}
+}
+
+// audience returns every chat messages go to: the configured chats and the private chats of
+// users who subscribed with /notify, plus otherBackends.
+func (out *outbox) audience() []int64 {
+	var ids []int64
+	if out.telegram {
+		ids = out.bot.Chats()
+		for _, id := range out.settings.Subscribers() {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if out.others {
+		ids = append(ids, otherBackends)
+	}
+	return ids
+}
+
+// broadcast sends every chat its own variant of a message, skipping chats that get none or,
+// for private chats, whose user chose not to get this kind of message.
+// Chats receiving the same text share one queued message. During quiet hours messages are held instead,
+// except critical ones, such as a lost connection, which also mention ALERT_MENTIONS in group chats.
+func (out *outbox) broadcast(critical bool, message func(chatID int64, v config.ChatValues) (string, telegram.Class)) {
+	out.broadcastTo(out.audience(), "", critical, message)
+}
+
+// broadcastAlert is broadcast for the alerts and recoveries of a condition, see notify.Message.Alert.
+func (out *outbox) broadcastAlert(alert string, critical bool, message func(chatID int64, v config.ChatValues) (string, telegram.Class)) {
+	out.broadcastTo(out.audience(), alert, critical, message)
+}
+
+// broadcastTo is broadcastAlert to the given chats only.
+func (out *outbox) broadcastTo(chatIDs []int64, alert string, critical bool, message func(chatID int64, v config.ChatValues) (string, telegram.Class)) {
+	type variant struct {
+		text  string
+		class telegram.Class
+	}
+	recipients := make(map[variant][]int64)
+	var order []variant
+	now := time.Now()
+	for _, id := range chatIDs {
+		v := out.settings.ForChat(id)
+		if v.Muted(now) {
+			log.Debug().Int64("chat_id", id).Time("muted_until", v.MutedUntil).Msg("Chat muted, skipping message")
+			continue
+		}
+		text, class := message(id, v)
+		if text == "" || !out.settings.ForUser(id).Wants(string(class), critical) || !critical && out.quiet.hold(out.bot, id, text, now) {
+			continue
+		}
+		msg := variant{text, class}
+		if _, ok := recipients[msg]; !ok {
+			order = append(order, msg)
+		}
+		recipients[msg] = append(recipients[msg], id)
+	}
+	for _, msg := range order {
+		out.deliver(notify.Message{Class: string(msg.class), Text: msg.text, Critical: critical, Alert: alert}, recipients[msg])
+	}
+}
+
+// send queues a message for the given chats, where otherBackends stands for the backends besides Telegram.
+func (out *outbox) send(class telegram.Class, text string, critical bool, chatIDs ...int64) {
+	out.deliver(notify.Message{Class: string(class), Text: text, Critical: critical}, chatIDs)
+}
+
+// deliver hands a message for the given chats to the dispatcher.
+func (out *outbox) deliver(m notify.Message, chatIDs []int64) {
+	m.Chats = slices.DeleteFunc(slices.Clone(chatIDs), func(id int64) bool { return id == otherBackends })
+	m.Others = len(m.Chats) < len(chatIDs)
+	out.dispatch.Dispatch(context.Background(), m)
+}
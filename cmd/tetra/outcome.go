@@ -0,0 +1,500 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/notify"
+	"github.com/ckayt/tetra/internal/rules"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/ckayt/tetra/internal/telegram"
+	"github.com/ckayt/tetra/internal/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// Conditions of the alerts that aren't from ALERT_RULES, acknowledged separately on Telegram
+const (
+	alertSpeed   = "speed"   // slow or failed tests and probe alerts
+	alertMonitor = "monitor" // the continuous ping monitor lost the host
+)
+
+// testOutcome is everything one test cycle produced. Chats have their own thresholds and
+// verbosity, so the alert text is rendered per chat.
+type testOutcome struct {
+	manual      bool
+	jitterLimit time.Duration // zero disables jitter alerts
+	critical    criticalTier
+	schedule    config.ThresholdSchedule // thresholds replacing the chats' own at the time of a result
+	loc         *time.Location           // time zone of the schedule
+	results     []stats.Result
+	traces      []string // traceroute report per result, empty when none was taken
+	details     []string // probe reports (IP family, DNS) that alert every chat
+	extras      []string // informational reports only shown in manual replies
+	notices     []string // status changes sent even without an alert
+	recoveries  []string // like notices, but reporting something that came back
+	failed      bool     // a scheduled test failed
+	outage      string   // "internet down" alert when this cycle started an outage
+	down        bool     // an outage is ongoing, so its failed tests don't alert on their own
+	back        bool     // this cycle ended an outage, which its recovery message reports
+	maintenance bool     // taken during a maintenance window, so nothing alerts
+
+	// With ALERT_RULES the rules decide about speed alerts instead of the thresholds
+	ruled       bool
+	ruleAlerts  []rules.Match
+	ruleCleared []rules.Match // rules that alerted for the previous cycle and no longer do
+}
+
+// belowThresholds reports whether a full test result is worse than the given thresholds.
+// Lite checks download too little for their throughput to be compared.
+func belowThresholds(r stats.Result, v config.ChatValues, jitterLimit time.Duration) bool {
+	return slowerThan(r, v) || jitterAbove(r, jitterLimit)
+}
+
+// thresholdsAt returns a chat's thresholds at the time of a result, which THRESHOLD_SCHEDULE may replace.
+func (o *testOutcome) thresholdsAt(r stats.Result, v config.ChatValues) config.ChatValues {
+	return o.schedule.Apply(r.Time.In(o.loc), v)
+}
+
+func slowerThan(r stats.Result, v config.ChatValues) bool {
+	return r.Error == nil && !r.Lite && (r.Download < v.DownloadThreshold || (!r.NoUpload && r.Upload < v.UploadThreshold))
+}
+
+// jitterAbove reports whether a full test result has more jitter than JITTER_THRESHOLD, if set.
+func jitterAbove(r stats.Result, jitterLimit time.Duration) bool {
+	return r.Error == nil && !r.Lite && jitterLimit > 0 && r.Jitter > jitterLimit
+}
+
+// criticalTier holds the CRITICAL_*_THRESHOLD limits, zero where a metric has none.
+type criticalTier struct {
+	download, upload float64
+	jitter           time.Duration
+}
+
+func newCriticalTier(cfg *config.Config) criticalTier {
+	return criticalTier{
+		download: cfg.CriticalDownloadThreshold,
+		upload:   cfg.CriticalUploadThreshold,
+		jitter:   time.Duration(cfg.CriticalJitterThreshold * float64(time.Millisecond)),
+	}
+}
+
+// severity rates an alerting result: critical if it failed, crossed a critical threshold or
+// there is no critical tier at all, otherwise a warning.
+func (c criticalTier) severity(r stats.Result) string {
+	if c == (criticalTier{}) || r.Error != nil {
+		return stats.SeverityCritical
+	}
+	if !r.Lite && (r.Download < c.download || (!r.NoUpload && r.Upload < c.upload) || (c.jitter > 0 && r.Jitter > c.jitter)) {
+		return stats.SeverityCritical
+	}
+	return stats.SeverityWarning
+}
+
+// severity rates the alert of the whole cycle by its worst result.
+func (o *testOutcome) severity() string {
+	if o.failed || o.critical == (criticalTier{}) {
+		return stats.SeverityCritical
+	}
+	for _, r := range o.results {
+		if o.critical.severity(r) == stats.SeverityCritical {
+			return stats.SeverityCritical
+		}
+	}
+	return stats.SeverityWarning
+}
+
+// message renders the alert or notices for a chat, or an empty string if there is nothing to send.
+func (o *testOutcome) message(v config.ChatValues) (string, telegram.Class) {
+	return o.render(v, o.alerts(v), 1)
+}
+
+// render is message with the alert decided by the caller, e.g. held back until enough tests
+// in a row confirm it; confirmations is how many did.
+func (o *testOutcome) render(v config.ChatValues, alert bool, confirmations int) (string, telegram.Class) {
+	if o.manual {
+		return i18n.T("outcome.manual", strings.Join(o.parts(true), "\n\n")), telegram.ClassAlert
+	}
+	if v.Verbosity == config.VerbosityOff {
+		return "", telegram.ClassAlert
+	}
+
+	notices := append(slices.Clone(o.notices), o.recoveries...)
+	if !alert {
+		if len(o.notices) == 0 {
+			return strings.Join(notices, "\n\n"), telegram.ClassRecovery
+		}
+		return strings.Join(notices, "\n\n"), telegram.ClassInfo
+	}
+
+	msg := strings.Join(o.parts(v.Verbosity == config.VerbosityFull), "\n\n")
+	if confirmations > 1 {
+		msg += i18n.T("outcome.confirmed", confirmations)
+	}
+	if len(notices) > 0 {
+		msg += "\n\n" + strings.Join(notices, "\n\n")
+	}
+	severity := o.severity()
+	data := templates.AlertData{Results: o.results, Details: o.details, Notices: notices, Confirmations: confirmations, Severity: severity, Body: msg}
+	alertKey, warningKey := "outcome.alert", "outcome.warning"
+	if o.jitterOnly(v) {
+		alertKey, warningKey = "outcome.jitter_alert", "outcome.jitter_warn"
+	}
+	if severity == stats.SeverityWarning {
+		return templates.Render(templates.Alert, data, i18n.T(warningKey, msg)), telegram.ClassWarning
+	}
+	return templates.Render(templates.Alert, data, i18n.T(alertKey, msg)), telegram.ClassAlert
+}
+
+// jitterOnly reports whether high jitter is all that makes the outcome an alert for a chat,
+// which then gets an alert about an unstable rather than a slow connection.
+func (o *testOutcome) jitterOnly(v config.ChatValues) bool {
+	if o.ruled || o.failed || len(o.details) > 0 {
+		return false
+	}
+	jitter := false
+	for _, r := range o.results {
+		if slowerThan(r, o.thresholdsAt(r, v)) {
+			return false
+		}
+		jitter = jitter || jitterAbove(r, o.jitterLimit)
+	}
+	return jitter
+}
+
+// alerts reports whether the outcome is an alert for a chat with the given thresholds.
+func (o *testOutcome) alerts(v config.ChatValues) bool {
+	alert := o.failed || len(o.details) > 0
+	for _, r := range o.results {
+		alert = alert || (!o.ruled && belowThresholds(r, o.thresholdsAt(r, v), o.jitterLimit))
+	}
+	return alert
+}
+
+// alerting is alerts for a chat, also true while an ALERT_RULES rule for the chat matches, so
+// its alert is tracked, escalated and resolved like a threshold alert.
+func (o *testOutcome) alerting(chatID int64, v config.ChatValues) bool {
+	if o.alerts(v) {
+		return true
+	}
+	for _, m := range o.ruleAlerts {
+		if len(m.Rule.Chats) == 0 || slices.Contains(m.Rule.Chats, chatID) {
+			return true
+		}
+	}
+	return false
+}
+
+// passing returns the first full, successful result, which can confirm a recovery.
+// Lite checks aren't compared with the thresholds, so they can't.
+func (o *testOutcome) passing() (stats.Result, bool) {
+	for _, r := range o.results {
+		if r.Error == nil && !r.Lite {
+			return r, true
+		}
+	}
+	return stats.Result{}, false
+}
+
+// speedAlerts tracks the scheduled test cycles of each chat: how many in a row were bad, so
+// a single noisy measurement doesn't alert (ALERT_AFTER_FAILURES), since when the chat has
+// been alerting, so the test that brings it back above its thresholds can say so, and how
+// far the alert has been escalated (ESCALATE_AFTER).
+type speedAlerts struct {
+	after      int             // bad cycles in a row needed for an alert
+	escalate   []time.Duration // alert durations after which to send follow-ups
+	escalateTo []int64         // ESCALATION_CHAT_ID
+	mu         sync.Mutex
+	streak     map[int64]int
+	since      map[int64]time.Time
+	levels     map[int64]int // escalation level of each alerting chat
+	forwarded  int           // highest level sent to ESCALATION_CHAT_ID
+}
+
+func newSpeedAlerts(cfg *config.Config) *speedAlerts {
+	return &speedAlerts{
+		after:      cfg.AlertAfterFailures,
+		escalate:   cfg.EscalateAfter,
+		escalateTo: cfg.EscalationChatIDs,
+		streak:     make(map[int64]int),
+		since:      make(map[int64]time.Time),
+		levels:     make(map[int64]int),
+	}
+}
+
+// observe records a cycle for a chat: bad if it was below the chat's thresholds or failed,
+// confirms false when nothing in it could show a recovery (only lite checks). It returns how
+// many bad cycles in a row the chat has seen, and since when the chat had been alerting if
+// this cycle ends it.
+func (s *speedAlerts) observe(chatID int64, bad, confirms bool, now time.Time) (streak int, since time.Time, recovered bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case bad:
+		s.streak[chatID]++
+	case confirms:
+		delete(s.streak, chatID)
+	}
+	streak = s.streak[chatID]
+	since, was := s.since[chatID]
+	switch {
+	case bad && streak >= s.after && !was:
+		s.since[chatID] = now
+	case !bad && was && confirms:
+		delete(s.since, chatID)
+		delete(s.levels, chatID)
+		return streak, since, true
+	}
+	return streak, time.Time{}, false
+}
+
+// escalation returns the level a chat's alert has reached at now if it is new: how many of
+// the ESCALATE_AFTER durations it has lasted.
+func (s *speedAlerts) escalation(chatID int64, now time.Time) (level int, since time.Time, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	since, alerting := s.since[chatID]
+	if !alerting {
+		return 0, time.Time{}, false
+	}
+	level = s.levels[chatID]
+	for level < len(s.escalate) && now.Sub(since) >= s.escalate[level] {
+		level++
+	}
+	if level == s.levels[chatID] {
+		return 0, time.Time{}, false
+	}
+	s.levels[chatID] = level
+	return level, since, true
+}
+
+// forward reports whether level is higher than what the escalation chats have been sent, and
+// remembers it. Once no chat is alerting anymore they start over.
+func (s *speedAlerts) forward(level int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.since) == 0 {
+		s.forwarded = 0
+	}
+	if level <= s.forwarded {
+		return false
+	}
+	s.forwarded = level
+	return true
+}
+
+// alertsNext reports whether one more bad cycle makes the chat alert.
+func (s *speedAlerts) alertsNext(chatID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streak[chatID]+1 >= s.after
+}
+
+// recoveryMessage announces that speed is back to normal, with the measurement that shows it.
+func recoveryMessage(r stats.Result, since, now time.Time) string {
+	text := i18n.T("recovery.speed", stats.FormatPeriod(now.Sub(since).Round(time.Minute)), formatResult(r))
+	data := templates.RecoveryData{Target: r.Label(), Downtime: now.Sub(since), Start: since, End: now, Text: text}
+	return templates.Render(templates.Recovery, data, text)
+}
+
+// escalationMessage is the follow-up for an alert that has lasted since since, more urgent with every level.
+// The start time is shown in loc.
+func escalationMessage(level, levels int, since, now time.Time, down bool, loc *time.Location) string {
+	severity := []string{"⚠️", "🚨", "🆘"}
+	key := "escalation.slow"
+	if down {
+		key = "escalation.down"
+	}
+	return i18n.T(key, severity[min(level, len(severity))-1], level, levels,
+		stats.FormatPeriod(now.Sub(since).Round(time.Minute)), since.In(loc).Format("15:04"))
+}
+
+// escalate sends follow-ups for alerts that have lasted past the next ESCALATE_AFTER
+// duration, to the chats concerned and to ESCALATION_CHAT_ID.
+func escalate(out *outbox, alerts *speedAlerts, o *testOutcome, now time.Time) {
+	type step struct {
+		level int
+		since time.Time
+	}
+	steps := make(map[int64]step)
+	var top step
+	for _, id := range out.audience() {
+		if level, since, ok := alerts.escalation(id, now); ok {
+			steps[id] = step{level, since}
+			if level > top.level || (level == top.level && since.Before(top.since)) {
+				top = step{level, since}
+			}
+		}
+	}
+	alert := alertSpeed
+	if o.down {
+		alert = stats.AlertOutage
+	}
+	if len(steps) > 0 {
+		log.Warn().Int("level", top.level).Time("since", top.since).Int("chats", len(steps)).Msg("Escalating alert")
+		out.broadcastAlert(alert, true, func(chatID int64, v config.ChatValues) (string, telegram.Class) {
+			s, ok := steps[chatID]
+			if !ok || v.Verbosity == config.VerbosityOff {
+				return "", telegram.ClassAlert
+			}
+			return escalationMessage(s.level, len(alerts.escalate), s.since, now, o.down, o.loc), telegram.ClassAlert
+		})
+	}
+	if alerts.forward(top.level) && len(alerts.escalateTo) > 0 {
+		out.deliver(notify.Message{Class: string(telegram.ClassAlert), Text: escalationMessage(top.level, len(alerts.escalate), top.since, now, o.down, o.loc), Critical: true, Alert: alert}, alerts.escalateTo)
+	}
+}
+
+// announce broadcasts a scheduled test outcome, tells chats whose alerts are over that speed
+// recovered, lets the bot clean up earlier alerts in the chats for which everything is fine
+// again and escalates the alerts that keep going.
+func announce(out *outbox, alerts *speedAlerts, o *testOutcome) {
+	// Maintenance neither alerts nor counts towards alerts, only notices go out
+	if o.maintenance {
+		log.Info().Msg("Maintenance window, not alerting")
+		out.broadcast(false, func(_ int64, v config.ChatValues) (string, telegram.Class) {
+			return o.render(v, false, 0)
+		})
+		return
+	}
+
+	sendRuleMatches(out, o)
+
+	now := time.Now()
+	passing, confirms := o.passing()
+	streaks := make(map[int64]int)
+	recovered := make(map[int64]time.Time)
+	for _, id := range out.audience() {
+		streak, since, ok := alerts.observe(id, o.alerting(id, out.settings.ForChat(id)), confirms, now)
+		streaks[id] = streak
+		if ok {
+			recovered[id] = since
+		}
+	}
+
+	if o.outage != "" {
+		out.broadcastAlert(stats.AlertOutage, true, func(_ int64, v config.ChatValues) (string, telegram.Class) {
+			if v.Verbosity == config.VerbosityOff {
+				return "", telegram.ClassAlert
+			}
+			return o.outage, telegram.ClassAlert
+		})
+	}
+	// The recovery from an outage is about the outage, not the speed
+	alert := alertSpeed
+	if o.back {
+		alert = stats.AlertOutage
+	}
+	out.broadcastAlert(alert, o.failed && !o.down, func(chatID int64, v config.ChatValues) (string, telegram.Class) {
+		streak := streaks[chatID]
+		if o.alerts(v) && streak < alerts.after && !o.down {
+			log.Info().Int64("chat_id", chatID).Int("streak", streak).Int("needed", alerts.after).Msg("Holding back alert until more tests confirm it")
+		}
+		text, class := o.render(v, o.alerts(v) && streak >= alerts.after && !o.down, streak)
+		since, ok := recovered[chatID]
+		if !ok || o.back || v.Verbosity == config.VerbosityOff {
+			return text, class
+		}
+		msg := recoveryMessage(passing, since, now)
+		if text == "" {
+			return msg, telegram.ClassRecovery
+		}
+		return msg + "\n\n" + text, class
+	})
+	var resolved []int64
+	for _, id := range out.audience() {
+		if id != otherBackends && !o.alerting(id, out.settings.ForChat(id)) {
+			resolved = append(resolved, id)
+		}
+	}
+	out.bot.ResolveAlerts(resolved...)
+	if len(alerts.escalate) > 0 {
+		escalate(out, alerts, o, now)
+	}
+}
+
+// sendRuleMatches alerts the chats of every rule that matched the cycle and tells them once a
+// rule that alerted no longer does. Rules without chats go to everyone, like threshold alerts.
+// Either way muted chats, quiet hours and the users' choices apply.
+func sendRuleMatches(out *outbox, o *testOutcome) {
+	send := func(alert, text string, class telegram.Class, critical bool, chats []int64) {
+		if len(chats) == 0 {
+			chats = out.audience()
+		}
+		out.broadcastTo(chats, alert, critical, func(_ int64, v config.ChatValues) (string, telegram.Class) {
+			if v.Verbosity == config.VerbosityOff {
+				return "", class
+			}
+			return text, class
+		})
+	}
+	for _, m := range o.ruleAlerts {
+		class := telegram.ClassAlert
+		if m.Rule.Severity == stats.SeverityWarning {
+			class = telegram.ClassWarning
+		}
+		send(alertRulePrefix+m.Rule.Name, ruleMessage(m), class, class == telegram.ClassAlert, m.Rule.Chats)
+	}
+	for _, m := range o.ruleCleared {
+		text := i18n.T("rules.cleared", html.EscapeString(m.Rule.Name), html.EscapeString(m.Result.Label()), formatResult(m.Result))
+		send(alertRulePrefix+m.Rule.Name, text, telegram.ClassRecovery, false, m.Rule.Chats)
+	}
+}
+
+// ruleMessage is the alert of a matching rule, with the conditions it met.
+func ruleMessage(m rules.Match) string {
+	icon := "🚨"
+	if m.Rule.Severity == stats.SeverityWarning {
+		icon = "⚠️"
+	}
+	msg := i18n.T("rules.alert", icon, html.EscapeString(m.Rule.Name), html.EscapeString(m.Result.Label()), strings.Join(m.Met, ", "))
+	if m.Streak > 1 {
+		msg += i18n.T("rules.streak", m.Streak)
+	}
+	if m.Rule.Message != "" {
+		msg += "\n" + m.Rule.Message
+	}
+	return msg + "\n\n" + formatResult(m.Result)
+}
+
+// rawJSON is the full record of the test cycle attached by /test verbose.
+func (o *testOutcome) rawJSON() []byte {
+	raw, err := json.MarshalIndent(struct {
+		Version string         `json:"version"`
+		Results []stats.Result `json:"results"`
+	}{buildVersion(), o.results}, "", "  ")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode raw test results")
+		return nil
+	}
+	return raw
+}
+
+// parts formats every result, followed by the probe reports.
+func (o *testOutcome) parts(withTraces bool) []string {
+	var parts []string
+	for i, r := range o.results {
+		part := formatResult(r)
+		if !o.ruled && jitterAbove(r, o.jitterLimit) {
+			part += i18n.T("outcome.jitter_high", r.Jitter.Milliseconds(), o.jitterLimit.Milliseconds())
+		}
+		if withTraces && o.traces[i] != "" {
+			part += "\n\n" + o.traces[i]
+		}
+		if len(o.results) > 1 {
+			part = fmt.Sprintf("🔧 <b>%s</b>\n%s", html.EscapeString(r.Label()), part)
+		}
+		parts = append(parts, part)
+	}
+	parts = append(parts, o.details...)
+	if o.manual {
+		parts = append(parts, o.extras...)
+	}
+	return parts
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/rules"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+func TestSpeedAlertsRecovery(t *testing.T) {
+	s := newSpeedAlerts(&config.Config{AlertAfterFailures: 1})
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, _, ok := s.observe(1, true, true, start); ok {
+		t.Fatal("first alert reported as a recovery")
+	}
+	// Still alerting keeps the original start
+	s.observe(1, true, true, start.Add(30*time.Minute))
+	// A cycle of lite checks can't confirm a recovery
+	if _, _, ok := s.observe(1, false, false, start.Add(time.Hour)); ok {
+		t.Fatal("lite checks reported as a recovery")
+	}
+	_, since, ok := s.observe(1, false, true, start.Add(90*time.Minute))
+	if !ok || !since.Equal(start) {
+		t.Fatalf("observe() = %v, %v; want recovery since %v", since, ok, start)
+	}
+	if _, _, ok := s.observe(1, false, true, start.Add(2*time.Hour)); ok {
+		t.Error("recovery reported twice")
+	}
+}
+
+func TestSpeedAlertsHysteresis(t *testing.T) {
+	s := newSpeedAlerts(&config.Config{AlertAfterFailures: 2})
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if streak, _, _ := s.observe(1, true, true, start); streak != 1 {
+		t.Fatalf("streak = %d, want 1", streak)
+	}
+	// A good test resets the streak without announcing a recovery that never alerted
+	if streak, _, ok := s.observe(1, false, true, start.Add(time.Hour)); streak != 0 || ok {
+		t.Fatalf("observe() = %d, %v; want 0, false", streak, ok)
+	}
+	s.observe(1, true, true, start.Add(2*time.Hour))
+	if !s.alertsNext(1) {
+		t.Fatal("alertsNext() = false after one bad test")
+	}
+	if streak, _, _ := s.observe(1, true, true, start.Add(3*time.Hour)); streak != 2 {
+		t.Fatalf("streak = %d, want 2", streak)
+	}
+	_, since, ok := s.observe(1, false, true, start.Add(4*time.Hour))
+	if !ok || !since.Equal(start.Add(3*time.Hour)) {
+		t.Fatalf("observe() = %v, %v; want recovery since the confirmed alert", since, ok)
+	}
+}
+
+func TestRuleAlerting(t *testing.T) {
+	fast := stats.Result{Download: 500, Upload: 100}
+	o := &testOutcome{ruled: true, results: []stats.Result{fast}, ruleAlerts: []rules.Match{{Rule: &rules.Rule{Name: "evening", Chats: []int64{1}}, Result: fast}}}
+	v := config.ChatValues{DownloadThreshold: 1000, UploadThreshold: 1000}
+
+	// Thresholds don't count with rules, a rule only alerts its own chats
+	if !o.alerting(1, v) {
+		t.Error("chat of the matching rule not alerting")
+	}
+	if o.alerting(2, v) {
+		t.Error("chat outside the rule alerting")
+	}
+	o.ruleAlerts[0].Rule.Chats = nil
+	if !o.alerting(2, v) {
+		t.Error("rule without chats doesn't alert every chat")
+	}
+}
+
+func TestUploadNotMeasured(t *testing.T) {
+	v := config.ChatValues{DownloadThreshold: 50, UploadThreshold: 20}
+	r := stats.Result{Download: 100, NoUpload: true}
+	if slowerThan(r, v) {
+		t.Error("result without upload counted as slow")
+	}
+	if got := (criticalTier{upload: 10}).severity(r); got != stats.SeverityWarning {
+		t.Errorf("severity = %s, want warning", got)
+	}
+	r.NoUpload = false
+	if !slowerThan(r, v) {
+		t.Error("zero upload not counted as slow")
+	}
+}
+
+func TestOutageTracker(t *testing.T) {
+	tr := &outageTracker{after: 2}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	failed := func(at time.Time) []stats.Result {
+		return []stats.Result{{Time: at, Error: errors.New("timeout")}}
+	}
+
+	if started, _, _ := tr.observe(failed(start)); started {
+		t.Fatal("outage declared after one failed test")
+	}
+	if started, _, _ := tr.observe(failed(start.Add(30 * time.Minute))); !started || !tr.down {
+		t.Fatal("no outage after two failed tests")
+	}
+	if started, _, _ := tr.observe(failed(start.Add(time.Hour))); started {
+		t.Fatal("outage declared twice")
+	}
+	end := start.Add(90 * time.Minute)
+	_, o, ok := tr.observe([]stats.Result{{Time: end}})
+	if !ok || !o.Start.Equal(start) || !o.End.Equal(end) {
+		t.Fatalf("observe() = %+v, %v; want an outage from %v to %v", o, ok, start, end)
+	}
+	if _, _, ok := tr.observe([]stats.Result{{Time: end.Add(time.Hour)}}); ok {
+		t.Error("outage ended twice")
+	}
+}
+
+func TestSpeedAlertsEscalation(t *testing.T) {
+	s := newSpeedAlerts(&config.Config{AlertAfterFailures: 1, EscalateAfter: []time.Duration{time.Hour, 4 * time.Hour}})
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	s.observe(1, true, true, start)
+	if _, _, ok := s.escalation(1, start.Add(30*time.Minute)); ok {
+		t.Fatal("escalated before the first duration")
+	}
+	if level, since, ok := s.escalation(1, start.Add(time.Hour)); !ok || level != 1 || !since.Equal(start) {
+		t.Fatalf("escalation() = %d, %v, %v; want level 1 since %v", level, since, ok, start)
+	}
+	if _, _, ok := s.escalation(1, start.Add(2*time.Hour)); ok {
+		t.Fatal("level 1 escalated twice")
+	}
+	if !s.forward(1) || s.forward(1) {
+		t.Fatal("forward() should pass each level once")
+	}
+	// Skipped cycles jump straight to the level reached
+	if level, _, _ := s.escalation(1, start.Add(5*time.Hour)); level != 2 {
+		t.Fatalf("level = %d, want 2", level)
+	}
+
+	s.observe(1, false, true, start.Add(6*time.Hour))
+	if _, _, ok := s.escalation(1, start.Add(7*time.Hour)); ok {
+		t.Fatal("escalated after recovery")
+	}
+	if !s.forward(1) {
+		t.Error("escalation chats not reset after recovery")
+	}
+}
+
+func TestCriticalTier(t *testing.T) {
+	c := criticalTier{download: 20, jitter: 50 * time.Millisecond}
+	tests := []struct {
+		r    stats.Result
+		want string
+	}{
+		{stats.Result{Download: 50, Upload: 5}, stats.SeverityWarning}, // no critical upload limit
+		{stats.Result{Download: 10, Upload: 50}, stats.SeverityCritical},
+		{stats.Result{Download: 50, Upload: 50, Jitter: 80 * time.Millisecond}, stats.SeverityCritical},
+		{stats.Result{Error: errors.New("timeout")}, stats.SeverityCritical},
+		{stats.Result{Lite: true, Download: 1}, stats.SeverityWarning},
+	}
+	for _, tt := range tests {
+		if got := c.severity(tt.r); got != tt.want {
+			t.Errorf("severity(%+v) = %s, want %s", tt.r, got, tt.want)
+		}
+	}
+	if got := (criticalTier{}).severity(stats.Result{Download: 50}); got != stats.SeverityCritical {
+		t.Errorf("without a critical tier severity = %s, want critical", got)
+	}
+}
+
+func TestNetworkWatch(t *testing.T) {
+	mgr := stats.NewManager(0)
+	now := time.Now()
+	mgr.Add(stats.Result{Time: now.Add(-time.Hour), Engine: "ookla", Meta: map[string]string{"public_ip": "203.0.113.5", "isp": "Fiber Co"}})
+	w := newNetworkWatch(mgr, now)
+
+	result := func(ip, isp string) stats.Result {
+		return stats.Result{Time: now, Engine: "ookla", Meta: map[string]string{"public_ip": ip, "isp": isp}}
+	}
+	if notices := w.check([]stats.Result{result("203.0.113.5", "Fiber Co")}); len(notices) != 0 {
+		t.Fatalf("unchanged network reported: %v", notices)
+	}
+	// A test without user info keeps the last known network
+	if notices := w.check([]stats.Result{result("", "")}); len(notices) != 0 {
+		t.Fatalf("missing user info reported: %v", notices)
+	}
+	notices := w.check([]stats.Result{result("198.51.100.7", "LTE Mobile")})
+	if len(notices) != 1 || !strings.Contains(notices[0], "203.0.113.5 → 198.51.100.7") || !strings.Contains(notices[0], "Fiber Co → LTE Mobile") {
+		t.Fatalf("failover not reported: %v", notices)
+	}
+	// Another engine has its own address
+	other := result("192.0.2.1", "LTE Mobile")
+	other.Engine = "cloudflare"
+	if notices := w.check([]stats.Result{other}); len(notices) != 0 {
+		t.Fatalf("first result of another engine reported: %v", notices)
+	}
+}
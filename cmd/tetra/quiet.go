@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/telegram"
+	"github.com/rs/zerolog/log"
+)
+
+// maxDigestLen keeps the digest below Telegram's 4096 character message limit.
+const maxDigestLen = 3800
+
+// quietHours holds back alerts during the night and sends them as one digest when the window ends.
+// Held alerts are kept in the Telegram message queue, so they survive restarts.
+// A nil *quietHours means quiet hours are disabled.
+type quietHours struct {
+	start, end time.Duration // time of day
+	loc        *time.Location
+	digest     bool
+}
+
+// heldStore keeps held alerts until the digest is sent, see telegram.Bot.Hold.
+type heldStore interface {
+	Hold(chatID int64, text string, at time.Time)
+	TakeHeld() map[int64][]telegram.Held
+}
+
+func newQuietHours(cfg *config.Config, loc *time.Location) *quietHours {
+	if cfg.QuietHoursStart == cfg.QuietHoursEnd {
+		return nil
+	}
+	return &quietHours{
+		start:  cfg.QuietHoursStart,
+		end:    cfg.QuietHoursEnd,
+		loc:    loc,
+		digest: cfg.QuietHoursDigest,
+	}
+}
+
+// active reports whether t falls into the window, which may span midnight.
+func (q *quietHours) active(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	t = t.In(q.loc)
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.start < q.end {
+		return clock >= q.start && clock < q.end
+	}
+	return clock >= q.start || clock < q.end
+}
+
+// hold keeps an alert in store for the digest (or drops it) if quiet hours are active at now,
+// and reports whether it did so.
+func (q *quietHours) hold(store heldStore, chatID int64, text string, now time.Time) bool {
+	if !q.active(now) {
+		return false
+	}
+	if q.digest {
+		store.Hold(chatID, text, now)
+	}
+	log.Debug().Int64("chat_id", chatID).Bool("digest", q.digest).Msg("Alert held back during quiet hours")
+	return true
+}
+
+// Loop sends the held alerts every time quiet hours end, and right away for alerts
+// held before a restart once the window is already over.
+func (q *quietHours) Loop(ctx context.Context, out *outbox) {
+	if q == nil || !q.digest {
+		return
+	}
+	if !q.active(time.Now()) {
+		q.flush(out)
+	}
+	for {
+		now := time.Now().In(q.loc)
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, q.loc)
+		next := midnight.Add(q.end)
+		if !next.After(now) {
+			next = midnight.AddDate(0, 0, 1).Add(q.end)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+			q.flush(out)
+		}
+	}
+}
+
+func (q *quietHours) flush(out *outbox) {
+	for id, alerts := range out.bot.TakeHeld() {
+		log.Info().Int64("chat_id", id).Int("alerts", len(alerts)).Msg("Sending quiet hours digest")
+		out.send(telegram.ClassAlert, q.format(alerts), false, id)
+	}
+}
+
+// format joins whole alerts, oldest first, as long as they fit into one message.
+func (q *quietHours) format(alerts []telegram.Held) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("quiet.digest", formatClock(q.start), formatClock(q.end)))
+	for i, a := range alerts {
+		entry := fmt.Sprintf("\n\n<i>%s</i>\n%s", a.Time.In(q.loc).Format("15:04"), a.Text)
+		if sb.Len()+len(entry) > maxDigestLen {
+			sb.WriteString("\n\n" + i18n.T("quiet.digest_more", len(alerts)-i))
+			break
+		}
+		sb.WriteString(entry)
+	}
+	return sb.String()
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/telegram"
+)
+
+// heldAlerts is a heldStore in memory.
+type heldAlerts map[int64][]telegram.Held
+
+func (h heldAlerts) Hold(chatID int64, text string, at time.Time) {
+	h[chatID] = append(h[chatID], telegram.Held{Time: at, Text: text})
+}
+
+func (h heldAlerts) TakeHeld() map[int64][]telegram.Held {
+	held := maps.Clone(h)
+	clear(h)
+	return held
+}
+
+func TestQuietHoursActive(t *testing.T) {
+	cfg := &config.Config{QuietHoursStart: 23 * time.Hour, QuietHoursEnd: 7 * time.Hour, QuietHoursDigest: true}
+	q := newQuietHours(cfg, time.UTC)
+
+	for _, tc := range []struct {
+		clock  string
+		active bool
+	}{
+		{"22:59", false},
+		{"23:00", true},
+		{"03:00", true},
+		{"06:59", true},
+		{"07:00", false},
+		{"12:00", false},
+	} {
+		ts, _ := time.Parse("15:04", tc.clock)
+		if got := q.active(ts); got != tc.active {
+			t.Errorf("active(%s) = %v, want %v", tc.clock, got, tc.active)
+		}
+	}
+
+	var disabled *quietHours
+	if disabled.active(time.Now()) || newQuietHours(&config.Config{}, time.UTC) != nil {
+		t.Error("quiet hours should be disabled without a window")
+	}
+}
+
+func TestQuietHoursDigest(t *testing.T) {
+	cfg := &config.Config{QuietHoursStart: 23 * time.Hour, QuietHoursEnd: 7 * time.Hour, QuietHoursDigest: true}
+	q := newQuietHours(cfg, time.UTC)
+
+	store := heldAlerts{}
+	night := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	for range 10 {
+		if !q.hold(store, 1, strings.Repeat("x", 1000), night) {
+			t.Fatal("alert at 03:00 was not held")
+		}
+	}
+	if q.hold(store, 1, "day", night.Add(6*time.Hour)) {
+		t.Error("alert at 09:00 was held")
+	}
+
+	digest := q.format(store.TakeHeld()[1])
+	if len(digest) > maxDigestLen+100 {
+		t.Errorf("digest too long: %d", len(digest))
+	}
+	if !strings.Contains(digest, "23:00–07:00") || !strings.Contains(digest, "...and 7 more") {
+		t.Errorf("unexpected digest: %s", digest[:200])
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+func slaTarget(cfg *config.Config) stats.SLATarget {
+	return stats.SLATarget{
+		Download:   cfg.SLADownload,
+		Upload:     cfg.SLAUpload,
+		Compliance: cfg.SLACompliance,
+		Uptime:     cfg.SLAUptime,
+	}
+}
+
+// slaWatch alerts as soon as the SLA can't be met anymore in the current billing month, once
+// for speed and once for uptime.
+type slaWatch struct {
+	target  stats.SLATarget
+	day     int
+	mu      sync.Mutex
+	alerted map[string]time.Time // breached part of the SLA → start of the billing month
+}
+
+// newSLAWatch returns nil when no SLA is configured.
+func newSLAWatch(cfg *config.Config) *slaWatch {
+	target := slaTarget(cfg)
+	if target.Download == 0 && target.Upload == 0 && target.Uptime == 0 {
+		return nil
+	}
+	return &slaWatch{target: target, day: cfg.SLABillingDay, alerted: make(map[string]time.Time)}
+}
+
+// check returns the alerts for the parts of the SLA breached since the last check. now is in
+// the configured time zone, which billing months follow.
+func (w *slaWatch) check(statsMgr *stats.Manager, now time.Time, interval time.Duration) []string {
+	from, to := stats.BillingPeriod(now, w.day)
+	r := statsMgr.GetSLAReport(w.target, from, to, now, interval)
+	var alerts []string
+	if r.SpeedBreached() && w.first("speed", from) {
+		alerts = append(alerts, i18n.T("sla.speed_breach", r.Tests-r.Compliant, r.Tests, w.target.Download, w.target.Upload,
+			r.BestSpeedCompliance(), w.target.Compliance))
+	}
+	if r.UptimeBreached() && w.first("uptime", from) {
+		alerts = append(alerts, i18n.T("sla.uptime_breach", stats.FormatPeriod(r.Downtime.Round(time.Minute)),
+			stats.FormatPeriod(r.AllowedDowntime().Round(time.Minute)), w.target.Uptime))
+	}
+	return alerts
+}
+
+// first reports whether a breach hasn't been alerted in the billing month starting at from, and remembers it.
+func (w *slaWatch) first(part string, from time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.alerted[part].Equal(from) {
+		return false
+	}
+	w.alerted[part] = from
+	return true
+}
+
+// slaMonthReport summarizes the billing month that ended at the last midnight, if there is an
+// SLA and today, in loc, is the billing day.
+func slaMonthReport(cfg *config.Config, statsMgr *stats.Manager, now time.Time, loc *time.Location) (string, bool) {
+	target := slaTarget(cfg)
+	now = now.In(loc)
+	if newSLAWatch(cfg) == nil || now.Day() != cfg.SLABillingDay {
+		return "", false
+	}
+	start, _ := stats.BillingPeriod(now, cfg.SLABillingDay)
+	from, to := stats.BillingPeriod(start.Add(-time.Nanosecond), cfg.SLABillingDay)
+	return statsMgr.GetSLAReport(target, from, to, now, 0).String(), true
+}
+
+// planMonthReport measures the billing month that ended at the last midnight against the
+// advertised plan, if there is one and today, in loc, is the billing day.
+func planMonthReport(cfg *config.Config, statsMgr *stats.Manager, now time.Time, loc *time.Location) (string, bool) {
+	now = now.In(loc)
+	if cfg.PlanDownload <= 0 && cfg.PlanUpload <= 0 || now.Day() != cfg.SLABillingDay {
+		return "", false
+	}
+	start, _ := stats.BillingPeriod(now, cfg.SLABillingDay)
+	from, to := stats.BillingPeriod(start.Add(-time.Nanosecond), cfg.SLABillingDay)
+	plan := stats.Plan{Download: cfg.PlanDownload, Upload: cfg.PlanUpload}
+	return statsMgr.GetPlanReport(plan, from, to, loc).String(), true
+}
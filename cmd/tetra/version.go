@@ -0,0 +1,23 @@
+package main
+
+import "runtime/debug"
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// buildVersion falls back to the VCS revision Go embeds in the binary for untagged builds.
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+			return version + "-" + s.Value[:7]
+		}
+	}
+	return version
+}
@@ -4,13 +4,25 @@ go 1.24.0
 
 require (
 	github.com/go-telegram/bot v1.17.0
+	github.com/golang/snappy v1.0.0
 	github.com/joho/godotenv v1.5.1
+	github.com/parquet-go/parquet-go v0.25.1
 	github.com/rs/zerolog v1.34.0
 	github.com/showwin/speedtest-go v1.7.10
+	github.com/wcharczuk/go-chart/v2 v2.1.2
+	go.etcd.io/bbolt v1.4.3
+	google.golang.org/protobuf v1.36.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.28.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	golang.org/x/image v0.18.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
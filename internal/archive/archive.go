@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// Archiver periodically uploads a compressed snapshot of the result history
+// to S3-compatible storage so long-term data survives host loss.
+type Archiver struct {
+	s3       *S3Client
+	statsMgr *stats.Manager
+	prefix   string
+	format   string
+	interval time.Duration
+}
+
+func New(cfg *config.Config, statsMgr *stats.Manager) (*Archiver, error) {
+	switch cfg.ArchiveFormat {
+	case "jsonl", "csv", "parquet":
+	default:
+		return nil, fmt.Errorf("unsupported ARCHIVE_FORMAT '%s' (expected jsonl, csv or parquet)", cfg.ArchiveFormat)
+	}
+	client, err := NewS3Client(cfg.ArchiveS3Endpoint, cfg.ArchiveS3Region, cfg.ArchiveS3Bucket, cfg.ArchiveS3AccessKey, cfg.ArchiveS3SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Archiver{
+		s3:       client,
+		statsMgr: statsMgr,
+		prefix:   cfg.ArchiveS3Prefix,
+		format:   cfg.ArchiveFormat,
+		interval: cfg.ArchiveInterval,
+	}, nil
+}
+
+// Loop uploads an archive every interval until ctx is cancelled.
+func (a *Archiver) Loop(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	log.Info().Dur("interval", a.interval).Str("format", a.format).Msg("Scheduled result archive upload")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.Upload(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to upload result archive")
+			}
+		}
+	}
+}
+
+// Upload compresses the full result history and stores it as a new object.
+func (a *Archiver) Upload(ctx context.Context) error {
+	history, err := a.statsMgr.History()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	key := fmt.Sprintf("%stetra-results-%s.%s", a.prefix, time.Now().UTC().Format("20060102T150405Z"), a.format)
+	contentType := "application/gzip"
+
+	if a.format == "parquet" {
+		// Parquet pages are compressed already
+		if err := stats.WriteParquet(&buf, history); err != nil {
+			return fmt.Errorf("failed to encode archive: %w", err)
+		}
+		contentType = "application/vnd.apache.parquet"
+	} else {
+		gz := gzip.NewWriter(&buf)
+		if a.format == "csv" {
+			err = stats.WriteCSV(gz, history)
+		} else {
+			err = stats.WriteJSONL(gz, history)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode archive: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress archive: %w", err)
+		}
+		key += ".gz"
+	}
+
+	if err := a.s3.PutObject(ctx, key, buf.Bytes(), contentType); err != nil {
+		return err
+	}
+
+	log.Info().Str("key", key).Int("results", len(history)).Int("bytes", buf.Len()).Msg("Uploaded result archive")
+	return nil
+}
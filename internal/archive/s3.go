@@ -0,0 +1,135 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Client uploads objects to S3-compatible storage (AWS S3, MinIO, ...)
+// using path-style addressing and AWS Signature Version 4.
+type S3Client struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+}
+
+func NewS3Client(endpoint, region, bucket, accessKey, secretKey string) (*S3Client, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint '%s'", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	return &S3Client{
+		client:    &http.Client{Timeout: 5 * time.Minute},
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+	}, nil
+}
+
+// PutObject uploads data under key.
+func (c *S3Client) PutObject(ctx context.Context, key string, data []byte, contentType string) error {
+	u := *c.endpoint
+	u.Path = "/" + c.bucket + "/" + key
+	u.RawPath = "/" + uriEncode(c.bucket) + "/" + uriEncode(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	c.sign(req, data, time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 upload returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds SigV4 authentication headers to req.
+func (c *S3Client) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode escapes a path per SigV4 rules: everything except unreserved characters and '/'.
+func uriEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || ch == '/' {
+			sb.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", ch)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const manifestName = "manifest.json"
+
+// Entry is a file included in a backup archive under a stable logical name,
+// so it can be restored to a different path on another host.
+type Entry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Read returns a consistent copy of a file that may be written while the backup runs, such
+	// as a database. Without it the file is read as is.
+	Read func() ([]byte, error) `json:"-"`
+}
+
+// Manifest describes the contents of a backup archive.
+type Manifest struct {
+	Created        time.Time `json:"created"`
+	StorageBackend string    `json:"storage_backend"`
+	Entries        []Entry   `json:"entries"`
+}
+
+// Create writes a gzip-compressed tar archive containing every existing entry plus a manifest.
+// Missing files are skipped, e.g. when no message queue has been written yet.
+func Create(dst string, storageBackend string, entries []Entry) (*Manifest, error) {
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	m := &Manifest{Created: time.Now().UTC(), StorageBackend: storageBackend}
+	for _, e := range entries {
+		read := e.Read
+		if read == nil {
+			read = func() ([]byte, error) { return os.ReadFile(e.Path) }
+		}
+		data, err := read()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Path, err)
+		}
+		if err := writeFile(tw, e.Name, data); err != nil {
+			return nil, err
+		}
+		m.Entries = append(m.Entries, e)
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, manifestName, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return m, f.Close()
+}
+
+// Read loads the manifest and file contents from a backup archive.
+func Read(src string) (*Manifest, map[string][]byte, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid backup archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid backup archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[hdr.Name] = data
+	}
+
+	raw, ok := files[manifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("backup archive has no %s", manifestName)
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	return &m, files, nil
+}
+
+// WriteEntry restores data to path. Existing files are only replaced when force is set.
+func WriteEntry(path string, data []byte, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+		}
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
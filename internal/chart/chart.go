@@ -0,0 +1,136 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+	gochart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// ErrNotEnoughData is returned when there are fewer than two results to draw a line through.
+var ErrNotEnoughData = errors.New("not enough results to draw a chart")
+
+// Metric selects what a chart shows.
+type Metric string
+
+const (
+	MetricAll      Metric = "all" // download and upload, with ping on a second axis
+	MetricDownload Metric = "download"
+	MetricUpload   Metric = "upload"
+	MetricPing     Metric = "ping"
+	MetricJitter   Metric = "jitter"
+)
+
+// Metrics lists every metric a chart can show.
+var Metrics = []Metric{MetricAll, MetricDownload, MetricUpload, MetricPing, MetricJitter}
+
+var (
+	colorDownload = drawing.ColorFromHex("1f77b4")
+	colorUpload   = drawing.ColorFromHex("2ca02c")
+	colorPing     = drawing.ColorFromHex("d62728")
+	colorJitter   = drawing.ColorFromHex("ff7f0e")
+)
+
+// Render draws the metric over time as a PNG. Speeds are in Mbps, latencies in ms, and all
+// axes start at zero so a dip isn't exaggerated by auto-scaling. Failed tests and lite checks
+// are skipped; times are labeled in loc.
+func Render(results []stats.Result, metric Metric, loc *time.Location) ([]byte, error) {
+	var times []time.Time
+	var download, upload, ping, jitter []float64
+	for _, r := range results {
+		if r.Error != nil || r.Lite {
+			continue
+		}
+		times = append(times, r.Time)
+		download = append(download, r.Download)
+		upload = append(upload, r.Upload)
+		ping = append(ping, float64(r.Ping)/float64(time.Millisecond))
+		jitter = append(jitter, float64(r.Jitter)/float64(time.Millisecond))
+	}
+	if len(times) < 2 {
+		return nil, ErrNotEnoughData
+	}
+
+	series := func(name string, color drawing.Color, values []float64) gochart.TimeSeries {
+		return gochart.TimeSeries{
+			Name:    name,
+			Style:   gochart.Style{StrokeColor: color, StrokeWidth: 2},
+			XValues: times,
+			YValues: values,
+		}
+	}
+	var lines []gochart.Series
+	unit, top := "Mbps", 1.0
+	switch metric {
+	case MetricDownload:
+		lines = []gochart.Series{series("Download", colorDownload, download)}
+		top = max(top, maxOf(download))
+	case MetricUpload:
+		lines = []gochart.Series{series("Upload", colorUpload, upload)}
+		top = max(top, maxOf(upload))
+	case MetricPing:
+		lines = []gochart.Series{series("Ping", colorPing, ping)}
+		unit, top = "ms", max(top, maxOf(ping))
+	case MetricJitter:
+		lines = []gochart.Series{series("Jitter", colorJitter, jitter)}
+		unit, top = "ms", max(top, maxOf(jitter))
+	default:
+		pingLine := series("Ping", colorPing, ping)
+		pingLine.Style.StrokeWidth, pingLine.Style.StrokeDashArray = 1, []float64{5, 3}
+		pingLine.YAxis = gochart.YAxisSecondary
+		lines = []gochart.Series{series("Download", colorDownload, download), series("Upload", colorUpload, upload), pingLine}
+		top = max(top, maxOf(download), maxOf(upload))
+	}
+
+	// Longer spans need the date rather than the time of day
+	layout := "15:04"
+	if times[len(times)-1].Sub(times[0]) > 36*time.Hour {
+		layout = "01-02"
+	}
+	graph := gochart.Chart{
+		Width:  1000,
+		Height: 500,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 40, Left: 30, Right: 10, Bottom: 10},
+		},
+		XAxis: gochart.XAxis{
+			ValueFormatter: func(v interface{}) string {
+				if f, ok := v.(float64); ok {
+					return gochart.TimeFromFloat64(f).In(loc).Format(layout)
+				}
+				return ""
+			},
+		},
+		YAxis: gochart.YAxis{
+			Name:           unit,
+			Range:          &gochart.ContinuousRange{Min: 0, Max: top * 1.1},
+			ValueFormatter: gochart.IntValueFormatter,
+		},
+		Series: lines,
+	}
+	if metric == MetricAll {
+		graph.YAxisSecondary = gochart.YAxis{
+			Name:           "ms",
+			Range:          &gochart.ContinuousRange{Min: 0, Max: max(1, maxOf(ping)) * 1.2},
+			ValueFormatter: gochart.IntValueFormatter,
+		}
+	}
+	graph.Elements = []gochart.Renderable{gochart.LegendThin(&graph)}
+
+	var buf bytes.Buffer
+	if err := graph.Render(gochart.PNG, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func maxOf(values []float64) float64 {
+	m := 0.0
+	for _, v := range values {
+		m = max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,33 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Now()
+	results := []stats.Result{
+		{Time: now.Add(-2 * time.Hour), Download: 90, Upload: 40, Ping: 15 * time.Millisecond},
+		{Time: now.Add(-1 * time.Hour), Download: 20, Upload: 10, Ping: 80 * time.Millisecond},
+		{Time: now, Error: errors.New("timeout")},
+	}
+
+	for _, metric := range Metrics {
+		png, err := Render(results, metric, time.UTC)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", metric, err)
+		}
+		if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+			t.Errorf("Expected PNG output for %s", metric)
+		}
+	}
+
+	if _, err := Render(results[:1], MetricAll, time.UTC); !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("Expected ErrNotEnoughData for a single result, got %v", err)
+	}
+}
@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,20 +15,215 @@ import (
 )
 
 type Config struct {
-	TelegramToken     string `json:"-"`
+	TelegramToken string `json:"-"`
+	// Second bot that sends alerts once TelegramToken keeps failing, e.g. revoked or blocked
+	TelegramBackupToken string `json:"-"`
+	TelegramQueuePath   string
+	SettingsPath        string // where settings changed from the bot are kept, empty = in-memory only
+	AuditLogPath        string // JSON lines file of every command, empty = the latest in memory only
+	AlertHistoryPath    string // JSON lines file of alert events for /alerts, empty = the latest in memory only
+	TelegramProxy       string `json:"-"` // may contain credentials
+	// Public HTTPS URL Telegram posts updates to, empty = long polling
+	TelegramWebhookURL    string
+	TelegramWebhookSecret string `json:"-"`
+	// Certificate and key to serve the health check and webhook server over HTTPS
+	HTTPTLSCert       string
+	HTTPTLSKey        string
 	ChatIDs           []int64
+	ChatUsernames     []string // public channels or groups given as @username, resolved by the bot
+	AllowedUserIDs    []int64  // users allowed to use the bot, empty = anyone in the configured chats
+	AdminUserIDs      []int64  // users who may run tests and change settings, empty = every allowed user
 	DownloadThreshold float64
 	UploadThreshold   float64
+	JitterThreshold   float64 // ms, 0 disables jitter alerts
 	CheckInterval     time.Duration
 	DailyReportHour   int
+	ReportChart       bool // attach a PNG chart of the last 24h to the daily report
+	ReportPin         bool // pin the daily report, unpinning the previous one
+	WeeklyReport      bool // follow the daily report on Mondays with a roll-up of the last week
+	MonthlyReport     bool // follow the daily report on the 1st with a roll-up of the last month
+	// Critical tier below the (warning) download, upload and jitter thresholds; 0 leaves a
+	// metric without one, and without any every alert is critical
+	CriticalDownloadThreshold float64
+	CriticalUploadThreshold   float64
+	CriticalJitterThreshold   float64 // ms
+	// Download and upload thresholds replacing those of every chat during scheduled windows
+	ThresholdSchedule ThresholdSchedule
+	// What happens to sent alerts once a test passes again or ALERT_TTL runs out: off, delete or edit
+	AlertCleanup  string
+	AlertTTL      time.Duration // 0 = only clean up on recovery
+	AlertMentions []string      // @usernames mentioned in group chats when the connection is lost or a test fails
+	// Scheduled tests in a row that must be below the thresholds or fail before a chat is alerted
+	AlertAfterFailures int
+	// Scheduled test cycles in a row that must fail completely before the internet is reported down, 0 disables
+	OutageAfterFailures int
+	// Alert when the public IP or ISP seen by the speed test changes, e.g. after a failover
+	NetworkChangeAlerts bool
+	// How long an alert may last before each follow-up of rising severity, ascending
+	EscalateAfter     []time.Duration
+	EscalationChatIDs []int64 // extra chats that get the follow-ups, e.g. an on-call group
+	// Percent a full test may fall below the usual speed at its hour of day before alerting, 0 disables
+	BaselineDrop float64
+	BaselineDays int // days of results the usual speed is averaged over
+	// Percent the daily averages may steadily fall over TrendDays before the daily report warns, 0 disables
+	TrendDrop float64
+	TrendDays int
+	HoursDays int // days /hours ranks the hours of the day over unless given
+	// Ascending download bounds in Mbps splitting the reports' speed distribution, empty leaves it out
+	SpeedBuckets []float64
+	// Service level promised by the ISP, checked over billing months starting on SLABillingDay
+	SLADownload   float64 // Mbps, 0 with SLAUpload 0 doesn't check speed
+	SLAUpload     float64 // Mbps
+	SLACompliance float64 // percent of tests that must reach the speed
+	SLAUptime     float64 // percent, 0 doesn't check uptime
+	SLABillingDay int
+	// Speeds the ISP advertises for the plan, which reports express the averages as a share of;
+	// 0 for both leaves them out
+	PlanDownload float64 // Mbps
+	PlanUpload   float64 // Mbps
+	// Alerts are held between these times of day (in TZ); equal values disable quiet hours
+	QuietHoursStart  time.Duration
+	QuietHoursEnd    time.Duration
+	QuietHoursDigest bool // send held alerts as one message when quiet hours end, otherwise drop them
+	// Periods in which tests run without alerting, see MaintenanceWindow
+	MaintenanceWindows []MaintenanceWindow
+	// Message classes (alert, recovery, report, info) sent without a notification sound
+	SilentNotifications []string
+	// Forum topic (message_thread_id) per message class in forum chats, "default" covers the others
+	TelegramTopics    map[string]int
+	TelegramParseMode string // html, markdownv2 or plain
 	TimeZone          string
+	BotLang           string // language of bot messages, see internal/i18n
+	TemplatesDir      string // directory with *.tmpl files replacing built-in messages, see internal/templates
+	AlertRules        string // YAML or JSON file with rules replacing the threshold comparison, see internal/rules
 	LogLevel          string
+	SpeedtestEngines  []string
+	SpeedtestServerID string
+	// How long the selected Ookla server is reused before re-discovery
+	SpeedtestServerCacheTTL time.Duration
+	// Run every engine once per listed IP family ("ipv4", "ipv6"); empty uses the system default
+	IPFamilies      []string
+	IPFamilyMaxDiff float64 // percent one family may fall behind the other before alerting
+	// Bind tests to these local interfaces or source IPs, one run each; empty uses the routing table
+	BindInterfaces []string
+	SpeedtestProxy string `json:"-"` // may contain credentials
+	// Monthly bytes tests may use before scheduled tests switch to lite checks, 0 disables
+	DataBudget   uint64
+	LiteCheckURL string
+	// Run a full test every Nth cycle and lite checks in between (1 = always full)
+	FullTestEvery         int
+	LitePingThreshold     float64 // ms, a slower lite check triggers a full test, 0 disables
+	LiteDownloadThreshold float64 // Mbps, a slower lite check triggers a full test
+	LibreSpeedURL         string
+	OoklaCLIPath          string
+	Iperf3Path            string
+	Iperf3Server          string
+	Iperf3Mode            string
+	Iperf3Bandwidth       string
+	Iperf3Duration        time.Duration
+
+	HTTPProbeDownloadURL string
+	HTTPProbeUploadURL   string
+	HTTPProbeUploadSize  int64
+	StorageBackend       string
+	StoragePath          string
+	Retention            time.Duration
+
+	// InfluxDB export (disabled when InfluxURL is empty)
+	InfluxURL         string
+	InfluxVersion     int
+	InfluxToken       string `json:"-"`
+	InfluxOrg         string
+	InfluxBucket      string
+	InfluxDatabase    string
+	InfluxUser        string
+	InfluxPassword    string `json:"-"`
+	InfluxMeasurement string
+
+	// Prometheus remote_write export (disabled when RemoteWriteURL is empty)
+	RemoteWriteURL      string
+	RemoteWriteUser     string
+	RemoteWritePassword string `json:"-"`
+	RemoteWriteToken    string `json:"-"`
+	RemoteWriteJob      string
+	RemoteWriteInstance string
+
+	// Periodic history archive to S3-compatible storage (disabled when ArchiveS3Endpoint is empty)
+	ArchiveS3Endpoint  string
+	ArchiveS3Region    string
+	ArchiveS3Bucket    string
+	ArchiveS3AccessKey string `json:"-"`
+	ArchiveS3SecretKey string `json:"-"`
+	ArchiveS3Prefix    string
+	ArchiveFormat      string
+	ArchiveInterval    time.Duration
+
+	// Continuous ping monitor between speed tests (disabled when PingMonitorHost is empty)
+	PingMonitorHost            string
+	PingMonitorInterval        time.Duration
+	PingMonitorTimeout         time.Duration
+	PingMonitorOutageThreshold int
+
+	// DNS lookup probes run with every speed test (disabled when DNSProbeHosts is empty)
+	DNSProbeHosts         []string
+	DNSProbeResolvers     []string
+	DNSProbeTimeout       time.Duration
+	DNSProbeSlowThreshold time.Duration
+
+	// Traceroute attached to alerts (disabled when TraceroutePath is empty)
+	TraceroutePath   string
+	TracerouteTarget string
+
+	// HTTP endpoint availability checks run with every speed test (disabled when EndpointChecks is empty)
+	EndpointChecks        []string
+	EndpointCheckTimeout  time.Duration
+	EndpointCheckInsecure bool
+
+	// Backends alerts and reports are sent to, see NotifierNames, and how often each one is retried
+	Notifiers       []string
+	NotifyRetries   int
+	SlackWebhookURL string `json:"-"` // the URL is the credential
+	SlackBotToken   string `json:"-"`
+	SlackChannel    string // channel posted to with SlackBotToken
+
+	DiscordWebhookURL string `json:"-"`
+
+	// SMTP server and recipients of email notifications
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string `json:"-"`
+	SMTPFrom     string
+	SMTPTo       []string
+
+	// Pushover app and recipient, priorities by message class and the emergency priority of
+	// critical alerts, repeated every PushoverRetry until acknowledged or PushoverExpire passes
+	PushoverToken    string `json:"-"`
+	PushoverUser     string `json:"-"`
+	PushoverPriority map[string]int
+	PushoverCritical int
+	PushoverRetry    time.Duration
+	PushoverExpire   time.Duration
+}
+
+// NotifiesVia reports whether alerts and reports go to the named backend.
+func (c *Config) NotifiesVia(name string) bool {
+	return slices.Contains(c.Notifiers, name)
 }
 
 func (c Config) String() string {
 	return fmt.Sprintf("Config{ChatIDs:%v, Levels: DL=%.0f/UL=%.0f}", c.ChatIDs, c.DownloadThreshold, c.UploadThreshold)
 }
 
+// MessageClasses are the kinds of bot messages that can be configured separately.
+var MessageClasses = []string{"alert", "warning", "recovery", "report", "info"}
+
+// NotifierNames are the notification backends NOTIFIERS can select.
+var NotifierNames = []string{"telegram", "slack", "discord", "email", "pushover"}
+
+// webhookSecretPattern is what Telegram accepts as a webhook secret_token.
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
 func Load() (*Config, error) {
 	// Load .env file, but don't fail if it doesn't exist (environment variables might be set directly)
 	_ = godotenv.Load()
@@ -34,57 +233,477 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("TELEGRAM_TOKEN is required")
 	}
 
+	// Without CHAT_ID the first authorized /start binds a chat, which is kept in SETTINGS_PATH
 	chatIDsStr := os.Getenv("CHAT_ID")
-	if chatIDsStr == "" {
-		return nil, fmt.Errorf("CHAT_ID is required")
+	if chatIDsStr == "" && os.Getenv("SETTINGS_PATH") == "" {
+		return nil, fmt.Errorf("CHAT_ID is required, or SETTINGS_PATH to bind a chat with /start")
 	}
 
 	var chatIDs []int64
+	var chatUsernames []string
 	for _, idStr := range strings.Split(chatIDsStr, ",") {
 		idStr = strings.TrimSpace(idStr)
 		if idStr == "" {
 			continue
 		}
+		if strings.HasPrefix(idStr, "@") {
+			chatUsernames = append(chatUsernames, idStr)
+			continue
+		}
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid CHAT_ID element '%s': %w", idStr, err)
 		}
 		chatIDs = append(chatIDs, id)
 	}
-	if len(chatIDs) == 0 {
+	if chatIDsStr != "" && len(chatIDs) == 0 && len(chatUsernames) == 0 {
 		return nil, fmt.Errorf("CHAT_ID must contain at least one valid ID")
 	}
 
+	allowedUserIDs, err := getEnvIDs("ALLOWED_USER_IDS")
+	if err != nil {
+		return nil, err
+	}
+	adminUserIDs, err := getEnvIDs("ADMIN_USER_IDS")
+	if err != nil {
+		return nil, err
+	}
+	quietStart, quietEnd, err := getEnvClockRange("QUIET_HOURS")
+	if err != nil {
+		return nil, err
+	}
+	var maintenanceWindows []MaintenanceWindow
+	for _, item := range getEnvList("MAINTENANCE_WINDOWS", nil) {
+		w, err := parseMaintenanceWindow(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAINTENANCE_WINDOWS element '%s': %w", item, err)
+		}
+		maintenanceWindows = append(maintenanceWindows, w)
+	}
+	var thresholdSchedule ThresholdSchedule
+	for _, item := range getEnvList("THRESHOLD_SCHEDULE", nil) {
+		w, err := parseThresholdWindow(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid THRESHOLD_SCHEDULE element '%s': %w", item, err)
+		}
+		thresholdSchedule = append(thresholdSchedule, w)
+	}
+	telegramTopics, err := getEnvClassValues("TELEGRAM_TOPICS", "thread_id")
+	if err != nil {
+		return nil, err
+	}
+	silentNotifications := getEnvList("SILENT_NOTIFICATIONS", nil)
+	for _, class := range silentNotifications {
+		if !slices.Contains(MessageClasses, class) {
+			return nil, fmt.Errorf("invalid SILENT_NOTIFICATIONS element '%s' (available: %s)", class, strings.Join(MessageClasses, ", "))
+		}
+	}
+
+	webhookURL, webhookSecret := os.Getenv("TELEGRAM_WEBHOOK_URL"), os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	if webhookURL != "" {
+		u, err := url.Parse(webhookURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("TELEGRAM_WEBHOOK_URL must be an https:// URL, got '%s'", webhookURL)
+		}
+		if !webhookSecretPattern.MatchString(webhookSecret) {
+			return nil, fmt.Errorf("TELEGRAM_WEBHOOK_SECRET is required with TELEGRAM_WEBHOOK_URL (1-256 characters A-Z, a-z, 0-9, _ and -)")
+		}
+	}
+	parseMode := strings.ToLower(getEnvString("TELEGRAM_PARSE_MODE", "html"))
+	if !slices.Contains([]string{"html", "markdownv2", "plain"}, parseMode) {
+		return nil, fmt.Errorf("invalid TELEGRAM_PARSE_MODE '%s' (available: html, markdownv2, plain)", parseMode)
+	}
+	alertCleanup := strings.ToLower(getEnvString("ALERT_CLEANUP", "off"))
+	if !slices.Contains([]string{"off", "delete", "edit"}, alertCleanup) {
+		return nil, fmt.Errorf("invalid ALERT_CLEANUP '%s' (available: off, delete, edit)", alertCleanup)
+	}
+	alertMentions := getEnvList("ALERT_MENTIONS", nil)
+	for _, name := range alertMentions {
+		if !strings.HasPrefix(name, "@") || len(name) < 2 {
+			return nil, fmt.Errorf("invalid ALERT_MENTIONS element '%s': expected an @username", name)
+		}
+	}
+	backupToken := os.Getenv("TELEGRAM_BACKUP_TOKEN")
+	if backupToken != "" && backupToken == token {
+		return nil, fmt.Errorf("TELEGRAM_BACKUP_TOKEN must belong to a different bot than TELEGRAM_TOKEN")
+	}
+	alertAfter := getEnvInt("ALERT_AFTER_FAILURES", 1)
+	if alertAfter < 1 {
+		return nil, fmt.Errorf("ALERT_AFTER_FAILURES must be at least 1, got %d", alertAfter)
+	}
+	baselineDrop, baselineDays := getEnvFloat("BASELINE_DROP", 0), getEnvInt("BASELINE_DAYS", 7)
+	if baselineDrop < 0 || baselineDrop >= 100 {
+		return nil, fmt.Errorf("BASELINE_DROP must be a percentage from 0 to 100, got %g", baselineDrop)
+	}
+	if baselineDays < 1 {
+		return nil, fmt.Errorf("BASELINE_DAYS must be at least 1, got %d", baselineDays)
+	}
+	trendDrop, trendDays := getEnvFloat("TREND_DROP", 0), getEnvInt("TREND_DAYS", 14)
+	if trendDrop < 0 || trendDrop >= 100 {
+		return nil, fmt.Errorf("TREND_DROP must be a percentage from 0 to 100, got %g", trendDrop)
+	}
+	if trendDays < 3 {
+		return nil, fmt.Errorf("TREND_DAYS must be at least 3, got %d", trendDays)
+	}
+	hoursDays := getEnvInt("HOURS_DAYS", 14)
+	if hoursDays < 1 {
+		return nil, fmt.Errorf("HOURS_DAYS must be at least 1, got %d", hoursDays)
+	}
+	slaCompliance, slaUptime := getEnvFloat("SLA_COMPLIANCE", 95), getEnvFloat("SLA_UPTIME", 0)
+	if slaCompliance <= 0 || slaCompliance > 100 {
+		return nil, fmt.Errorf("SLA_COMPLIANCE must be a percentage above 0 up to 100, got %g", slaCompliance)
+	}
+	if slaUptime < 0 || slaUptime >= 100 {
+		return nil, fmt.Errorf("SLA_UPTIME must be a percentage from 0 to 100, got %g", slaUptime)
+	}
+	slaBillingDay := getEnvInt("SLA_BILLING_DAY", 1)
+	if slaBillingDay < 1 || slaBillingDay > 28 {
+		return nil, fmt.Errorf("SLA_BILLING_DAY must be from 1 to 28, got %d", slaBillingDay)
+	}
+	escalateAfter, err := getEnvDurations("ESCALATE_AFTER")
+	if err != nil {
+		return nil, err
+	}
+	speedBuckets, err := getEnvFloats("SPEED_BUCKETS")
+	if err != nil {
+		return nil, err
+	}
+	escalationChatIDs, err := getEnvIDs("ESCALATION_CHAT_ID")
+	if err != nil {
+		return nil, err
+	}
+	if len(escalationChatIDs) > 0 && len(escalateAfter) == 0 {
+		return nil, fmt.Errorf("ESCALATION_CHAT_ID needs ESCALATE_AFTER")
+	}
+	slackWebhook, slackToken, slackChannel := os.Getenv("SLACK_WEBHOOK_URL"), os.Getenv("SLACK_BOT_TOKEN"), os.Getenv("SLACK_CHANNEL")
+	if slackWebhook == "" && slackToken != "" && slackChannel == "" {
+		return nil, fmt.Errorf("SLACK_CHANNEL is required with SLACK_BOT_TOKEN")
+	}
+	smtpHost, smtpUser, smtpTo := os.Getenv("SMTP_HOST"), os.Getenv("SMTP_USER"), getEnvList("SMTP_TO", nil)
+	smtpFrom := getEnvString("SMTP_FROM", smtpUser)
+	if smtpHost != "" && len(smtpTo) == 0 {
+		return nil, fmt.Errorf("SMTP_TO is required with SMTP_HOST")
+	}
+	if smtpHost != "" && smtpFrom == "" {
+		return nil, fmt.Errorf("SMTP_FROM is required with SMTP_HOST when SMTP_USER is empty")
+	}
+	pushoverToken, pushoverUser := os.Getenv("PUSHOVER_TOKEN"), os.Getenv("PUSHOVER_USER")
+	if (pushoverToken == "") != (pushoverUser == "") {
+		return nil, fmt.Errorf("PUSHOVER_TOKEN and PUSHOVER_USER must be set together")
+	}
+	// Warnings, reports and notices don't need to make a sound
+	pushoverPriority := map[string]int{"warning": -1, "report": -1, "info": -1}
+	custom, err := getEnvClassValues("PUSHOVER_PRIORITY", "priority")
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(pushoverPriority, custom)
+	pushoverCritical := getEnvInt("PUSHOVER_CRITICAL_PRIORITY", 2)
+	for class, p := range pushoverPriority {
+		if p < -2 || p > 2 {
+			return nil, fmt.Errorf("invalid PUSHOVER_PRIORITY for %s: %d (must be between -2 and 2)", class, p)
+		}
+	}
+	if pushoverCritical < -2 || pushoverCritical > 2 {
+		return nil, fmt.Errorf("PUSHOVER_CRITICAL_PRIORITY must be between -2 and 2, got %d", pushoverCritical)
+	}
+	pushoverRetry := getEnvDuration("PUSHOVER_RETRY", time.Minute)
+	pushoverExpire := getEnvDuration("PUSHOVER_EXPIRE", time.Hour)
+	if pushoverRetry < 30*time.Second {
+		return nil, fmt.Errorf("PUSHOVER_RETRY must be at least 30s, got %s", pushoverRetry)
+	}
+	if pushoverExpire < pushoverRetry || pushoverExpire > 3*time.Hour {
+		return nil, fmt.Errorf("PUSHOVER_EXPIRE must be between PUSHOVER_RETRY and 3h, got %s", pushoverExpire)
+	}
+	notifyRetries := getEnvInt("NOTIFY_RETRIES", 3)
+	if notifyRetries < 0 {
+		return nil, fmt.Errorf("NOTIFY_RETRIES must not be negative, got %d", notifyRetries)
+	}
+	notifiers, err := selectNotifiers(map[string]bool{
+		"telegram": true,
+		"slack":    slackWebhook != "" || slackToken != "",
+		"discord":  os.Getenv("DISCORD_WEBHOOK_URL") != "",
+		"email":    smtpHost != "",
+		"pushover": pushoverToken != "",
+	})
+	if err != nil {
+		return nil, err
+	}
+	outageAfter := getEnvInt("OUTAGE_AFTER_FAILURES", 2)
+	if outageAfter < 0 {
+		return nil, fmt.Errorf("OUTAGE_AFTER_FAILURES must not be negative, got %d", outageAfter)
+	}
+	tlsCert, tlsKey := os.Getenv("HTTP_TLS_CERT"), os.Getenv("HTTP_TLS_KEY")
+	if (tlsCert == "") != (tlsKey == "") {
+		return nil, fmt.Errorf("HTTP_TLS_CERT and HTTP_TLS_KEY must be set together")
+	}
+
 	cfg := &Config{
-		TelegramToken:     token,
-		ChatIDs:           chatIDs,
-		DownloadThreshold: getEnvFloat("DOWNLOAD_THRESHOLD", 80.0),
-		UploadThreshold:   getEnvFloat("UPLOAD_THRESHOLD", 100.0),
-		CheckInterval:     getEnvDuration("CHECK_INTERVAL_MIN", 30*time.Minute),
-		DailyReportHour:   getEnvInt("DAILY_REPORT_HOUR", 8),
-		TimeZone:          getEnvString("TZ", "Europe/Kyiv"),
-		LogLevel:          getEnvString("LOG_LEVEL", "info"),
+		TelegramToken:           token,
+		TelegramBackupToken:     backupToken,
+		TelegramQueuePath:       os.Getenv("TELEGRAM_QUEUE_PATH"),
+		SettingsPath:            os.Getenv("SETTINGS_PATH"),
+		AuditLogPath:            os.Getenv("AUDIT_LOG_PATH"),
+		AlertHistoryPath:        os.Getenv("ALERT_HISTORY_PATH"),
+		TelegramProxy:           os.Getenv("TELEGRAM_PROXY"),
+		TelegramWebhookURL:      webhookURL,
+		TelegramWebhookSecret:   webhookSecret,
+		HTTPTLSCert:             tlsCert,
+		HTTPTLSKey:              tlsKey,
+		ChatIDs:                 chatIDs,
+		ChatUsernames:           chatUsernames,
+		AllowedUserIDs:          allowedUserIDs,
+		AdminUserIDs:            adminUserIDs,
+		DownloadThreshold:       getEnvFloat("DOWNLOAD_THRESHOLD", 80.0),
+		UploadThreshold:         getEnvFloat("UPLOAD_THRESHOLD", 100.0),
+		JitterThreshold:         getEnvFloat("JITTER_THRESHOLD", 0),
+		CheckInterval:           getEnvDuration("CHECK_INTERVAL_MIN", 30*time.Minute),
+		DailyReportHour:         getEnvInt("DAILY_REPORT_HOUR", 8),
+		ReportChart:             os.Getenv("REPORT_CHART") != "false",
+		ReportPin:               os.Getenv("REPORT_PIN") == "true",
+		WeeklyReport:            os.Getenv("WEEKLY_REPORT") == "true",
+		MonthlyReport:           os.Getenv("MONTHLY_REPORT") == "true",
+		AlertCleanup:            alertCleanup,
+		AlertTTL:                getEnvDuration("ALERT_TTL", 0),
+		AlertMentions:           alertMentions,
+		AlertAfterFailures:      alertAfter,
+		OutageAfterFailures:     outageAfter,
+		NetworkChangeAlerts:     os.Getenv("NETWORK_CHANGE_ALERTS") == "true",
+		EscalateAfter:           escalateAfter,
+		EscalationChatIDs:       escalationChatIDs,
+		BaselineDrop:            baselineDrop,
+		BaselineDays:            baselineDays,
+		TrendDrop:               trendDrop,
+		TrendDays:               trendDays,
+		HoursDays:               hoursDays,
+		SpeedBuckets:            speedBuckets,
+		SLADownload:             getEnvFloat("SLA_DOWNLOAD", 0),
+		SLAUpload:               getEnvFloat("SLA_UPLOAD", 0),
+		SLACompliance:           slaCompliance,
+		SLAUptime:               slaUptime,
+		SLABillingDay:           slaBillingDay,
+		PlanDownload:            getEnvFloat("PLAN_DOWNLOAD", 0),
+		PlanUpload:              getEnvFloat("PLAN_UPLOAD", 0),
+		QuietHoursStart:         quietStart,
+		QuietHoursEnd:           quietEnd,
+		QuietHoursDigest:        os.Getenv("QUIET_HOURS_DIGEST") != "false",
+		MaintenanceWindows:      maintenanceWindows,
+		ThresholdSchedule:       thresholdSchedule,
+		SilentNotifications:     silentNotifications,
+		TelegramTopics:          telegramTopics,
+		TelegramParseMode:       parseMode,
+		TimeZone:                getEnvString("TZ", "Europe/Kyiv"),
+		BotLang:                 getEnvString("BOT_LANG", "en"),
+		TemplatesDir:            os.Getenv("TEMPLATES_DIR"),
+		AlertRules:              os.Getenv("ALERT_RULES"),
+		LogLevel:                getEnvString("LOG_LEVEL", "info"),
+		SpeedtestEngines:        getEnvList("SPEEDTEST_ENGINE", []string{"ookla"}),
+		SpeedtestServerID:       os.Getenv("SPEEDTEST_SERVER_ID"),
+		SpeedtestServerCacheTTL: getEnvDuration("SPEEDTEST_SERVER_CACHE_TTL", 6*time.Hour),
+		IPFamilies:              getEnvList("IP_FAMILIES", nil),
+		IPFamilyMaxDiff:         getEnvFloat("IP_FAMILY_MAX_DIFF", 50),
+		BindInterfaces:          getEnvList("BIND_INTERFACES", nil),
+		SpeedtestProxy:          os.Getenv("SPEEDTEST_PROXY"),
+		DataBudget:              getEnvBytes("DATA_BUDGET", 0),
+		LiteCheckURL:            getEnvString("LITE_CHECK_URL", "https://speed.cloudflare.com/__down?bytes=1000000"),
+		FullTestEvery:           getEnvInt("FULL_TEST_EVERY", 1),
+		LitePingThreshold:       getEnvFloat("LITE_PING_THRESHOLD", 100),
+		LiteDownloadThreshold:   getEnvFloat("LITE_DOWNLOAD_THRESHOLD", 5),
+		LibreSpeedURL:           os.Getenv("LIBRESPEED_URL"),
+		OoklaCLIPath:            getEnvString("OOKLA_CLI_PATH", "speedtest"),
+		Iperf3Path:              getEnvString("IPERF3_PATH", "iperf3"),
+		Iperf3Server:            os.Getenv("IPERF3_SERVER"),
+		Iperf3Mode:              getEnvString("IPERF3_MODE", "tcp"),
+		Iperf3Bandwidth:         os.Getenv("IPERF3_BANDWIDTH"),
+		Iperf3Duration:          getEnvDuration("IPERF3_DURATION", 10*time.Second),
+
+		HTTPProbeDownloadURL: os.Getenv("HTTP_PROBE_DOWNLOAD_URL"),
+		HTTPProbeUploadURL:   os.Getenv("HTTP_PROBE_UPLOAD_URL"),
+		HTTPProbeUploadSize:  int64(getEnvInt("HTTP_PROBE_UPLOAD_SIZE", 10_000_000)),
+		StorageBackend:       getEnvString("STORAGE_BACKEND", "memory"),
+		StoragePath:          getEnvString("STORAGE_PATH", "tetra.db"),
+		Retention:            getEnvDuration("RETENTION", 0),
+		InfluxURL:            os.Getenv("INFLUX_URL"),
+		InfluxVersion:        getEnvInt("INFLUX_VERSION", 2),
+		InfluxToken:          os.Getenv("INFLUX_TOKEN"),
+		InfluxOrg:            os.Getenv("INFLUX_ORG"),
+		InfluxBucket:         os.Getenv("INFLUX_BUCKET"),
+		InfluxDatabase:       os.Getenv("INFLUX_DB"),
+		InfluxUser:           os.Getenv("INFLUX_USER"),
+		InfluxPassword:       os.Getenv("INFLUX_PASSWORD"),
+		InfluxMeasurement:    getEnvString("INFLUX_MEASUREMENT", "speedtest"),
+
+		RemoteWriteURL:      os.Getenv("REMOTE_WRITE_URL"),
+		RemoteWriteUser:     os.Getenv("REMOTE_WRITE_USER"),
+		RemoteWritePassword: os.Getenv("REMOTE_WRITE_PASSWORD"),
+		RemoteWriteToken:    os.Getenv("REMOTE_WRITE_TOKEN"),
+		RemoteWriteJob:      getEnvString("REMOTE_WRITE_JOB", "tetra"),
+		RemoteWriteInstance: os.Getenv("REMOTE_WRITE_INSTANCE"),
+
+		ArchiveS3Endpoint:  os.Getenv("ARCHIVE_S3_ENDPOINT"),
+		ArchiveS3Region:    getEnvString("ARCHIVE_S3_REGION", "us-east-1"),
+		ArchiveS3Bucket:    os.Getenv("ARCHIVE_S3_BUCKET"),
+		ArchiveS3AccessKey: os.Getenv("ARCHIVE_S3_ACCESS_KEY"),
+		ArchiveS3SecretKey: os.Getenv("ARCHIVE_S3_SECRET_KEY"),
+		ArchiveS3Prefix:    getEnvString("ARCHIVE_S3_PREFIX", "tetra/"),
+		ArchiveFormat:      getEnvString("ARCHIVE_FORMAT", "jsonl"),
+		ArchiveInterval:    getEnvDuration("ARCHIVE_INTERVAL", 24*time.Hour),
+
+		CriticalDownloadThreshold: getEnvFloat("CRITICAL_DOWNLOAD_THRESHOLD", 0),
+		CriticalUploadThreshold:   getEnvFloat("CRITICAL_UPLOAD_THRESHOLD", 0),
+		CriticalJitterThreshold:   getEnvFloat("CRITICAL_JITTER_THRESHOLD", 0),
+
+		PingMonitorHost:            os.Getenv("PING_MONITOR_HOST"),
+		PingMonitorInterval:        getEnvDuration("PING_MONITOR_INTERVAL", 5*time.Second),
+		PingMonitorTimeout:         getEnvDuration("PING_MONITOR_TIMEOUT", 2*time.Second),
+		PingMonitorOutageThreshold: getEnvInt("PING_MONITOR_OUTAGE_THRESHOLD", 3),
+
+		DNSProbeHosts:         getEnvList("DNS_PROBE_HOSTS", nil),
+		DNSProbeResolvers:     getEnvList("DNS_PROBE_RESOLVERS", []string{"system"}),
+		DNSProbeTimeout:       getEnvDuration("DNS_PROBE_TIMEOUT", 5*time.Second),
+		DNSProbeSlowThreshold: getEnvDuration("DNS_PROBE_SLOW_THRESHOLD", 500*time.Millisecond),
+
+		TraceroutePath:   os.Getenv("TRACEROUTE_PATH"),
+		TracerouteTarget: getEnvString("TRACEROUTE_TARGET", "1.1.1.1"),
+
+		EndpointChecks:        getEnvList("ENDPOINT_CHECKS", nil),
+		EndpointCheckTimeout:  getEnvDuration("ENDPOINT_CHECK_TIMEOUT", 10*time.Second),
+		EndpointCheckInsecure: os.Getenv("ENDPOINT_CHECK_INSECURE") == "true",
+
+		Notifiers:       notifiers,
+		NotifyRetries:   notifyRetries,
+		SlackWebhookURL: slackWebhook,
+		SlackBotToken:   slackToken,
+		SlackChannel:    slackChannel,
+
+		DiscordWebhookURL: os.Getenv("DISCORD_WEBHOOK_URL"),
+
+		SMTPHost:     smtpHost,
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUser:     smtpUser,
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     smtpFrom,
+		SMTPTo:       smtpTo,
+
+		PushoverToken:    pushoverToken,
+		PushoverUser:     pushoverUser,
+		PushoverPriority: pushoverPriority,
+		PushoverCritical: pushoverCritical,
+		PushoverRetry:    pushoverRetry,
+		PushoverExpire:   pushoverExpire,
 	}
 
 	return cfg, nil
 }
 
+// selectNotifiers parses NOTIFIERS, which defaults to every configured backend.
+func selectNotifiers(configured map[string]bool) ([]string, error) {
+	names := getEnvList("NOTIFIERS", nil)
+	if names == nil {
+		for _, name := range NotifierNames {
+			if configured[name] {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+	for _, name := range names {
+		if !slices.Contains(NotifierNames, name) {
+			return nil, fmt.Errorf("invalid NOTIFIERS element '%s' (available: %s)", name, strings.Join(NotifierNames, ", "))
+		}
+		if !configured[name] {
+			return nil, fmt.Errorf("NOTIFIERS includes %s, which isn't configured", name)
+		}
+	}
+	return names, nil
+}
+
 func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 	val := os.Getenv(key)
 	if val == "" {
 		return defaultVal
 	}
+	if d, ok := parseDuration(val); ok {
+		return d
+	}
+	return defaultVal
+}
+
+func parseDuration(val string) (time.Duration, bool) {
 	// Try parsing as duration string (e.g. "1m", "30s")
 	d, err := time.ParseDuration(val)
 	if err == nil {
-		return d
+		return d, true
+	}
+	// Try parsing as days (e.g. "7d"), which time.ParseDuration doesn't support
+	if days, ok := strings.CutSuffix(val, "d"); ok {
+		f, err := strconv.ParseFloat(days, 64)
+		if err == nil {
+			return time.Duration(f * float64(24*time.Hour)), true
+		}
 	}
 	// Fallback: try parsing as simple integer (assumed minutes)
 	i, err := strconv.Atoi(val)
 	if err == nil {
-		return time.Duration(i) * time.Minute
+		return time.Duration(i) * time.Minute, true
 	}
-	return defaultVal
+	return 0, false
+}
+
+// getEnvDurations parses a comma-separated list of ascending positive durations, e.g. "2h,6h,1d".
+func getEnvDurations(key string) ([]time.Duration, error) {
+	var list []time.Duration
+	for _, item := range getEnvList(key, nil) {
+		d, ok := parseDuration(item)
+		if !ok || d <= 0 {
+			return nil, fmt.Errorf("invalid %s element '%s': expected a positive duration", key, item)
+		}
+		if n := len(list); n > 0 && d <= list[n-1] {
+			return nil, fmt.Errorf("invalid %s: durations must be ascending", key)
+		}
+		list = append(list, d)
+	}
+	return list, nil
+}
+
+// getEnvFloats parses a comma-separated list of ascending positive numbers, e.g. "50,100,200".
+func getEnvFloats(key string) ([]float64, error) {
+	var list []float64
+	for _, item := range getEnvList(key, nil) {
+		f, err := strconv.ParseFloat(item, 64)
+		if err != nil || f <= 0 {
+			return nil, fmt.Errorf("invalid %s element '%s': expected a positive number", key, item)
+		}
+		if n := len(list); n > 0 && f <= list[n-1] {
+			return nil, fmt.Errorf("invalid %s: numbers must be ascending", key)
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// getEnvBytes parses a size like "50GB", "500MB" or a plain byte count.
+// Units are decimal, the way ISPs count data caps.
+func getEnvBytes(key string, defaultVal uint64) uint64 {
+	val := strings.ToUpper(strings.TrimSpace(os.Getenv(key)))
+	if val == "" {
+		return defaultVal
+	}
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"B", 1}} {
+		if num, ok := strings.CutSuffix(val, unit.suffix); ok {
+			val, multiplier = strings.TrimSpace(num), unit.factor
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil || f < 0 {
+		return defaultVal
+	}
+	return uint64(f * multiplier)
 }
 
 func getEnvFloat(key string, defaultVal float64) float64 {
@@ -111,6 +730,73 @@ func getEnvInt(key string, defaultVal int) int {
 	return i
 }
 
+// getEnvList parses a comma-separated list, ignoring empty elements.
+func getEnvList(key string, defaultVal []string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return defaultVal
+	}
+	return list
+}
+
+// getEnvIDs parses a comma-separated list of Telegram IDs.
+func getEnvIDs(key string) ([]int64, error) {
+	var ids []int64
+	for _, idStr := range getEnvList(key, nil) {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s element '%s': %w", key, idStr, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// getEnvClassValues parses "class:value" pairs, e.g. "alert:12,report:34,default:5", where
+// value names the number in errors.
+func getEnvClassValues(key, value string) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, item := range getEnvList(key, nil) {
+		class, numStr, ok := strings.Cut(item, ":")
+		class = strings.TrimSpace(class)
+		if !ok || (class != "default" && !slices.Contains(MessageClasses, class)) {
+			return nil, fmt.Errorf("invalid %s element '%s': expected <class>:<%s> with class one of %s, default", key, item, value, strings.Join(MessageClasses, ", "))
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(numStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s element '%s': %w", key, item, err)
+		}
+		values[class] = n
+	}
+	return values, nil
+}
+
+// getEnvClockRange parses a time of day range like "23:00-07:00". Unset returns two zero values.
+func getEnvClockRange(key string) (time.Duration, time.Duration, error) {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return 0, 0, nil
+	}
+	startStr, endStr, ok := strings.Cut(val, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid %s '%s': expected HH:MM-HH:MM", key, val)
+	}
+	var bounds [2]time.Duration
+	for i, s := range []string{startStr, endStr} {
+		t, err := time.Parse("15:04", strings.TrimSpace(s))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid %s '%s': %w", key, val, err)
+		}
+		bounds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return bounds[0], bounds[1], nil
+}
+
 func getEnvString(key string, defaultVal string) string {
 	val := os.Getenv(key)
 	if val == "" {
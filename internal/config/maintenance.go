@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	recurringWindowPattern = regexp.MustCompile(`^(?:([A-Za-z]{3})\s+)?(\d{1,2}:\d{2})\s*-\s*(\d{1,2}:\d{2})$`)
+	oneOffWindowPattern    = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\s+(\d{1,2}:\d{2})\s*-\s*(?:(\d{4}-\d{2}-\d{2})\s+)?(\d{1,2}:\d{2})$`)
+)
+
+// MaintenanceWindow is a period during which tests still run and are recorded, but don't alert.
+// It is either recurring, every day or every week, or a one-off period.
+type MaintenanceWindow struct {
+	Text string // as configured
+
+	// Recurring windows; an End before Start spans midnight
+	Weekly     bool
+	Weekday    time.Weekday // day the window starts on, if Weekly
+	Start, End time.Duration
+
+	// One-off windows, wall clock time stored as UTC
+	From, To time.Time
+}
+
+// Active reports whether t, in the configured time zone, falls into the window.
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	if !w.From.IsZero() {
+		wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		return !wall.Before(w.From) && wall.Before(w.To)
+	}
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	on := func(day time.Weekday) bool { return !w.Weekly || t.Weekday() == day }
+	if w.Start < w.End {
+		return on(w.Weekday) && clock >= w.Start && clock < w.End
+	}
+	return (on(w.Weekday) && clock >= w.Start) || (on((w.Weekday+1)%7) && clock < w.End)
+}
+
+// InMaintenance reports whether t, in the configured time zone, falls into a maintenance window.
+func (c *Config) InMaintenance(t time.Time) bool {
+	for _, w := range c.MaintenanceWindows {
+		if w.Active(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMaintenanceWindow parses "Sun 02:00-04:00" (weekly), "02:00-04:00" (daily) or
+// "2024-06-01 22:00-2024-06-02 02:00" (one-off, the end date defaults to the start date).
+func parseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	w := MaintenanceWindow{Text: s}
+	if m := oneOffWindowPattern.FindStringSubmatch(s); m != nil {
+		endDate := m[3]
+		if endDate == "" {
+			endDate = m[1]
+		}
+		var err error
+		if w.From, err = time.Parse("2006-01-02 15:04", m[1]+" "+m[2]); err != nil {
+			return w, err
+		}
+		if w.To, err = time.Parse("2006-01-02 15:04", endDate+" "+m[4]); err != nil {
+			return w, err
+		}
+		if !w.To.After(w.From) {
+			return w, fmt.Errorf("window ends before it starts")
+		}
+		return w, nil
+	}
+
+	m := recurringWindowPattern.FindStringSubmatch(s)
+	if m == nil {
+		return w, fmt.Errorf("expected e.g. 'Sun 02:00-04:00', '02:00-04:00' or '2024-06-01 22:00-23:30'")
+	}
+	if m[1] != "" {
+		day, ok := weekdays[strings.ToLower(m[1])]
+		if !ok {
+			return w, fmt.Errorf("unknown weekday '%s'", m[1])
+		}
+		w.Weekly, w.Weekday = true, day
+	}
+	for i, clock := range []string{m[2], m[3]} {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return w, err
+		}
+		d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if i == 0 {
+			w.Start = d
+		} else {
+			w.End = d
+		}
+	}
+	if w.Start == w.End {
+		return w, fmt.Errorf("window is empty")
+	}
+	return w, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		window string
+		t      string
+		want   bool
+	}{
+		{"Sun 02:00-04:00", "2024-06-02 03:00", true}, // a Sunday
+		{"Sun 02:00-04:00", "2024-06-03 03:00", false},
+		{"Sun 02:00-04:00", "2024-06-02 04:00", false},
+		{"sat 23:00-01:00", "2024-06-02 00:30", true}, // past midnight into Sunday
+		{"sat 23:00-01:00", "2024-06-03 00:30", false},
+		{"03:00-03:30", "2024-06-05 03:10", true},
+		{"2024-06-01 22:00-2024-06-02 02:00", "2024-06-02 01:00", true},
+		{"2024-06-01 22:00-23:30", "2024-06-01 23:45", false},
+	}
+	for _, tt := range tests {
+		w, err := parseMaintenanceWindow(tt.window)
+		if err != nil {
+			t.Fatalf("parseMaintenanceWindow(%q): %v", tt.window, err)
+		}
+		if got := w.Active(at(tt.t)); got != tt.want {
+			t.Errorf("%q.Active(%s) = %v, want %v", tt.window, tt.t, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"Sunday 02:00-04:00", "02:00-02:00", "2024-06-02 04:00-2024-06-01 02:00", "soon"} {
+		if _, err := parseMaintenanceWindow(bad); err == nil {
+			t.Errorf("parseMaintenanceWindow(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ThresholdWindow replaces the download and upload thresholds while its window is active,
+// e.g. stricter during work hours or relaxed while night backups saturate the line.
+type ThresholdWindow struct {
+	MaintenanceWindow
+	Download, Upload float64 // Mbps
+}
+
+// ThresholdSchedule lists the THRESHOLD_SCHEDULE windows; the first active one applies.
+type ThresholdSchedule []ThresholdWindow
+
+// Apply returns v with the thresholds of the window active at t, in the configured time zone.
+// Outside every window the chat's own thresholds apply.
+func (s ThresholdSchedule) Apply(t time.Time, v ChatValues) ChatValues {
+	for _, w := range s {
+		if w.Active(t) {
+			v.DownloadThreshold, v.UploadThreshold = w.Download, w.Upload
+			return v
+		}
+	}
+	return v
+}
+
+// parseThresholdWindow parses a maintenance window followed by the thresholds in Mbps,
+// e.g. "09:00-18:00 150/50" or "Sun 01:00-06:00 20/5".
+func parseThresholdWindow(s string) (ThresholdWindow, error) {
+	window, thresholds, ok := cutLast(strings.TrimSpace(s), " ")
+	if !ok {
+		return ThresholdWindow{}, fmt.Errorf("expected e.g. '09:00-18:00 150/50'")
+	}
+	dl, ul, ok := strings.Cut(thresholds, "/")
+	if !ok {
+		return ThresholdWindow{}, fmt.Errorf("expected thresholds as download/upload, e.g. 150/50")
+	}
+	var w ThresholdWindow
+	var err error
+	if w.Download, err = strconv.ParseFloat(dl, 64); err != nil || w.Download < 0 {
+		return w, fmt.Errorf("invalid download threshold '%s'", dl)
+	}
+	if w.Upload, err = strconv.ParseFloat(ul, 64); err != nil || w.Upload < 0 {
+		return w, fmt.Errorf("invalid upload threshold '%s'", ul)
+	}
+	w.MaintenanceWindow, err = parseMaintenanceWindow(strings.TrimSpace(window))
+	return w, err
+}
+
+// cutLast is strings.Cut around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
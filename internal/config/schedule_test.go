@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThresholdSchedule(t *testing.T) {
+	var s ThresholdSchedule
+	for _, item := range []string{"09:00-18:00 150/50", "Sun 22:00-06:00 20/5.5"} {
+		w, err := parseThresholdWindow(item)
+		if err != nil {
+			t.Fatalf("parseThresholdWindow(%q): %v", item, err)
+		}
+		s = append(s, w)
+	}
+	own := ChatValues{DownloadThreshold: 80, UploadThreshold: 20}
+
+	tests := []struct {
+		t        time.Time
+		download float64
+		upload   float64
+	}{
+		{time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC), 150, 50}, // Monday work hours
+		{time.Date(2024, 6, 3, 20, 0, 0, 0, time.UTC), 80, 20},  // Monday evening
+		{time.Date(2024, 6, 3, 3, 0, 0, 0, time.UTC), 20, 5.5},  // Sunday night, past midnight
+	}
+	for _, tt := range tests {
+		if v := s.Apply(tt.t, own); v.DownloadThreshold != tt.download || v.UploadThreshold != tt.upload {
+			t.Errorf("Apply(%s) = %.1f/%.1f, want %.1f/%.1f", tt.t, v.DownloadThreshold, v.UploadThreshold, tt.download, tt.upload)
+		}
+	}
+
+	for _, bad := range []string{"09:00-18:00", "09:00-18:00 150", "09:00-18:00 fast/50", "09:00-09:00 10/10", "-5/5"} {
+		if _, err := parseThresholdWindow(bad); err == nil {
+			t.Errorf("parseThresholdWindow(%q) accepted", bad)
+		}
+	}
+}
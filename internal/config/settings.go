@@ -0,0 +1,382 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Values are the settings that can be changed at runtime from the bot.
+type Values struct {
+	DownloadThreshold float64       `json:"download_threshold"`
+	UploadThreshold   float64       `json:"upload_threshold"`
+	CheckInterval     time.Duration `json:"check_interval"`
+	DailyReportHour   int           `json:"daily_report_hour"`
+	Paused            bool          `json:"paused,omitempty"`     // scheduled tests are skipped while set
+	ServerID          string        `json:"server_id,omitempty"`  // pinned speedtest.net server, see PinnedServer
+	BoundChat         int64         `json:"bound_chat,omitempty"` // chat bound with /start while CHAT_ID is empty
+}
+
+// ServerAuto is the ServerID of a choice for the closest server made in the bot. Unlike an empty
+// ServerID it is kept in the settings file, so SPEEDTEST_SERVER_ID doesn't override it on restart.
+const ServerAuto = "auto"
+
+// PinnedServer returns the speedtest.net server tests are pinned to, empty for the closest one.
+func (v Values) PinnedServer() string {
+	if v.ServerID == ServerAuto {
+		return ""
+	}
+	return v.ServerID
+}
+
+// Validate rejects values the scheduler or alerting can't work with.
+func (v Values) Validate() error {
+	if v.DownloadThreshold < 0 || v.UploadThreshold < 0 {
+		return errors.New("thresholds can't be negative")
+	}
+	if v.CheckInterval < time.Minute {
+		return errors.New("check interval must be at least 1m")
+	}
+	if v.DailyReportHour < 0 || v.DailyReportHour > 23 {
+		return errors.New("daily report hour must be between 0 and 23")
+	}
+	return nil
+}
+
+// Alert verbosity levels of a chat
+const (
+	VerbosityFull  = "full"  // alerts with diagnostics, plus notices
+	VerbosityShort = "short" // alerts and notices without traceroute reports
+	VerbosityOff   = "off"   // daily reports only
+)
+
+// ChatValues are the preferences each chat can set for itself. Those a chat never changed
+// follow the global Values.
+type ChatValues struct {
+	DownloadThreshold float64 `json:"download_threshold"`
+	UploadThreshold   float64 `json:"upload_threshold"`
+	DailyReportHour   int     `json:"daily_report_hour"`
+	Verbosity         string  `json:"verbosity"`
+	TextCharts        bool    `json:"text_charts,omitempty"` // sparklines in reports instead of chart images
+	// Alerts and notices aren't sent to the chat before this time, tests keep running
+	MutedUntil time.Time `json:"muted_until,omitzero"`
+}
+
+// Muted reports whether alerts to the chat are muted at now.
+func (v ChatValues) Muted(now time.Time) bool {
+	return now.Before(v.MutedUntil)
+}
+
+// Validate rejects values the scheduler or alerting can't work with.
+func (v ChatValues) Validate() error {
+	if v.DownloadThreshold < 0 || v.UploadThreshold < 0 {
+		return errors.New("thresholds can't be negative")
+	}
+	if v.DailyReportHour < 0 || v.DailyReportHour > 23 {
+		return errors.New("daily report hour must be between 0 and 23")
+	}
+	switch v.Verbosity {
+	case VerbosityFull, VerbosityShort, VerbosityOff:
+	default:
+		return fmt.Errorf("unknown verbosity '%s'", v.Verbosity)
+	}
+	return nil
+}
+
+// chatOverrides are the ChatValues a chat changed, as kept in the settings file.
+// Nil fields follow the global Values, so later changes to those still reach the chat.
+type chatOverrides struct {
+	DownloadThreshold *float64  `json:"download_threshold,omitempty"`
+	UploadThreshold   *float64  `json:"upload_threshold,omitempty"`
+	DailyReportHour   *int      `json:"daily_report_hour,omitempty"`
+	Verbosity         string    `json:"verbosity,omitempty"`
+	TextCharts        bool      `json:"text_charts,omitempty"`
+	MutedUntil        time.Time `json:"muted_until,omitzero"`
+}
+
+// apply returns the chat's preferences on top of the global values.
+func (o chatOverrides) apply(g Values) ChatValues {
+	v := ChatValues{
+		DownloadThreshold: g.DownloadThreshold,
+		UploadThreshold:   g.UploadThreshold,
+		DailyReportHour:   g.DailyReportHour,
+		Verbosity:         VerbosityFull,
+		TextCharts:        o.TextCharts,
+		MutedUntil:        o.MutedUntil,
+	}
+	if o.DownloadThreshold != nil {
+		v.DownloadThreshold = *o.DownloadThreshold
+	}
+	if o.UploadThreshold != nil {
+		v.UploadThreshold = *o.UploadThreshold
+	}
+	if o.DailyReportHour != nil {
+		v.DailyReportHour = *o.DailyReportHour
+	}
+	if o.Verbosity != "" {
+		v.Verbosity = o.Verbosity
+	}
+	return v
+}
+
+// override records the fields that differ between old and v.
+func (o chatOverrides) override(old, v ChatValues) chatOverrides {
+	if v.DownloadThreshold != old.DownloadThreshold {
+		o.DownloadThreshold = &v.DownloadThreshold
+	}
+	if v.UploadThreshold != old.UploadThreshold {
+		o.UploadThreshold = &v.UploadThreshold
+	}
+	if v.DailyReportHour != old.DailyReportHour {
+		o.DailyReportHour = &v.DailyReportHour
+	}
+	if v.Verbosity != old.Verbosity {
+		o.Verbosity = v.Verbosity
+	}
+	o.TextCharts, o.MutedUntil = v.TextCharts, v.MutedUntil
+	return o
+}
+
+// Personal notification choices of a user, see UserValues
+const (
+	NotifyAll     = "all"     // everything their chats get
+	NotifyOutages = "outages" // only critical alerts: lost connection and failed tests
+	NotifyReports = "reports" // only the daily report
+	NotifyOff     = "off"     // nothing
+)
+
+// NotifyOptions lists the accepted UserValues.Notify values.
+var NotifyOptions = []string{NotifyAll, NotifyOutages, NotifyReports, NotifyOff}
+
+// UserValues are the personal preferences of a user. Users who chose what to be notified of get
+// it in their private chat with the bot, and a private chat in CHAT_ID follows the choice too.
+type UserValues struct {
+	Notify string `json:"notify"`
+}
+
+// Subscribed reports whether the user receives messages in their private chat with the bot.
+func (u UserValues) Subscribed() bool {
+	return u.Notify != "" && u.Notify != NotifyOff
+}
+
+// Wants reports whether a message of the given class ("alert", "recovery", "report" or "info")
+// should reach the user. Users without a choice get everything.
+func (u UserValues) Wants(class string, critical bool) bool {
+	switch u.Notify {
+	case NotifyOutages:
+		return class == "alert" && critical
+	case NotifyReports:
+		return class == "report"
+	case NotifyOff:
+		return false
+	default:
+		return true
+	}
+}
+
+// Settings holds the runtime-adjustable values, initialized from the environment.
+// With SETTINGS_PATH set, changes are written there and take precedence over .env after a restart.
+type Settings struct {
+	mu      sync.RWMutex
+	path    string
+	values  Values
+	chats   map[int64]chatOverrides
+	users   map[int64]UserValues
+	changed chan struct{}
+}
+
+// settingsFile is the on-disk layout: the global values plus per-chat overrides.
+type settingsFile struct {
+	Values
+	Chats map[int64]chatOverrides `json:"chats,omitempty"`
+	Users map[int64]UserValues    `json:"users,omitempty"`
+}
+
+func NewSettings(cfg *Config) (*Settings, error) {
+	s := &Settings{
+		path: cfg.SettingsPath,
+		values: Values{
+			DownloadThreshold: cfg.DownloadThreshold,
+			UploadThreshold:   cfg.UploadThreshold,
+			CheckInterval:     cfg.CheckInterval,
+			DailyReportHour:   cfg.DailyReportHour,
+			ServerID:          cfg.SpeedtestServerID,
+		},
+		chats:   make(map[int64]chatOverrides),
+		users:   make(map[int64]UserValues),
+		changed: make(chan struct{}),
+	}
+	if s.path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	f := settingsFile{Values: s.values}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+	if err := f.Values.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings in %s: %w", s.path, err)
+	}
+	for id, c := range f.Chats {
+		if err := c.apply(f.Values).Validate(); err != nil {
+			return nil, fmt.Errorf("invalid settings for chat %d in %s: %w", id, s.path, err)
+		}
+		s.chats[id] = c
+	}
+	for id, u := range f.Users {
+		if !slices.Contains(NotifyOptions, u.Notify) {
+			return nil, fmt.Errorf("invalid notify preference '%s' for user %d in %s", u.Notify, id, s.path)
+		}
+		s.users[id] = u
+	}
+	s.values = f.Values
+	return s, nil
+}
+
+// Get returns a snapshot of the current values.
+func (s *Settings) Get() Values {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values
+}
+
+// Update applies fn to a copy of the current values and stores the result if it is valid.
+func (s *Settings) Update(fn func(*Values)) (Values, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.values
+	fn(&v)
+	if err := v.Validate(); err != nil {
+		return s.values, err
+	}
+	if v == s.values {
+		return v, nil
+	}
+	if err := s.persist(v, s.chats, s.users); err != nil {
+		return s.values, err
+	}
+	s.values = v
+	s.notify()
+	return v, nil
+}
+
+// ForChat returns the preferences of a chat, falling back to the global values.
+func (s *Settings) ForChat(chatID int64) ChatValues {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.forChat(chatID)
+}
+
+// forChat is ForChat for callers holding the lock.
+func (s *Settings) forChat(chatID int64) ChatValues {
+	return s.chats[chatID].apply(s.values)
+}
+
+// UpdateChat applies fn to a copy of the chat's preferences and stores the result if it is valid.
+// Only the preferences fn changed become the chat's own, the rest keep following the global values.
+func (s *Settings) UpdateChat(chatID int64, fn func(*ChatValues)) (ChatValues, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.forChat(chatID)
+	v := old
+	fn(&v)
+	if err := v.Validate(); err != nil {
+		return old, err
+	}
+	if v == old {
+		return v, nil
+	}
+	chats := maps.Clone(s.chats)
+	chats[chatID] = s.chats[chatID].override(old, v)
+	if err := s.persist(s.values, chats, s.users); err != nil {
+		return old, err
+	}
+	s.chats = chats
+	s.notify()
+	return v, nil
+}
+
+// ForUser returns the personal preferences of a user, the zero value if they never set any.
+func (s *Settings) ForUser(userID int64) UserValues {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.users[userID]
+}
+
+// SetUser stores the personal preferences of a user.
+func (s *Settings) SetUser(userID int64, u UserValues) error {
+	if !slices.Contains(NotifyOptions, u.Notify) {
+		return fmt.Errorf("unknown notify preference '%s'", u.Notify)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := maps.Clone(s.users)
+	users[userID] = u
+	if err := s.persist(s.values, s.chats, users); err != nil {
+		return err
+	}
+	s.users = users
+	s.notify()
+	return nil
+}
+
+// Subscribers returns the users who receive messages in their private chat with the bot.
+func (s *Settings) Subscribers() []int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []int64
+	for id, u := range s.users {
+		if u.Subscribed() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// notify wakes everyone waiting on Changed. Caller must hold the lock.
+func (s *Settings) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Changed returns a channel that is closed on the next successful Update.
+func (s *Settings) Changed() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.changed
+}
+
+// persist writes the values atomically. Caller must hold the lock.
+func (s *Settings) persist(v Values, chats map[int64]chatOverrides, users map[int64]UserValues) error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(settingsFile{Values: v, Chats: chats, Users: users}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace settings: %w", err)
+	}
+	return nil
+}
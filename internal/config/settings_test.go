@@ -0,0 +1,53 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSettingsKeepAutoServer(t *testing.T) {
+	cfg := &Config{SettingsPath: filepath.Join(t.TempDir(), "settings.json"), CheckInterval: time.Minute, SpeedtestServerID: "1234"}
+	s, err := NewSettings(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get().PinnedServer(); got != "1234" {
+		t.Fatalf("PinnedServer() = %q, want SPEEDTEST_SERVER_ID", got)
+	}
+	if _, err := s.Update(func(v *Values) { v.ServerID = ServerAuto }); err != nil {
+		t.Fatal(err)
+	}
+
+	// Choosing the closest server in the bot outlasts a restart with SPEEDTEST_SERVER_ID set
+	if s, err = NewSettings(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get().PinnedServer(); got != "" {
+		t.Errorf("PinnedServer() after restart = %q, want the closest server", got)
+	}
+}
+
+func TestSettingsChatOverrides(t *testing.T) {
+	cfg := &Config{SettingsPath: filepath.Join(t.TempDir(), "settings.json"), CheckInterval: time.Minute, DownloadThreshold: 100, UploadThreshold: 10}
+	s, err := NewSettings(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateChat(1, func(v *ChatValues) { v.UploadThreshold = 5 }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(func(v *Values) { v.DownloadThreshold, v.UploadThreshold = 200, 20 }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chat keeps its own upload threshold but follows the global download threshold, also after a restart
+	for range 2 {
+		if v := s.ForChat(1); v.DownloadThreshold != 200 || v.UploadThreshold != 5 || v.Verbosity != VerbosityFull {
+			t.Errorf("ForChat(1) = %+v, want download 200 and upload 5", v)
+		}
+		if s, err = NewSettings(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
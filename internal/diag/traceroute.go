@@ -0,0 +1,72 @@
+package diag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// maxReportLen keeps the hop report well inside Telegram's 4096 character message limit.
+const maxReportLen = 2500
+
+// Tracer runs the system traceroute (or mtr) binary to show where the path to a
+// test server breaks, as evidence for the ISP.
+type Tracer struct {
+	path     string
+	fallback string // target used when the result doesn't name its server
+	timeout  time.Duration
+}
+
+func NewTracer(path, fallback string) (*Tracer, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("traceroute binary not found: %w", err)
+	}
+	return &Tracer{path: resolved, fallback: fallback, timeout: 90 * time.Second}, nil
+}
+
+// Trace runs a trace to host (a host name or host:port, empty for the fallback target)
+// and returns the traced host with the raw hop report.
+func (t *Tracer) Trace(ctx context.Context, host string) (string, string, error) {
+	if host == "" {
+		host = t.fallback
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	var args []string
+	if strings.HasPrefix(filepath.Base(t.path), "mtr") {
+		args = []string{"--report", "--report-cycles", "5", "--no-dns", host}
+	} else {
+		args = []string{"-n", "-q", "1", "-w", "2", "-m", "30", host}
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.path, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil && out.Len() == 0 {
+		return host, "", fmt.Errorf("traceroute failed: %w", err)
+	}
+	return host, strings.TrimSpace(out.String()), nil
+}
+
+// Format renders a hop report for a Telegram HTML message.
+func Format(host, report string) string {
+	if len(report) > maxReportLen {
+		report = report[:maxReportLen] + "\n..."
+	}
+	return i18n.T("route.title", html.EscapeString(host)) + "\n<pre>" + html.EscapeString(report) + "</pre>"
+}
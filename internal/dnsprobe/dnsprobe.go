@@ -0,0 +1,105 @@
+package dnsprobe
+
+import (
+	"context"
+	"html"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// SystemResolver names the host's own resolver configuration in results.
+const SystemResolver = "system"
+
+// Prober times lookups of the configured hostnames against each configured resolver,
+// since many "internet is down" incidents are really DNS failures.
+type Prober struct {
+	hosts     []string
+	resolvers []string // host:port, or SystemResolver
+	timeout   time.Duration
+	slow      time.Duration
+}
+
+func New(cfg *config.Config) *Prober {
+	resolvers := make([]string, 0, len(cfg.DNSProbeResolvers))
+	for _, r := range cfg.DNSProbeResolvers {
+		if r != SystemResolver {
+			if _, _, err := net.SplitHostPort(r); err != nil {
+				r = net.JoinHostPort(r, "53")
+			}
+		}
+		resolvers = append(resolvers, r)
+	}
+	return &Prober{
+		hosts:     cfg.DNSProbeHosts,
+		resolvers: resolvers,
+		timeout:   cfg.DNSProbeTimeout,
+		slow:      cfg.DNSProbeSlowThreshold,
+	}
+}
+
+// Run looks up every host against every resolver sequentially.
+func (p *Prober) Run(ctx context.Context) []stats.DNSResult {
+	var results []stats.DNSResult
+	for _, resolver := range p.resolvers {
+		r := newResolver(resolver)
+		for _, host := range p.hosts {
+			results = append(results, p.lookup(ctx, r, resolver, host))
+		}
+	}
+	return results
+}
+
+func (p *Prober) lookup(ctx context.Context, r *net.Resolver, resolver, host string) stats.DNSResult {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	res := stats.DNSResult{Time: time.Now(), Host: host, Resolver: resolver}
+	start := time.Now()
+	_, err := r.LookupHost(ctx, host)
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Error = err
+	} else {
+		res.Slow = p.slow > 0 && res.Duration > p.slow
+	}
+	return res
+}
+
+// newResolver returns a resolver that sends every query to addr.
+func newResolver(addr string) *net.Resolver {
+	if addr == SystemResolver {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// Format renders results for a Telegram message. Unless verbose, only failed or
+// slow lookups are listed and an empty string means everything was fine.
+func Format(results []stats.DNSResult, verbose bool) string {
+	var sb strings.Builder
+	for _, r := range results {
+		switch {
+		case r.Error != nil:
+			sb.WriteString(i18n.T("dns.failed", html.EscapeString(r.Host), html.EscapeString(r.Resolver), html.EscapeString(r.Error.Error())) + "\n")
+		case r.Slow:
+			sb.WriteString(i18n.T("dns.slow", html.EscapeString(r.Host), html.EscapeString(r.Resolver), r.Duration.Milliseconds()) + "\n")
+		case verbose:
+			sb.WriteString(i18n.T("dns.ok", html.EscapeString(r.Host), html.EscapeString(r.Resolver), r.Duration.Milliseconds()) + "\n")
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return i18n.T("dns.title") + "\n" + strings.TrimSuffix(sb.String(), "\n")
+}
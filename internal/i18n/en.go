@@ -0,0 +1,323 @@
+package i18n
+
+var en = map[string]string{
+	// Access control
+	"auth.rejected":   "⛔ Sorry, you are not allowed to use this bot.",
+	"auth.admin_only": "⛔ Only admins can do that.",
+
+	// Keyboard
+	"button.test":     "🚀 Run test",
+	"button.last_24h": "📊 Last 24h",
+	"button.last_7d":  "📅 Last 7d",
+	"button.settings": "⚙️ Settings",
+	"button.ack":      "✅ Acknowledge",
+
+	// Commands
+	"start.welcome": "👋 <b>Hello!</b> I am Tetra, your internet connection monitor.\n\n" +
+		"I will periodically check your internet speed and notify you if it drops below the configured thresholds.\n" +
+		"Use /help to see available commands.",
+	"start.prompt": "What would you like to do?",
+	"bind.done":    "🔗 <b>This chat is now set up.</b> Alerts and daily reports will be sent here.",
+	"bind.code":    "No chat is set up yet. Send /start with the setup code from the bot's log to get alerts here.",
+	"help": "📋 <b>Available Commands:</b>\n" +
+		"/test - Run an immediate speed test, /test verbose also attaches the raw results as JSON\n" +
+		"/stats - Get statistics for the last 24h, or /stats 7d, /stats 2024-05-01 2024-05-07\n" +
+		"/last - Show the latest result without running a test\n" +
+		"/alerts - List recent alerts and how long they lasted, e.g. /alerts 30\n" +
+		"/report - Send the daily report now\n" +
+		"/compare - Compare the last 24h with the day before, or /compare week\n" +
+		"/week, /month - Summarize the last 7 or 30 days with percentiles and worst days\n" +
+		"/hours - Rank the hours of the day by speed, e.g. /hours 30d\n" +
+		"/graph - Chart download, upload, ping or jitter, e.g. /graph download 7d\n" +
+		"/export - Download all stored results as CSV\n" +
+		"/settings - View and adjust thresholds, interval and report hour\n" +
+		"/setinterval - Change the check interval, e.g. /setinterval 15m\n" +
+		"/server - List nearby speedtest servers and pin one\n" +
+		"/status - Show uptime, last and next test and queued messages\n" +
+		"/pause, /resume - Stop or restart scheduled tests\n" +
+		"/ack - Acknowledge the latest alert and hold back repeats\n" +
+		"/notify - Choose what you personally get in a private chat, e.g. /notify outages\n" +
+		"/audit - Show who ran which commands recently\n" +
+		"/mute 3h, /unmute - Silence alerts in this chat for a while\n" +
+		"/help - Show this help message\n" +
+		"/start - Welcome message",
+	"ack.by":              "\n\n✅ <i>Acknowledged by %s at %s</i>",
+	"ack.done":            "✅ <b>Acknowledged.</b> Repeat alerts are held back until a test passes again.",
+	"ack.untracked":       "This alert can no longer be acknowledged, the bot has lost track of it.",
+	"ack.none":            "No recent alert to acknowledge in this chat.",
+	"alert.resolved":      "✅ <b>Resolved</b> %s\n\n<s>%s</s>",
+	"alert.expired":       "🕒 <b>Outdated</b>\n\n<s>%s</s>",
+	"test.starting":       "🚀 <b>Starting manual speed test...</b> Please wait.",
+	"test.raw_caption":    "🧾 Raw results: servers, latencies, byte counts and engine details",
+	"test.running":        "🚀 <b>Speed test running...</b>\n%s",
+	"test.phase.start":    "⏳ %s: starting",
+	"test.phase.ping":     "📶 %s: measuring ping",
+	"test.phase.download": "⬇️ %s: downloading",
+	"test.phase.upload":   "⬆️ %s: uploading",
+	"export.failed":       "⚠️ <b>Export failed.</b> Check the logs for details.",
+	"export.caption":      "📄 Speed test results export",
+
+	// Status
+	"status.title":       "🩺 <b>Status</b>\n",
+	"status.version":     "🏷 Version: %s\n",
+	"status.uptime":      "⏳ Uptime: %s\n",
+	"status.last":        "✅ Last successful test: %s (%s ago)\n",
+	"status.last_none":   "✅ Last successful test: none since start\n",
+	"status.next":        "⏭ Next scheduled test: %s\n",
+	"status.next_paused": "⏭ Next scheduled test: paused\n",
+	"status.queue":       "📨 Queued messages: %d\n",
+	"status.streak":      "❌ Failing: %d tests in a row since %s\n",
+	"status.longest":     "🔁 Longest failure streak in 24h: %d tests / %s\n",
+	"status.failover":    "🔁 Sending through the backup bot since %s\n",
+	"status.notifier":    "⚠️ %s notifications failing since %s (%d dropped): %s\n",
+	"status.thresholds":  "🎯 Thresholds: ▼%.0f ▲%.0f Mbps",
+
+	// Stats
+	"stats.usage": "📊 <b>Usage:</b>\n" +
+		"/stats - last 24h\n" +
+		"/stats 7d, /stats 12h - a period ending now\n" +
+		"/stats 2024-05-01 - one day\n" +
+		"/stats 2024-05-01 2024-05-07 - a range of days",
+
+	// Last result
+	"last.title":    "🕒 <b>Last result</b> (%s, %s ago)",
+	"last.lite":     " (lite check)",
+	"last.alert":    "🚨 This result triggered an alert.",
+	"last.no_alert": "✅ No alert was triggered.",
+	"last.none":     "🕒 No results stored yet. Use /test to run one.",
+	"last.failed":   "⚠️ <b>Failed to read the last result.</b> Check the logs for details.",
+
+	// Settings
+	"settings.save_failed":   "⚠️ <b>Failed to save the setting.</b> Check the logs for details.",
+	"settings.title":         "⚙️ <b>Settings</b>\n",
+	"settings.download":      "⬇️ Download threshold: %.0f Mbps\n",
+	"settings.upload":        "⬆️ Upload threshold: %.0f Mbps\n",
+	"settings.report":        "📊 Daily report: %02d:00 %s\n",
+	"settings.alerts":        "🔔 Alerts: %s\n",
+	"settings.interval":      "⏱ Check interval: %s\n",
+	"settings.paused":        "⏸ Monitoring paused, /resume to restart scheduled tests\n",
+	"settings.engines":       "🔧 Engines: %s",
+	"settings.jitter":        "\n〰️ Jitter threshold: %.0f ms",
+	"settings.per_chat":      "\n\n<i>Thresholds, report hour and alerts apply to this chat only; the check interval is shared.</i>",
+	"settings.switch_alerts": "🔔 Switch alerts to %s",
+	"verbosity.full":         "full",
+	"verbosity.short":        "short",
+	"verbosity.off":          "off",
+	"settings.charts":        "📈 Report charts: %s\n",
+	"settings.switch_charts": "📈 Switch charts to %s",
+	"charts.image":           "images",
+	"charts.text":            "text",
+	"pause.paused":           "⏸ <b>Monitoring paused.</b> Scheduled tests are skipped until /resume; /test still works.",
+	"pause.resumed":          "▶️ <b>Monitoring resumed.</b>",
+	"settings.muted":         "🔕 Alerts muted until %s\n",
+	"alerts.title":           "🚨 <b>Last %d alerts</b>\n",
+	"alerts.entry":           "\n%s %s, %s",
+	"alerts.download":        "▼ %.1f Mbps (below %.0f)",
+	"alerts.upload":          "▲ %.1f Mbps (below %.0f)",
+	"alerts.jitter":          "〰️ jitter %.0f ms (above %.0f)",
+	"alerts.baseline":        "📉 ▼ %.1f Mbps (usually %.0f)",
+	"alerts.outage":          "🔴 internet down",
+	"alerts.rule":            "📏 rule %s",
+	"alerts.lasted":          "lasted %s",
+	"alerts.ongoing":         "ongoing for %s",
+	"alerts.empty":           "No alerts recorded yet.",
+	"alerts.usage":           "Usage: /alerts or /alerts 30",
+	"audit.title":            "📜 <b>Last %d commands</b>\n",
+	"audit.entry":            "\n%s %s: <code>%s</code>",
+	"audit.entry_denied":     "\n%s %s: <code>%s</code> ⛔",
+	"audit.empty":            "No commands recorded yet.",
+	"audit.usage":            "Usage: /audit or /audit 50",
+	"notify.current":         "🔔 You get: <b>%s</b>\n\n",
+	"notify.set":             "🔔 From now on you get <b>%s</b> in your private chat with the bot.",
+	"notify.start_private":   "\nIf you haven't yet, open a private chat with the bot and press Start, bots can't message you first.",
+	"notify.usage":           "Usage: /notify all, /notify outages (lost connection and failed tests only), /notify reports (daily report only) or /notify off",
+	"notify.all":             "all notifications",
+	"notify.outages":         "outage alerts only",
+	"notify.reports":         "the daily report only",
+	"notify.off":             "nothing",
+	"mute.usage":             "Usage: /mute 3h (or 30m, 1d), /mute off or /unmute",
+	"mute.set":               "🔕 <b>Alerts muted until %s.</b> Tests keep running and results are recorded; /unmute to end it early.",
+	"mute.no_alerts":         "\n<i>Note that alerts aren't sent to this chat anyway.</i>",
+	"mute.ended":             "🔔 <b>Alerts unmuted.</b>",
+	"mute.expired":           "🔔 <b>Mute ended,</b> alerts are back on.",
+	"interval.usage":         "Usage: /setinterval 15m (at least 1m, e.g. 90s, 2h, 1d)",
+	"interval.invalid":       "⚠️ <b>Interval not changed:</b> %s",
+	"interval.set":           "⏱ <b>Check interval set to %s.</b> The next scheduled test is one interval from now.",
+	"interval.not_saved":     "\n<i>SETTINGS_PATH isn't set, so the change is lost on restart.</i>",
+
+	// Server selection
+	"servers.failed":         "⚠️ <b>Failed to fetch the server list.</b> Check the logs for details.",
+	"servers.title":          "🛰 <b>Nearby speedtest servers</b>\n",
+	"servers.line":           "\n<code>%s</code> %s, %s (%s) — %.0f km, %s%s",
+	"servers.latency":        "%dms",
+	"servers.timeout":        "timeout",
+	"servers.current_auto":   "\n\nCurrently the closest server is picked for every test.",
+	"servers.current_pinned": "\n\nPinned server: <code>%s</code>",
+	"servers.button_auto":    "🧭 Closest server (auto)",
+	"servers.pinned":         "📌 Tests will use server <code>%s</code>.",
+	"servers.auto":           "🧭 Tests will use the closest server.",
+
+	// Test results and alerts
+	"result.failed":         "⚠️ <b>Test Failed:</b> %v",
+	"result.speed":          "⬇️ <b>Download:</b> %.2f Mbps\n⬆️ <b>Upload:</b> %.2f Mbps\n📶 <b>Ping:</b> %d ms",
+	"result.jitter":         "\n〰️ <b>Jitter:</b> %d ms",
+	"result.bufferbloat":    "\n🎈 <b>Bufferbloat:</b> %s (+%d ms under load)",
+	"result.share":          "\n🔗 <a href=\"%s\">Speedtest result</a>",
+	"outcome.manual":        "✅ <b>Manual Test Result:</b>\n%s",
+	"outcome.alert":         "🚨 <b>Internet Quality Alert!</b>\n%s",
+	"outcome.warning":       "⚠️ <b>Internet Quality Warning</b>\n%s",
+	"outcome.jitter_alert":  "〰️ <b>High Jitter Alert!</b>\nSpeed is fine, but latency is unstable: calls, video and games may stutter.\n%s",
+	"outcome.jitter_warn":   "〰️ <b>High Jitter Warning</b>\nSpeed is fine, but latency is unstable: calls, video and games may stutter.\n%s",
+	"outcome.jitter_high":   "\n⚠️ Jitter %d ms is above the %d ms threshold",
+	"outcome.confirmed":     "\n\n🔁 Confirmed by %d consecutive tests",
+	"budget.reached":        "💾 <b>Data budget reached:</b> %s of %s used this month. Switching to lite checks until next month.",
+	"usage.month":           "\n💾 <b>Data used this month:</b> %s",
+	"usage.of":              " of %s",
+	"chart.caption":         "📈 <b>Last 24h</b>",
+	"graph.caption":         "📈 <b>%s, last %s</b>",
+	"graph.metric.all":      "Speed and ping",
+	"graph.metric.download": "Download",
+	"graph.metric.upload":   "Upload",
+	"graph.metric.ping":     "Ping",
+	"graph.metric.jitter":   "Jitter",
+	"graph.empty":           "Not enough results in the last %s to draw a graph.",
+	"graph.failed":          "⚠️ <b>Failed to draw the graph.</b> Check the logs for details.",
+	"graph.usage":           "Usage: /graph [all|download|upload|ping|jitter] [period], e.g. /graph download 7d",
+	"inline.stats":          "📊 Stats for the last %s",
+	"inline.stats_desc":     "Averages, minimums and maximums over the last %s",
+	"inline.last":           "🕒 Latest result",
+	"inline.last_desc":      "The most recent speed test",
+	"quiet.digest":          "🌙 <b>Alerts during quiet hours</b> (%s–%s)",
+	"quiet.digest_more":     "...and %d more",
+	"family.title":          "🌍 <b>IP Family:</b>",
+	"family.failed":         "%s test failed while %s works",
+	"family.slower":         "%s is much slower than %s: ▼%.1f/▲%.1f vs ▼%.1f/▲%.1f Mbps",
+	"baseline.title":        "📉 <b>Below the usual speed</b> (%s):",
+	"baseline.download":     "Download %.1f Mbps is %.0f%% below the usual %.1f Mbps at this hour",
+	"baseline.upload":       "Upload %.1f Mbps is %.0f%% below the usual %.1f Mbps at this hour",
+	"trend.download":        "Download down %.0f%% over %d days",
+	"trend.upload":          "Upload down %.0f%% over %d days",
+	"monitor.lost":          "🔴 <b>Connection lost</b>\n%s unreachable since %s",
+	"monitor.restored":      "🟢 <b>Connection restored</b>\n%s was unreachable for %s (%s – %s)",
+	"network.changed":       "🌐 <b>Public network changed</b> (%s)",
+	"network.ip":            "\nIP: %s → %s",
+	"network.isp":           "\nISP: %s → %s",
+	"outage.down":           "🔴 <b>Internet appears DOWN</b>\n%d tests in a row failed, the first at %s",
+	"outage.restored":       "🟢 <b>Internet is back</b>\nIt was down for %s (%s – %s)",
+	"recovery.speed":        "✅ <b>Connection recovered</b>\nSpeed is back within the thresholds after %s of alerts\n\n%s",
+	"notify.failed":         "⚠️ <b>%s notifications are failing</b>\nMessages couldn't be delivered there: %s",
+	"sla.title":             "📜 <b>SLA</b> (%s – %s)\n",
+	"sla.speed":             "⚡ ▼%.0f ▲%.0f Mbps reached in %.1f%% of %d tests (promised %.0f%%)\n",
+	"sla.uptime":            "🟢 Uptime %.2f%%, down for %s (promised %.2f%%)\n",
+	"sla.met":               "✅ SLA met",
+	"sla.on_track":          "✅ SLA not breached so far",
+	"sla.breached":          "❌ SLA breached",
+	"sla.speed_breach":      "📜 <b>Speed SLA breached</b>\n%d of %d tests this billing month fell short of ▼%.0f ▲%.0f Mbps. Even if every remaining test reaches it, at most %.1f%% can, and %.0f%% was promised.",
+	"sla.uptime_breach":     "📜 <b>Uptime SLA breached</b>\nThe internet has been down for %s this billing month, more than the %s that %.2f%% uptime allows.",
+	"plan.title":            "📄 <b>Internet service report</b> (%s – %s)\n",
+	"plan.advertised":       "Advertised plan: ▼%.0f ▲%.0f Mbps\n\n",
+	"plan.tests":            "Automated speed tests: %d (%d failed)\n",
+	"plan.download":         "⬇️ Download: avg %.1f Mbps (%.0f%% of advertised), median %.1f, slowest 5%% below %.1f Mbps\n",
+	"plan.upload":           "⬆️ Upload: avg %.1f Mbps (%.0f%% of advertised), median %.1f, slowest 5%% below %.1f Mbps\n",
+	"plan.reached":          "   %.0f%% of tests reached %d%% of the advertised speed\n",
+	"plan.ping":             "📶 Ping: avg %dms, 95th percentile %dms\n",
+	"rules.alert":           "%s <b>%s</b>\n🔧 %s: %s",
+	"rules.streak":          " (%d tests in a row)",
+	"rules.cleared":         "✅ <b>%s</b> no longer applies\n🔧 %s\n\n%s",
+	"escalation.slow":       "%s <b>Escalation %d/%d:</b> speed has been below the thresholds for %s, since %s",
+	"escalation.down":       "%s <b>Escalation %d/%d:</b> the internet has been down for %s, since %s",
+	"dns.title":             "🌐 <b>DNS:</b>",
+	"dns.failed":            "- %s @%s: ❌ %v",
+	"dns.slow":              "- %s @%s: 🐢 %dms",
+	"dns.ok":                "- %s @%s: %dms",
+	"route.title":           "🛤 <b>Route to %s:</b>",
+	"endpoints.title":       "🖥 <b>Endpoints:</b>",
+	"endpoints.down":        "🔴 %s is DOWN: %s",
+	"endpoints.up":          "🟢 %s is UP again (%dms)",
+	"endpoints.failed":      "- %s: ❌ %s",
+	"endpoints.ok":          "- %s: ✅ %dms",
+
+	// Reports
+	"report.daily_title":           "📊 <b>Daily Report</b> (Last 24h)\n",
+	"report.title":                 "📊 <b>Report</b> (Last %s)\n",
+	"report.range_title":           "📊 <b>Report</b> (%s – %s)\n",
+	"report.tests":                 "Tests run: %d",
+	"report.lite":                  " (+%d lite checks)",
+	"report.maintenance":           " (%d during maintenance)",
+	"report.alerts":                "Alerts triggered: %d\n\n",
+	"report.alerts_severity":       "Alerts triggered: %d (⚠️ %d warnings, 🚨 %d critical)\n\n",
+	"report.download":              "📉 <b>Download</b>:\nAvg: %.2f ± %.2f | Median: %.2f | Min: %.2f | Max: %.2f Mbps\n",
+	"report.timeline":              "%s <code>%s</code> %s\n",
+	"report.upload":                "📈 <b>Upload</b>:\nAvg: %.2f ± %.2f | Median: %.2f | Min: %.2f | Max: %.2f Mbps\n",
+	"report.plan_download":         "📋 Download avg %.0f%% of the advertised %.0f Mbps\n",
+	"report.plan_upload":           "📋 Upload avg %.0f%% of the advertised %.0f Mbps\n",
+	"report.ping":                  "📶 <b>Ping</b>:\nAvg: %dms ± %dms | Median: %dms | Min: %dms | Max: %dms\n",
+	"report.jitter":                "〰️ <b>Jitter</b>:\nAvg: %dms | Max: %dms\n",
+	"report.bufferbloat":           "🎈 <b>Bufferbloat</b>:\nAvg: +%dms under load (grade %s)\n",
+	"report.hourly":                "\n🕐 <b>Download by hour</b>:\n<code>%s</code>\n<code>%s</code>\n",
+	"report.hourly_range":          "Slowest %02d:00 (▼%.1f Mbps), fastest %02d:00 (▼%.1f Mbps)\n",
+	"report.trend":                 "\n📉 <b>Steady decline</b>:\n",
+	"report.histogram":             "\n📊 <b>Download distribution</b> (Mbps):\n",
+	"report.bucket":                "<code>%s %s</code> %.0f%% (%d)\n",
+	"report.engines":               "\n🔧 <b>By Engine</b> (avg):\n",
+	"report.engine":                "- %s: ▼%.1f ▲%.1f Mbps, %dms (%d tests",
+	"report.engine_no_upload":      "- %s: ▼%.1f Mbps, %dms (%d tests",
+	"report.engine_failed":         ", %d failed",
+	"report.engine_alerts":         ", %d alerted",
+	"report.servers":               "\n🛰 <b>By Server</b> (avg):\n",
+	"report.unknown":               "unknown",
+	"report.monitor":               "\n🛰 <b>Ping Monitor</b>:\n",
+	"report.monitor_line":          "Avg: %dms | Loss: %.1f%% (%d probes)\n",
+	"report.uptime":                "🟢 Uptime: %.1f%%\n",
+	"report.uptime_outages":        "🟠 Uptime: %.1f%% (outages: %d, down %s)\n",
+	"report.streak":                "🔁 Longest failure streak: %d tests / %s\n",
+	"report.traffic":               "📦 Test traffic used: %s down / %s up\n",
+	"report.dns":                   "\n🌐 <b>DNS</b>:\n",
+	"report.dns_line":              "- %s: avg %dms, %d lookups",
+	"report.dns_failed":            ", %d failed",
+	"report.dns_slow":              ", %d slow",
+	"report.endpoints":             "\n🖥 <b>Endpoints</b>:\n",
+	"report.endpoint":              "- %s: %.1f%% up, avg %dms\n",
+	"report.low_speed":             "\n⚠️ <b>Low Quality Events:</b>\n",
+	"report.jitter_period":         "- %s–%s: 〰️ jitter up to %dms (%d tests)\n",
+	"report.low_speed_more":        "...and more\n",
+	"report.low_speed_event":       "- %s: ▼%.1f ▲%.1f Mbps, %dms\n",
+	"report.low_speed_maintenance": "- %s: ▼%.1f ▲%.1f Mbps, %dms 🛠 maintenance\n",
+
+	// Week and month summaries
+	"aggregate.title":    "📅 <b>Last %d days</b> (%s – %s)\n",
+	"aggregate.none":     "No speed tests in this period.",
+	"aggregate.tests":    "Tests run: %d (%d failed), alerts triggered: %d\n\n",
+	"aggregate.download": "📉 <b>Download</b>:\nAvg: %.1f | P5: %.1f | Median: %.1f | P95: %.1f Mbps\n",
+	"aggregate.upload":   "📈 <b>Upload</b>:\nAvg: %.1f | P5: %.1f | Median: %.1f | P95: %.1f Mbps\n",
+	"aggregate.ping":     "📶 <b>Ping</b>:\nAvg: %dms | Median: %dms | P95: %dms\n",
+	"aggregate.since":    "\n<i>Results only go back to %s. Keep them longer with a persistent STORAGE_BACKEND and RETENTION.</i>\n",
+	"aggregate.worst":    "\n🐢 <b>Worst days</b>:\n",
+	"compare.title":      "⚖️ <b>Last %s vs the %s before</b>\n\n",
+	"compare.no_data":    "Not enough results in one of the periods to compare.",
+	"compare.download":   "⬇️ Download: %.1f → %.1f Mbps (%s)\n",
+	"compare.upload":     "⬆️ Upload: %.1f → %.1f Mbps (%s)\n",
+	"compare.ping":       "📶 Ping: %d → %dms (%s)\n",
+	"compare.alerts":     "🚨 Alerts: %d → %d\n",
+	"compare.tests":      "🧪 Tests: %d → %d",
+	"compare.usage":      "Usage: /compare (last 24h), /compare week or /compare 12h",
+	"hours.title":        "🕐 <b>Hours of the day by download</b> (last %d days)\n",
+	"hours.none":         "Not enough results yet to rank the hours of the day.",
+	"hours.worst":        "\n🐢 <b>Slowest hours</b>:\n",
+	"hours.best":         "\n🏆 <b>Fastest hours</b>:\n",
+	"hours.line":         "- %02d:00: ▼%.1f ▲%.1f Mbps, below the day's average on %d of %d days\n",
+	"hours.no_upload":    "- %02d:00: ▼%.1f Mbps, below the day's average on %d of %d days\n",
+	"hours.consistent":   "\n⚠️ Consistently slower than the rest of the day: %s",
+	"hours.usage":        "Usage: /hours or /hours 30d",
+	"aggregate.day":      "- %s: ▼%.1f ▲%.1f Mbps, %dms (%d tests, %d alerts)\n",
+	"rollup.week_title":  "🗓 <b>Weekly Report</b> (%s – %s)\n",
+	"rollup.month_title": "🗓 <b>Monthly Report</b> (%s)\n",
+	"rollup.vs_week":     "<i>Averages, change from the week before:</i>\n",
+	"rollup.vs_month":    "<i>Averages, change from the month before:</i>\n",
+	"rollup.download":    "⬇️ Download: %.1f Mbps (%s)\n",
+	"rollup.upload":      "⬆️ Upload: %.1f Mbps (%s)\n",
+	"rollup.ping":        "📶 Ping: %dms (%s)\n",
+	"rollup.alerts":      "🚨 Alerts: %d (%d before)\n",
+	"rollup.best":        "\n🏆 <b>Best day</b>:\n",
+	"rollup.worst":       "🐢 <b>Worst day</b>:\n",
+}
@@ -0,0 +1,52 @@
+// Package i18n translates the texts the bot sends. The language is chosen once at startup
+// with BOT_LANG; keys missing from a catalog fall back to English.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const DefaultLang = "en"
+
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"uk": uk,
+}
+
+var active = en
+
+// SetLang selects the catalog used by T. Call it before the bot starts.
+func SetLang(lang string) error {
+	c, ok := catalogs[strings.ToLower(lang)]
+	if !ok {
+		return fmt.Errorf("unsupported language '%s' (available: %s)", lang, strings.Join(Langs(), ", "))
+	}
+	active = c
+	return nil
+}
+
+// Langs returns the available language codes.
+func Langs() []string {
+	langs := make([]string, 0, len(catalogs))
+	for l := range catalogs {
+		langs = append(langs, l)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// T returns the text for key in the active language, formatted with args like fmt.Sprintf.
+func T(key string, args ...any) string {
+	format, ok := active[key]
+	if !ok {
+		if format, ok = en[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,48 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	for lang, c := range catalogs {
+		for key, format := range en {
+			translated, ok := c[key]
+			if !ok {
+				t.Errorf("%s: missing key %q", lang, key)
+				continue
+			}
+			if verbs(translated) != verbs(format) {
+				t.Errorf("%s: %q has %d verbs, want %d", lang, key, verbs(translated), verbs(format))
+			}
+		}
+		for key := range c {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s: unknown key %q", lang, key)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLang(DefaultLang)
+
+	if err := SetLang("UK"); err != nil {
+		t.Fatalf("SetLang: %v", err)
+	}
+	if got := T("report.tests", 3); got != "Тестів виконано: 3" {
+		t.Errorf("T = %q", got)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q, want the key itself", got)
+	}
+	if err := SetLang("xx"); err == nil {
+		t.Error("expected an error for an unknown language")
+	}
+}
+
+// verbs counts formatting verbs, ignoring escaped percent signs.
+func verbs(format string) int {
+	return strings.Count(format, "%") - 2*strings.Count(format, "%%")
+}
@@ -0,0 +1,323 @@
+package i18n
+
+var uk = map[string]string{
+	// Access control
+	"auth.rejected":   "⛔ Вибачте, вам не дозволено користуватися цим ботом.",
+	"auth.admin_only": "⛔ Це можуть робити лише адміністратори.",
+
+	// Keyboard
+	"button.test":     "🚀 Запустити тест",
+	"button.last_24h": "📊 За 24 год",
+	"button.last_7d":  "📅 За 7 днів",
+	"button.settings": "⚙️ Налаштування",
+	"button.ack":      "✅ Підтвердити",
+
+	// Commands
+	"start.welcome": "👋 <b>Привіт!</b> Я Tetra, монітор вашого інтернет-з'єднання.\n\n" +
+		"Я періодично перевірятиму швидкість інтернету й повідомлю, якщо вона впаде нижче налаштованих порогів.\n" +
+		"Скористайтеся /help, щоб побачити доступні команди.",
+	"start.prompt": "Що бажаєте зробити?",
+	"bind.done":    "🔗 <b>Цей чат налаштовано.</b> Сповіщення та щоденні звіти надходитимуть сюди.",
+	"bind.code":    "Чат ще не налаштовано. Надішліть /start з кодом налаштування з журналу бота, щоб отримувати сповіщення тут.",
+	"help": "📋 <b>Доступні команди:</b>\n" +
+		"/test - Запустити тест швидкості зараз, /test verbose також додає сирі результати в JSON\n" +
+		"/stats - Статистика за останні 24 год, або /stats 7d, /stats 2024-05-01 2024-05-07\n" +
+		"/last - Останній результат без запуску тесту\n" +
+		"/alerts - Останні сповіщення і скільки вони тривали, напр. /alerts 30\n" +
+		"/report - Надіслати щоденний звіт зараз\n" +
+		"/compare - Порівняти останні 24 год із попередньою добою, або /compare week\n" +
+		"/week, /month - Підсумок за 7 або 30 днів із процентилями й найгіршими днями\n" +
+		"/hours - Години доби за швидкістю, напр. /hours 30d\n" +
+		"/graph - Графік завантаження, вивантаження, пінгу чи джитера, напр. /graph download 7d\n" +
+		"/export - Завантажити всі збережені результати у CSV\n" +
+		"/settings - Переглянути й змінити пороги, інтервал і час звіту\n" +
+		"/setinterval - Змінити інтервал перевірки, напр. /setinterval 15m\n" +
+		"/server - Найближчі сервери speedtest і вибір одного з них\n" +
+		"/status - Час роботи, останній і наступний тест, черга повідомлень\n" +
+		"/pause, /resume - Зупинити або відновити планові тести\n" +
+		"/ack - Підтвердити останнє сповіщення й притримати повтори\n" +
+		"/notify - Обрати, що ви отримуєте особисто, напр. /notify outages\n" +
+		"/audit - Показати, хто нещодавно виконував команди\n" +
+		"/mute 3h, /unmute - Тимчасово вимкнути сповіщення в цьому чаті\n" +
+		"/help - Показати цю довідку\n" +
+		"/start - Вітальне повідомлення",
+	"ack.by":              "\n\n✅ <i>Підтвердив(-ла) %s о %s</i>",
+	"ack.done":            "✅ <b>Підтверджено.</b> Повторні сповіщення не надсилатимуться, доки тест знову не пройде.",
+	"ack.untracked":       "Це сповіщення вже не можна підтвердити, бот його більше не відстежує.",
+	"ack.none":            "У цьому чаті немає недавнього сповіщення для підтвердження.",
+	"alert.resolved":      "✅ <b>Вирішено</b> %s\n\n<s>%s</s>",
+	"alert.expired":       "🕒 <b>Застаріло</b>\n\n<s>%s</s>",
+	"test.starting":       "🚀 <b>Запускаю тест швидкості...</b> Зачекайте, будь ласка.",
+	"test.raw_caption":    "🧾 Сирі результати: сервери, затримки, обсяги даних і дані рушіїв",
+	"test.running":        "🚀 <b>Тест швидкості триває...</b>\n%s",
+	"test.phase.start":    "⏳ %s: запуск",
+	"test.phase.ping":     "📶 %s: вимірювання пінгу",
+	"test.phase.download": "⬇️ %s: завантаження",
+	"test.phase.upload":   "⬆️ %s: вивантаження",
+	"export.failed":       "⚠️ <b>Не вдалося експортувати.</b> Подробиці в логах.",
+	"export.caption":      "📄 Експорт результатів тестів швидкості",
+
+	// Status
+	"status.title":       "🩺 <b>Стан</b>\n",
+	"status.version":     "🏷 Версія: %s\n",
+	"status.uptime":      "⏳ Час роботи: %s\n",
+	"status.last":        "✅ Останній успішний тест: %s (%s тому)\n",
+	"status.last_none":   "✅ Останній успішний тест: ще не було після запуску\n",
+	"status.next":        "⏭ Наступний плановий тест: %s\n",
+	"status.next_paused": "⏭ Наступний плановий тест: призупинено\n",
+	"status.queue":       "📨 Повідомлень у черзі: %d\n",
+	"status.streak":      "❌ Невдалих тестів поспіль: %d, з %s\n",
+	"status.longest":     "🔁 Найдовша серія невдач за 24 год: %d тестів / %s\n",
+	"status.failover":    "🔁 Надсилання через резервного бота з %s\n",
+	"status.notifier":    "⚠️ Сповіщення %s не доходять з %s (втрачено %d): %s\n",
+	"status.thresholds":  "🎯 Пороги: ▼%.0f ▲%.0f Мбіт/с",
+
+	// Stats
+	"stats.usage": "📊 <b>Використання:</b>\n" +
+		"/stats - останні 24 год\n" +
+		"/stats 7d, /stats 12h - період до цього моменту\n" +
+		"/stats 2024-05-01 - один день\n" +
+		"/stats 2024-05-01 2024-05-07 - діапазон днів",
+
+	// Last result
+	"last.title":    "🕒 <b>Останній результат</b> (%s, %s тому)",
+	"last.lite":     " (легка перевірка)",
+	"last.alert":    "🚨 Цей результат спричинив сповіщення.",
+	"last.no_alert": "✅ Сповіщення не надсилалося.",
+	"last.none":     "🕒 Ще немає збережених результатів. Запустіть тест командою /test.",
+	"last.failed":   "⚠️ <b>Не вдалося прочитати останній результат.</b> Подробиці в логах.",
+
+	// Settings
+	"settings.save_failed":   "⚠️ <b>Не вдалося зберегти налаштування.</b> Подробиці в логах.",
+	"settings.title":         "⚙️ <b>Налаштування</b>\n",
+	"settings.download":      "⬇️ Поріг завантаження: %.0f Мбіт/с\n",
+	"settings.upload":        "⬆️ Поріг вивантаження: %.0f Мбіт/с\n",
+	"settings.report":        "📊 Щоденний звіт: %02d:00 %s\n",
+	"settings.alerts":        "🔔 Сповіщення: %s\n",
+	"settings.interval":      "⏱ Інтервал перевірки: %s\n",
+	"settings.paused":        "⏸ Моніторинг призупинено, /resume відновить планові тести\n",
+	"settings.engines":       "🔧 Рушії: %s",
+	"settings.jitter":        "\n〰️ Поріг джитера: %.0f мс",
+	"settings.per_chat":      "\n\n<i>Пороги, час звіту й сповіщення діють лише для цього чату; інтервал перевірки спільний.</i>",
+	"settings.switch_alerts": "🔔 Перемкнути сповіщення: %s",
+	"verbosity.full":         "повні",
+	"verbosity.short":        "короткі",
+	"verbosity.off":          "вимкнені",
+	"settings.charts":        "📈 Графіки у звітах: %s\n",
+	"settings.switch_charts": "📈 Перемкнути графіки: %s",
+	"charts.image":           "зображення",
+	"charts.text":            "текст",
+	"pause.paused":           "⏸ <b>Моніторинг призупинено.</b> Планові тести пропускаються до /resume; /test і далі працює.",
+	"pause.resumed":          "▶️ <b>Моніторинг відновлено.</b>",
+	"settings.muted":         "🔕 Сповіщення вимкнено до %s\n",
+	"alerts.title":           "🚨 <b>Останні сповіщення: %d</b>\n",
+	"alerts.entry":           "\n%s %s, %s",
+	"alerts.download":        "▼ %.1f Мбіт/с (нижче %.0f)",
+	"alerts.upload":          "▲ %.1f Мбіт/с (нижче %.0f)",
+	"alerts.jitter":          "〰️ джитер %.0f мс (вище %.0f)",
+	"alerts.baseline":        "📉 ▼ %.1f Мбіт/с (зазвичай %.0f)",
+	"alerts.outage":          "🔴 інтернет недоступний",
+	"alerts.rule":            "📏 правило %s",
+	"alerts.lasted":          "тривало %s",
+	"alerts.ongoing":         "триває вже %s",
+	"alerts.empty":           "Сповіщень ще не було.",
+	"alerts.usage":           "Використання: /alerts або /alerts 30",
+	"audit.title":            "📜 <b>Останні команди: %d</b>\n",
+	"audit.entry":            "\n%s %s: <code>%s</code>",
+	"audit.entry_denied":     "\n%s %s: <code>%s</code> ⛔",
+	"audit.empty":            "Команд ще не записано.",
+	"audit.usage":            "Використання: /audit або /audit 50",
+	"notify.current":         "🔔 Ви отримуєте: <b>%s</b>\n\n",
+	"notify.set":             "🔔 Відтепер ви отримуватимете <b>%s</b> в особистому чаті з ботом.",
+	"notify.start_private":   "\nЯкщо ще не зробили цього, відкрийте особистий чат із ботом і натисніть «Почати», боти не можуть написати першими.",
+	"notify.usage":           "Використання: /notify all, /notify outages (лише втрата з'єднання й невдалі тести), /notify reports (лише щоденний звіт) або /notify off",
+	"notify.all":             "усі сповіщення",
+	"notify.outages":         "лише сповіщення про збої",
+	"notify.reports":         "лише щоденний звіт",
+	"notify.off":             "нічого",
+	"mute.usage":             "Використання: /mute 3h (або 30m, 1d), /mute off чи /unmute",
+	"mute.set":               "🔕 <b>Сповіщення вимкнено до %s.</b> Тести тривають, результати записуються; /unmute, щоб увімкнути раніше.",
+	"mute.no_alerts":         "\n<i>Зауважте, що в цей чат сповіщення й так не надсилаються.</i>",
+	"mute.ended":             "🔔 <b>Сповіщення увімкнено.</b>",
+	"mute.expired":           "🔔 <b>Тишу завершено,</b> сповіщення знову увімкнені.",
+	"interval.usage":         "Використання: /setinterval 15m (щонайменше 1m, напр. 90s, 2h, 1d)",
+	"interval.invalid":       "⚠️ <b>Інтервал не змінено:</b> %s",
+	"interval.set":           "⏱ <b>Інтервал перевірки: %s.</b> Наступний плановий тест через один інтервал.",
+	"interval.not_saved":     "\n<i>SETTINGS_PATH не задано, тож після перезапуску зміна втратиться.</i>",
+
+	// Server selection
+	"servers.failed":         "⚠️ <b>Не вдалося отримати список серверів.</b> Подробиці в логах.",
+	"servers.title":          "🛰 <b>Найближчі сервери speedtest</b>\n",
+	"servers.line":           "\n<code>%s</code> %s, %s (%s) — %.0f км, %s%s",
+	"servers.latency":        "%dмс",
+	"servers.timeout":        "тайм-аут",
+	"servers.current_auto":   "\n\nЗараз для кожного тесту обирається найближчий сервер.",
+	"servers.current_pinned": "\n\nЗакріплений сервер: <code>%s</code>",
+	"servers.button_auto":    "🧭 Найближчий сервер (авто)",
+	"servers.pinned":         "📌 Тести використовуватимуть сервер <code>%s</code>.",
+	"servers.auto":           "🧭 Тести використовуватимуть найближчий сервер.",
+
+	// Test results and alerts
+	"result.failed":         "⚠️ <b>Тест не вдався:</b> %v",
+	"result.speed":          "⬇️ <b>Завантаження:</b> %.2f Мбіт/с\n⬆️ <b>Вивантаження:</b> %.2f Мбіт/с\n📶 <b>Пінг:</b> %d мс",
+	"result.jitter":         "\n〰️ <b>Джитер:</b> %d мс",
+	"result.bufferbloat":    "\n🎈 <b>Bufferbloat:</b> %s (+%d мс під навантаженням)",
+	"result.share":          "\n🔗 <a href=\"%s\">Результат Speedtest</a>",
+	"outcome.manual":        "✅ <b>Результат ручного тесту:</b>\n%s",
+	"outcome.alert":         "🚨 <b>Погіршення якості інтернету!</b>\n%s",
+	"outcome.warning":       "⚠️ <b>Попередження про якість інтернету</b>\n%s",
+	"outcome.jitter_alert":  "〰️ <b>Високий джитер!</b>\nШвидкість у нормі, але затримка нестабільна: дзвінки, відео та ігри можуть переривчатися.\n%s",
+	"outcome.jitter_warn":   "〰️ <b>Попередження: високий джитер</b>\nШвидкість у нормі, але затримка нестабільна: дзвінки, відео та ігри можуть переривчатися.\n%s",
+	"outcome.jitter_high":   "\n⚠️ Джитер %d мс перевищує поріг %d мс",
+	"outcome.confirmed":     "\n\n🔁 Підтверджено %d тестами поспіль",
+	"budget.reached":        "💾 <b>Ліміт трафіку вичерпано:</b> використано %s з %s цього місяця. До наступного місяця виконуються лише легкі перевірки.",
+	"usage.month":           "\n💾 <b>Трафік за місяць:</b> %s",
+	"usage.of":              " з %s",
+	"chart.caption":         "📈 <b>Останні 24 год</b>",
+	"graph.caption":         "📈 <b>%s, останні %s</b>",
+	"graph.metric.all":      "Швидкість і пінг",
+	"graph.metric.download": "Завантаження",
+	"graph.metric.upload":   "Вивантаження",
+	"graph.metric.ping":     "Пінг",
+	"graph.metric.jitter":   "Джитер",
+	"graph.empty":           "Замало результатів за останні %s, щоб побудувати графік.",
+	"graph.failed":          "⚠️ <b>Не вдалося побудувати графік.</b> Подробиці в журналі.",
+	"graph.usage":           "Використання: /graph [all|download|upload|ping|jitter] [період], напр. /graph download 7d",
+	"inline.stats":          "📊 Статистика за останні %s",
+	"inline.stats_desc":     "Середні, мінімальні й максимальні значення за останні %s",
+	"inline.last":           "🕒 Останній результат",
+	"inline.last_desc":      "Найсвіжіший тест швидкості",
+	"quiet.digest":          "🌙 <b>Сповіщення за тихі години</b> (%s–%s)",
+	"quiet.digest_more":     "...та ще %d",
+	"family.title":          "🌍 <b>Версія IP:</b>",
+	"family.failed":         "тест %s не вдався, хоча %s працює",
+	"family.slower":         "%s значно повільніший за %s: ▼%.1f/▲%.1f проти ▼%.1f/▲%.1f Мбіт/с",
+	"baseline.title":        "📉 <b>Нижче звичної швидкості</b> (%s):",
+	"baseline.download":     "Завантаження %.1f Мбіт/с на %.0f%% нижче звичних для цієї години %.1f Мбіт/с",
+	"baseline.upload":       "Вивантаження %.1f Мбіт/с на %.0f%% нижче звичних для цієї години %.1f Мбіт/с",
+	"trend.download":        "Завантаження впало на %.0f%% за %d дн.",
+	"trend.upload":          "Вивантаження впало на %.0f%% за %d дн.",
+	"monitor.lost":          "🔴 <b>З'єднання втрачено</b>\n%s недоступний з %s",
+	"monitor.restored":      "🟢 <b>З'єднання відновлено</b>\n%s був недоступний %s (%s – %s)",
+	"network.changed":       "🌐 <b>Змінилася зовнішня мережа</b> (%s)",
+	"network.ip":            "\nIP: %s → %s",
+	"network.isp":           "\nПровайдер: %s → %s",
+	"outage.down":           "🔴 <b>Схоже, інтернету НЕМАЄ</b>\n%d тестів поспіль не вдалися, перший о %s",
+	"outage.restored":       "🟢 <b>Інтернет повернувся</b>\nЙого не було %s (%s – %s)",
+	"recovery.speed":        "✅ <b>З'єднання відновилося</b>\nШвидкість знову в межах порогів після %s сповіщень\n\n%s",
+	"notify.failed":         "⚠️ <b>Сповіщення %s не доходять</b>\nНе вдалося доставити повідомлення: %s",
+	"sla.title":             "📜 <b>SLA</b> (%s – %s)\n",
+	"sla.speed":             "⚡ ▼%.0f ▲%.0f Мбіт/с досягнуто в %.1f%% з %d тестів (обіцяно %.0f%%)\n",
+	"sla.uptime":            "🟢 Доступність %.2f%%, простій %s (обіцяно %.2f%%)\n",
+	"sla.met":               "✅ SLA виконано",
+	"sla.on_track":          "✅ SLA поки не порушено",
+	"sla.breached":          "❌ SLA порушено",
+	"sla.speed_breach":      "📜 <b>SLA швидкості порушено</b>\n%d з %d тестів цього розрахункового місяця не досягли ▼%.0f ▲%.0f Мбіт/с. Навіть якщо всі наступні тести її досягнуть, це буде щонайбільше %.1f%%, а обіцяно %.0f%%.",
+	"sla.uptime_breach":     "📜 <b>SLA доступності порушено</b>\nІнтернету не було %s цього розрахункового місяця, більше ніж %s, які допускає доступність %.2f%%.",
+	"plan.title":            "📄 <b>Звіт про якість інтернету</b> (%s – %s)\n",
+	"plan.advertised":       "Заявлений тариф: ▼%.0f ▲%.0f Мбіт/с\n\n",
+	"plan.tests":            "Автоматичних тестів швидкості: %d (невдалих: %d)\n",
+	"plan.download":         "⬇️ Завантаження: в сер. %.1f Мбіт/с (%.0f%% від заявленого), медіана %.1f, найповільніші 5%% нижче %.1f Мбіт/с\n",
+	"plan.upload":           "⬆️ Вивантаження: в сер. %.1f Мбіт/с (%.0f%% від заявленого), медіана %.1f, найповільніші 5%% нижче %.1f Мбіт/с\n",
+	"plan.reached":          "   %.0f%% тестів досягли %d%% заявленої швидкості\n",
+	"plan.ping":             "📶 Пінг: в сер. %dмс, 95-й перцентиль %dмс\n",
+	"rules.alert":           "%s <b>%s</b>\n🔧 %s: %s",
+	"rules.streak":          " (%d тестів поспіль)",
+	"rules.cleared":         "✅ <b>%s</b> більше не спрацьовує\n🔧 %s\n\n%s",
+	"escalation.slow":       "%s <b>Ескалація %d/%d:</b> швидкість нижча за пороги вже %s, з %s",
+	"escalation.down":       "%s <b>Ескалація %d/%d:</b> інтернету немає вже %s, з %s",
+	"dns.title":             "🌐 <b>DNS:</b>",
+	"dns.failed":            "- %s @%s: ❌ %v",
+	"dns.slow":              "- %s @%s: 🐢 %dмс",
+	"dns.ok":                "- %s @%s: %dмс",
+	"route.title":           "🛤 <b>Маршрут до %s:</b>",
+	"endpoints.title":       "🖥 <b>Сервіси:</b>",
+	"endpoints.down":        "🔴 %s НЕДОСТУПНИЙ: %s",
+	"endpoints.up":          "🟢 %s знову доступний (%dмс)",
+	"endpoints.failed":      "- %s: ❌ %s",
+	"endpoints.ok":          "- %s: ✅ %dмс",
+
+	// Reports
+	"report.daily_title":           "📊 <b>Щоденний звіт</b> (за 24 год)\n",
+	"report.title":                 "📊 <b>Звіт</b> (за %s)\n",
+	"report.range_title":           "📊 <b>Звіт</b> (%s – %s)\n",
+	"report.tests":                 "Тестів виконано: %d",
+	"report.lite":                  " (+%d легких перевірок)",
+	"report.maintenance":           " (%d під час обслуговування)",
+	"report.alerts":                "Сповіщень надіслано: %d\n\n",
+	"report.alerts_severity":       "Сповіщень надіслано: %d (⚠️ %d попереджень, 🚨 %d критичних)\n\n",
+	"report.download":              "📉 <b>Завантаження</b>:\nСер.: %.2f ± %.2f | Медіана: %.2f | Мін.: %.2f | Макс.: %.2f Мбіт/с\n",
+	"report.timeline":              "%s <code>%s</code> %s\n",
+	"report.upload":                "📈 <b>Вивантаження</b>:\nСер.: %.2f ± %.2f | Медіана: %.2f | Мін.: %.2f | Макс.: %.2f Мбіт/с\n",
+	"report.plan_download":         "📋 Завантаження в сер. %.0f%% від заявлених %.0f Мбіт/с\n",
+	"report.plan_upload":           "📋 Вивантаження в сер. %.0f%% від заявлених %.0f Мбіт/с\n",
+	"report.ping":                  "📶 <b>Пінг</b>:\nСер.: %dмс ± %dмс | Медіана: %dмс | Мін.: %dмс | Макс.: %dмс\n",
+	"report.jitter":                "〰️ <b>Джитер</b>:\nСер.: %dмс | Макс.: %dмс\n",
+	"report.bufferbloat":           "🎈 <b>Bufferbloat</b>:\nСер.: +%dмс під навантаженням (оцінка %s)\n",
+	"report.hourly":                "\n🕐 <b>Завантаження за годинами</b>:\n<code>%s</code>\n<code>%s</code>\n",
+	"report.hourly_range":          "Найповільніше о %02d:00 (▼%.1f Мбіт/с), найшвидше о %02d:00 (▼%.1f Мбіт/с)\n",
+	"report.trend":                 "\n📉 <b>Стале погіршення</b>:\n",
+	"report.histogram":             "\n📊 <b>Розподіл завантаження</b> (Мбіт/с):\n",
+	"report.bucket":                "<code>%s %s</code> %.0f%% (%d)\n",
+	"report.engines":               "\n🔧 <b>За рушієм</b> (сер.):\n",
+	"report.engine":                "- %s: ▼%.1f ▲%.1f Мбіт/с, %dмс (тестів: %d",
+	"report.engine_no_upload":      "- %s: ▼%.1f Мбіт/с, %dмс (тестів: %d",
+	"report.engine_failed":         ", невдалих: %d",
+	"report.engine_alerts":         ", зі сповіщенням: %d",
+	"report.servers":               "\n🛰 <b>За сервером</b> (сер.):\n",
+	"report.unknown":               "невідомий",
+	"report.monitor":               "\n🛰 <b>Монітор пінгу</b>:\n",
+	"report.monitor_line":          "Сер.: %dмс | Втрати: %.1f%% (%d проб)\n",
+	"report.uptime":                "🟢 Доступність: %.1f%%\n",
+	"report.uptime_outages":        "🟠 Доступність: %.1f%% (збоїв: %d, простій %s)\n",
+	"report.streak":                "🔁 Найдовша серія невдач: %d тестів / %s\n",
+	"report.traffic":               "📦 Трафік тестів: %s отримано / %s надіслано\n",
+	"report.dns":                   "\n🌐 <b>DNS</b>:\n",
+	"report.dns_line":              "- %s: сер. %dмс, запитів: %d",
+	"report.dns_failed":            ", невдалих: %d",
+	"report.dns_slow":              ", повільних: %d",
+	"report.endpoints":             "\n🖥 <b>Сервіси</b>:\n",
+	"report.endpoint":              "- %s: доступний %.1f%%, сер. %dмс\n",
+	"report.low_speed":             "\n⚠️ <b>Погіршення якості:</b>\n",
+	"report.jitter_period":         "- %s–%s: 〰️ джитер до %dмс (%d тестів)\n",
+	"report.low_speed_more":        "...та інші\n",
+	"report.low_speed_event":       "- %s: ▼%.1f ▲%.1f Мбіт/с, %dмс\n",
+	"report.low_speed_maintenance": "- %s: ▼%.1f ▲%.1f Мбіт/с, %dмс 🛠 обслуговування\n",
+
+	// Week and month summaries
+	"aggregate.title":    "📅 <b>Останні %d днів</b> (%s – %s)\n",
+	"aggregate.none":     "За цей період тестів швидкості не було.",
+	"aggregate.tests":    "Тестів виконано: %d (%d невдалих), сповіщень: %d\n\n",
+	"aggregate.download": "📉 <b>Завантаження</b>:\nСер.: %.1f | P5: %.1f | Медіана: %.1f | P95: %.1f Мбіт/с\n",
+	"aggregate.upload":   "📈 <b>Вивантаження</b>:\nСер.: %.1f | P5: %.1f | Медіана: %.1f | P95: %.1f Мбіт/с\n",
+	"aggregate.ping":     "📶 <b>Пінг</b>:\nСер.: %dмс | Медіана: %dмс | P95: %dмс\n",
+	"aggregate.since":    "\n<i>Результати є лише з %s. Зберігайте їх довше з постійним STORAGE_BACKEND і RETENTION.</i>\n",
+	"aggregate.worst":    "\n🐢 <b>Найгірші дні</b>:\n",
+	"compare.title":      "⚖️ <b>Останні %s проти попередніх %s</b>\n\n",
+	"compare.no_data":    "Замало результатів в одному з періодів для порівняння.",
+	"compare.download":   "⬇️ Завантаження: %.1f → %.1f Мбіт/с (%s)\n",
+	"compare.upload":     "⬆️ Вивантаження: %.1f → %.1f Мбіт/с (%s)\n",
+	"compare.ping":       "📶 Пінг: %d → %dмс (%s)\n",
+	"compare.alerts":     "🚨 Сповіщення: %d → %d\n",
+	"compare.tests":      "🧪 Тести: %d → %d",
+	"compare.usage":      "Використання: /compare (останні 24 год), /compare week або /compare 12h",
+	"hours.title":        "🕐 <b>Години доби за швидкістю завантаження</b> (останні %d днів)\n",
+	"hours.none":         "Поки замало результатів, щоб порівняти години доби.",
+	"hours.worst":        "\n🐢 <b>Найповільніші години</b>:\n",
+	"hours.best":         "\n🏆 <b>Найшвидші години</b>:\n",
+	"hours.line":         "- %02d:00: ▼%.1f ▲%.1f Мбіт/с, нижче за середню за день у %d з %d днів\n",
+	"hours.no_upload":    "- %02d:00: ▼%.1f Мбіт/с, нижче за середню за день у %d з %d днів\n",
+	"hours.consistent":   "\n⚠️ Стабільно повільніше за решту доби: %s",
+	"hours.usage":        "Використання: /hours або /hours 30d",
+	"aggregate.day":      "- %s: ▼%.1f ▲%.1f Мбіт/с, %dмс (%d тестів, %d сповіщень)\n",
+	"rollup.week_title":  "🗓 <b>Тижневий звіт</b> (%s – %s)\n",
+	"rollup.month_title": "🗓 <b>Місячний звіт</b> (%s)\n",
+	"rollup.vs_week":     "<i>Середні, зміна від попереднього тижня:</i>\n",
+	"rollup.vs_month":    "<i>Середні, зміна від попереднього місяця:</i>\n",
+	"rollup.download":    "⬇️ Завантаження: %.1f Мбіт/с (%s)\n",
+	"rollup.upload":      "⬆️ Вивантаження: %.1f Мбіт/с (%s)\n",
+	"rollup.ping":        "📶 Пінг: %dмс (%s)\n",
+	"rollup.alerts":      "🚨 Сповіщення: %d (до того %d)\n",
+	"rollup.best":        "\n🏆 <b>Найкращий день</b>:\n",
+	"rollup.worst":       "🐢 <b>Найгірший день</b>:\n",
+}
@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"context"
+	"html"
+	"net"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/ckayt/tetra/internal/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// Monitor pings a host every few seconds between speed tests to catch short
+// outages a full test every half hour would miss. It uses TCP connects instead
+// of ICMP so it works without raw socket privileges.
+type Monitor struct {
+	target    string
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int // consecutive lost probes before an outage is declared
+	statsMgr  *stats.Manager
+	notify    func(msg string, restored bool)
+}
+
+func New(cfg *config.Config, statsMgr *stats.Manager, notify func(msg string, restored bool)) *Monitor {
+	target := cfg.PingMonitorHost
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, "443")
+	}
+	threshold := cfg.PingMonitorOutageThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Monitor{
+		target:    target,
+		interval:  cfg.PingMonitorInterval,
+		timeout:   cfg.PingMonitorTimeout,
+		threshold: threshold,
+		statsMgr:  statsMgr,
+		notify:    notify,
+	}
+}
+
+// Loop probes the target every interval until ctx is cancelled.
+func (m *Monitor) Loop(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	log.Info().Str("target", m.target).Dur("interval", m.interval).Msg("Starting ping monitor")
+
+	var (
+		lost      int
+		firstLost time.Time
+		down      bool
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		latency, err := m.probe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		m.statsMgr.AddPing(now, latency, err == nil)
+
+		if err != nil {
+			log.Debug().Err(err).Str("target", m.target).Msg("Ping probe lost")
+			if lost == 0 {
+				firstLost = now
+			}
+			lost++
+			if !down && lost >= m.threshold {
+				down = true
+				log.Warn().Str("target", m.target).Time("since", firstLost).Msg("Outage detected")
+				m.notify(i18n.T("monitor.lost", html.EscapeString(m.target), firstLost.Format("15:04:05")), false)
+			}
+			continue
+		}
+
+		if down {
+			o := stats.Outage{Start: firstLost, End: now, Target: m.target}
+			m.statsMgr.AddOutage(o)
+			log.Warn().Str("target", m.target).Dur("duration", o.Duration()).Msg("Outage ended")
+			text := i18n.T("monitor.restored",
+				html.EscapeString(m.target), o.Duration().Round(time.Second), o.Start.Format("15:04:05"), o.End.Format("15:04:05"))
+			data := templates.RecoveryData{Target: m.target, Downtime: o.Duration(), Start: o.Start, End: o.End, Text: text}
+			m.notify(templates.Render(templates.Recovery, data, text), true)
+		}
+		lost, down = 0, false
+	}
+}
+
+// probe measures the time to establish a TCP connection to the target.
+func (m *Monitor) probe(ctx context.Context) (time.Duration, error) {
+	dialer := net.Dialer{Timeout: m.timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", m.target)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
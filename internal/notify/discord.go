@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+)
+
+// Embed limits
+const (
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+	discordEmbedsLimit      = 10
+	discordMessageLimit     = 6000 // all embeds of a message together
+)
+
+// discordColors sets the color bar of the embed by message class.
+var discordColors = map[string]int{
+	"alert":    0xE74C3C,
+	"warning":  0xF1C40F,
+	"recovery": 0x2ECC71,
+	"report":   0x3498DB,
+	"info":     0x95A5A6,
+}
+
+// discordMarkup is Discord's markdown, where the characters that start formatting are escaped with a backslash.
+var discordMarkup = markup{
+	bold:   "**",
+	italic: "*",
+	code:   "`",
+	pre:    "```",
+	link:   func(url, text string) string { return "[" + text + "](" + url + ")" },
+	escape: strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`).Replace,
+}
+
+// Discord posts messages as embeds through a channel webhook.
+type Discord struct {
+	client     *http.Client
+	webhookURL string
+}
+
+func NewDiscord(cfg *config.Config) *Discord {
+	return &Discord{
+		client:     &http.Client{Timeout: 15 * time.Second},
+		webhookURL: cfg.DiscordWebhookURL,
+	}
+}
+
+func (d *Discord) Name() string {
+	return "discord"
+}
+
+func (d *Discord) Notify(ctx context.Context, m Message) error {
+	body, err := json.Marshal(discordMessage(m, time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("discord returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// discordMessage lays a message out as embeds: its first line as the title, the rest as the
+// description, continued in further embeds when it is too long for one.
+func discordMessage(m Message, now time.Time) map[string]any {
+	title, body := splitTitle(m.Text)
+	header := plainText(title)
+	if header == "" || len([]rune(header)) > discordTitleLimit {
+		header, body = "", m.Text
+	}
+
+	var embeds []map[string]any
+	total := len([]rune(header))
+	for _, chunk := range chunks(discordMarkup.convert(body), discordDescriptionLimit) {
+		total += len([]rune(chunk))
+		if len(embeds) == discordEmbedsLimit || total > discordMessageLimit {
+			break
+		}
+		embeds = append(embeds, map[string]any{"description": chunk, "color": discordColors[m.Class]})
+	}
+	if len(embeds) == 0 {
+		embeds = append(embeds, map[string]any{"color": discordColors[m.Class]})
+	}
+	if header != "" {
+		embeds[0]["title"] = header
+	}
+	embeds[len(embeds)-1]["timestamp"] = now.UTC().Format(time.RFC3339)
+	return map[string]any{
+		"embeds": embeds,
+		// Message text never pings anyone
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
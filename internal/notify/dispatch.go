@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"context"
+	"html"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// attemptTimeout bounds a single delivery attempt.
+const attemptTimeout = 30 * time.Second
+
+// Failure describes a backend whose messages couldn't be delivered.
+type Failure struct {
+	Backend string
+	Err     error
+	Since   time.Time // first message given up on since the last successful delivery
+	Dropped int       // messages given up on since then
+}
+
+// Dispatcher fans messages out to every backend, retrying each one on its own, so a slow or
+// broken backend doesn't hold up or lose the messages of the others. Each backend gets its
+// messages in the order they were dispatched.
+type Dispatcher struct {
+	notifiers []Notifier
+	retries   int
+	backoff   time.Duration // before the first retry, doubling after that
+
+	mu      sync.Mutex
+	failing map[string]*Failure
+	lanes   map[string]*lane
+}
+
+// lane holds the messages waiting for a backend without chats of its own, which a single
+// worker delivers one after the other while there are any.
+type lane struct {
+	pending []queued
+	running bool
+}
+
+type queued struct {
+	ctx context.Context
+	msg Message
+}
+
+func NewDispatcher(cfg *config.Config, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{
+		notifiers: notifiers,
+		retries:   cfg.NotifyRetries,
+		backoff:   10 * time.Second,
+		failing:   make(map[string]*Failure),
+		lanes:     make(map[string]*lane),
+	}
+}
+
+// Dispatch sends the message to the backends it is for. The Telegram bot queues it right
+// away, since it delivers its queue itself, the other backends get it in the background.
+func (d *Dispatcher) Dispatch(ctx context.Context, m Message) {
+	for _, n := range d.notifiers {
+		if d.wants(n, m) {
+			d.send(ctx, n, m)
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, n Notifier, m Message) {
+	if _, ok := n.(ChatNotifier); !ok {
+		d.enqueue(ctx, n, m)
+		return
+	}
+	if err := n.Notify(ctx, m); err != nil {
+		d.giveUp(ctx, n, m, err)
+		return
+	}
+	d.recovered(n.Name())
+}
+
+// enqueue appends a message to the lane of a backend, starting its worker if it is idle.
+func (d *Dispatcher) enqueue(ctx context.Context, n Notifier, m Message) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	l, ok := d.lanes[n.Name()]
+	if !ok {
+		l = &lane{}
+		d.lanes[n.Name()] = l
+	}
+	l.pending = append(l.pending, queued{ctx: ctx, msg: m})
+	if !l.running {
+		l.running = true
+		go d.drain(n, l)
+	}
+}
+
+// drain delivers the messages of a lane in order until it is empty.
+func (d *Dispatcher) drain(n Notifier, l *lane) {
+	for {
+		d.mu.Lock()
+		if len(l.pending) == 0 {
+			l.running = false
+			d.mu.Unlock()
+			return
+		}
+		next := l.pending[0]
+		l.pending = l.pending[1:]
+		d.mu.Unlock()
+		d.deliver(next.ctx, n, next.msg)
+	}
+}
+
+// Failures returns the backends whose last message couldn't be delivered.
+func (d *Dispatcher) Failures() []Failure {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var failures []Failure
+	for _, n := range d.notifiers {
+		if f, ok := d.failing[n.Name()]; ok {
+			failures = append(failures, *f)
+		}
+	}
+	return failures
+}
+
+func (d *Dispatcher) wants(n Notifier, m Message) bool {
+	if _, ok := n.(ChatNotifier); ok {
+		return len(m.Chats) > 0
+	}
+	return m.Others
+}
+
+// deliver sends a message to one backend, retrying with backoff.
+func (d *Dispatcher) deliver(ctx context.Context, n Notifier, m Message) {
+	var err error
+	wait := d.backoff
+	for attempt := 0; attempt <= d.retries; attempt++ {
+		if attempt > 0 {
+			log.Warn().Err(err).Str("notifier", n.Name()).Int("attempt", attempt).Dur("wait", wait).Msg("Notification failed, retrying")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		err = n.Notify(attemptCtx, m)
+		cancel()
+		if err == nil {
+			d.recovered(n.Name())
+			return
+		}
+	}
+
+	d.giveUp(ctx, n, m, err)
+}
+
+// giveUp records a message a backend didn't get. When the backend worked until then, the other
+// backends tell the message's recipients.
+func (d *Dispatcher) giveUp(ctx context.Context, n Notifier, m Message, err error) {
+	log.Error().Err(err).Str("notifier", n.Name()).Str("class", m.Class).Msg("Failed to deliver notification")
+	if d.failed(n.Name(), err) {
+		notice := Message{Class: "info", Text: i18n.T("notify.failed", n.Name(), html.EscapeString(err.Error())), Chats: m.Chats, Others: m.Others}
+		for _, other := range d.notifiers {
+			if other != n && d.wants(other, notice) {
+				d.send(ctx, other, notice)
+			}
+		}
+	}
+}
+
+// failed records a message given up on and reports whether the backend worked until now.
+func (d *Dispatcher) failed(name string, err error) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if f, ok := d.failing[name]; ok {
+		f.Err = err
+		f.Dropped++
+		return false
+	}
+	d.failing[name] = &Failure{Backend: name, Err: err, Since: time.Now(), Dropped: 1}
+	return true
+}
+
+func (d *Dispatcher) recovered(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if f, ok := d.failing[name]; ok {
+		log.Info().Str("notifier", name).Int("dropped", f.Dropped).Msg("Notifications delivered again")
+		delete(d.failing, name)
+	}
+}
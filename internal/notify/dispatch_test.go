@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeNotifier struct {
+	name  string
+	fails int // attempts left that fail
+
+	mu       sync.Mutex
+	attempts int
+	got      chan Message
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) Notify(ctx context.Context, m Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.fails > 0 {
+		f.fails--
+		return errors.New("unreachable")
+	}
+	f.got <- m
+	return nil
+}
+
+type fakeChatNotifier struct{ *fakeNotifier }
+
+func (f fakeChatNotifier) Chats() []int64 { return nil }
+
+func receive(t *testing.T, f *fakeNotifier) Message {
+	t.Helper()
+	select {
+	case m := <-f.got:
+		return m
+	case <-time.After(time.Second):
+		t.Fatalf("%s got no message", f.name)
+		return Message{}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	chat := &fakeNotifier{name: "telegram", got: make(chan Message, 4)}
+	flaky := &fakeNotifier{name: "slack", fails: 3, got: make(chan Message, 4)}
+	d := &Dispatcher{notifiers: []Notifier{fakeChatNotifier{chat}, flaky}, retries: 1, backoff: time.Millisecond, failing: map[string]*Failure{}, lanes: map[string]*lane{}}
+
+	// Only the chat backend gets messages without Others
+	d.Dispatch(context.Background(), Message{Text: "chats only", Chats: []int64{1}})
+	if m := receive(t, chat); m.Text != "chats only" {
+		t.Errorf("telegram got %q", m.Text)
+	}
+
+	// Giving up after the retries is reported through the other backends, once
+	d.Dispatch(context.Background(), Message{Text: "alert", Chats: []int64{1}, Others: true})
+	receive(t, chat)
+	if m := receive(t, chat); m.Class != "info" || len(m.Chats) != 1 {
+		t.Errorf("failure notice = %+v", m)
+	}
+	if f := d.Failures(); len(f) != 1 || f[0].Backend != "slack" || f[0].Dropped != 1 {
+		t.Errorf("Failures() = %+v", f)
+	}
+
+	// The next delivery clears the failure
+	d.Dispatch(context.Background(), Message{Text: "report", Others: true})
+	if m := receive(t, flaky); m.Text != "report" {
+		t.Errorf("slack got %q", m.Text)
+	}
+	for deadline := time.Now().Add(time.Second); len(d.Failures()) > 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Failures() after delivery = %+v", d.Failures())
+		}
+	}
+	select {
+	case m := <-chat.got:
+		t.Errorf("telegram got %q for a message to the other backends", m.Text)
+	default:
+	}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	slow := &fakeNotifier{name: "slack", fails: 2, got: make(chan Message, 4)}
+	d := &Dispatcher{notifiers: []Notifier{slow}, retries: 3, backoff: time.Millisecond, failing: map[string]*Failure{}, lanes: map[string]*lane{}}
+
+	// A message retried by a backend still arrives before the ones dispatched after it
+	for _, text := range []string{"report", "chart", "recovery"} {
+		d.Dispatch(context.Background(), Message{Text: text, Others: true})
+	}
+	for _, want := range []string{"report", "chart", "recovery"} {
+		if m := receive(t, slow); m.Text != want {
+			t.Errorf("got %q, want %q", m.Text, want)
+		}
+	}
+}
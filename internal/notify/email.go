@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+)
+
+// smtpsPort is the port on which servers expect TLS right away instead of STARTTLS.
+const smtpsPort = 465
+
+// Email sends messages through an SMTP server, as HTML with a plain text alternative.
+type Email struct {
+	host     string
+	port     int
+	user     string
+	password string
+	from     string
+	to       []string
+}
+
+func NewEmail(cfg *config.Config) *Email {
+	return &Email{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		user:     cfg.SMTPUser,
+		password: cfg.SMTPPassword,
+		from:     cfg.SMTPFrom,
+		to:       cfg.SMTPTo,
+	}
+}
+
+func (e *Email) Name() string {
+	return "email"
+}
+
+func (e *Email) Notify(ctx context.Context, m Message) error {
+	msg, err := emailMessage(m, e.from, e.to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(e.host, strconv.Itoa(e.port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if e.port == smtpsPort {
+		conn = tls.Client(conn, &tls.Config{ServerName: e.host})
+	}
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && e.port != smtpsPort {
+		if err := c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	// PlainAuth refuses to send the password unencrypted, except to localhost
+	if e.user != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.user, e.password, e.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.from); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// emailMessage builds a multipart email with the first line of the message as its subject.
+// Telegram HTML is valid HTML, so it only needs its line breaks kept.
+func emailMessage(m Message, from string, to []string, now time.Time) ([]byte, error) {
+	title, _ := splitTitle(m.Text)
+	subject := plainText(title)
+	if subject == "" {
+		subject = "Tetra"
+	}
+	boundary, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", key, value) }
+	header("From", from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	if m.Critical {
+		header("X-Priority", "1")
+	}
+	buf.WriteString("\r\n")
+
+	page := `<!DOCTYPE html><html><body><div style="font-family:sans-serif;white-space:pre-wrap">` +
+		strings.TrimSpace(m.Text) + "</div></body></html>"
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", plainText(m.Text)},
+		{"text/html", page},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: %s; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", boundary, part.contentType)
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
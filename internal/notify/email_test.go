@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailMessage(t *testing.T) {
+	raw, err := emailMessage(Message{Class: "report", Text: "📊 <b>Daily report</b>\nTests: 48 &amp; alerts: 2"}, "tetra@example.com", []string{"a@example.com", "b@example.com"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "📊 Daily report" {
+		t.Errorf("Subject = %q", subject)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for _, want := range []string{"Tests: 48 & alerts: 2", "Tests: 48 &amp; alerts: 2</div>"} {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if !strings.Contains(string(body), want) {
+			t.Errorf("%s part = %q, want it to contain %q", part.Header.Get("Content-Type"), body, want)
+		}
+	}
+}
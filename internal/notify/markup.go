@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// tagPattern matches the tags Telegram HTML messages use.
+var tagPattern = regexp.MustCompile(`<(/?)([a-z-]+)(?:\s+href="([^"]*)")?[^>]*>`)
+
+// markup describes how a backend formats what Telegram HTML expresses with tags.
+type markup struct {
+	bold, italic, code, pre string // put around the text of the tag
+	link                    func(url, text string) string
+	escape                  func(text string) string
+}
+
+// convert rewrites Telegram HTML in the given markup. Unknown tags are dropped, keeping their text.
+func (m markup) convert(s string) string {
+	var sb strings.Builder
+	var linkURL string
+	var linkText strings.Builder
+	inLink := false
+	write := func(text string) {
+		if inLink {
+			linkText.WriteString(text)
+		} else {
+			sb.WriteString(text)
+		}
+	}
+
+	rest := s
+	for {
+		loc := tagPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			write(m.escape(html.UnescapeString(rest)))
+			break
+		}
+		write(m.escape(html.UnescapeString(rest[:loc[0]])))
+		closing, name := rest[loc[2]:loc[3]] == "/", rest[loc[4]:loc[5]]
+		switch name {
+		case "b", "strong":
+			write(m.bold)
+		case "i", "em":
+			write(m.italic)
+		case "code":
+			write(m.code)
+		case "pre":
+			write(m.pre)
+		case "a":
+			if !closing && loc[6] >= 0 {
+				linkURL, inLink = html.UnescapeString(rest[loc[6]:loc[7]]), true
+				linkText.Reset()
+			} else if closing && inLink {
+				inLink = false
+				sb.WriteString(m.link(linkURL, linkText.String()))
+			}
+		}
+		rest = rest[loc[1]:]
+	}
+	return sb.String()
+}
+
+// plainText strips the markup of Telegram HTML.
+func plainText(s string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(s, ""))
+}
+
+// splitTitle separates the first line of a message, its heading in most cases, from the rest.
+func splitTitle(s string) (title, body string) {
+	title, body, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return title, strings.TrimSpace(body)
+}
+
+// chunks splits text at line breaks into pieces of at most limit bytes, for APIs that cap the
+// length of a text field. Single lines longer than limit are cut.
+func chunks(text string, limit int) []string {
+	var out []string
+	for len(text) > limit {
+		cut := strings.LastIndex(text[:limit], "\n")
+		if cut <= 0 {
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		out = append(out, text[:cut])
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" {
+		out = append(out, text)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackMarkup(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"<b>Down</b> 12.5 &lt; 50 Mbps", "*Down* 12.5 &lt; 50 Mbps"},
+		{`<i>note</i> <code>1.1.1.1</code>`, "_note_ `1.1.1.1`"},
+		{`<a href="https://example.com/?a=1&amp;b=2">result</a>`, "<https://example.com/?a=1&b=2|result>"},
+		{"<tg-spoiler>kept</tg-spoiler> A &amp; B", "kept A &amp; B"},
+	} {
+		if got := slackMarkup.convert(tc.in); got != tc.want {
+			t.Errorf("convert(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDiscordMessage(t *testing.T) {
+	msg := discordMessage(Message{Class: "alert", Text: "⚠️ <b>Slow internet</b>\nDL <b>12.5</b> Mbps, a_b"}, time.Now())
+	embeds := msg["embeds"].([]map[string]any)
+	if len(embeds) != 1 || embeds[0]["title"] != "⚠️ Slow internet" || embeds[0]["color"] != discordColors["alert"] {
+		t.Fatalf("embeds = %v", embeds)
+	}
+	if got := embeds[0]["description"]; got != `DL **12.5** Mbps, a\_b` {
+		t.Errorf("description = %q", got)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	got := chunks("one\ntwo\nthree", 8)
+	if strings.Join(got, "|") != "one\ntwo|three" {
+		t.Errorf("chunks() = %q", got)
+	}
+	for _, c := range chunks(strings.Repeat("ї", 10), 5) {
+		if len(c) > 5 || !strings.HasPrefix(c, "ї") {
+			t.Errorf("chunks() cut a rune: %q", c)
+		}
+	}
+}
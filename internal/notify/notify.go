@@ -0,0 +1,54 @@
+// Package notify delivers the bot's alerts and reports to Telegram and the other configured
+// services. Messages are written as Telegram HTML, so each backend converts them to its own markup.
+package notify
+
+import (
+	"context"
+
+	"github.com/ckayt/tetra/internal/config"
+)
+
+// Message is an alert, notice or report.
+type Message struct {
+	Class    string // message class, e.g. "alert" or "report", see config.MessageClasses
+	Text     string // Telegram HTML
+	Critical bool   // a lost connection or failed test, which mentions ALERT_MENTIONS on Telegram
+	Pin      bool   // pin in the Telegram chats, replacing the previous pin
+	// Condition an alert or its recovery is about, e.g. "outage". Acknowledging an alert on
+	// Telegram only holds back repeats of the same condition, until it recovers.
+	Alert string
+
+	Chats  []int64 // Telegram chats the message goes to, see ChatNotifier
+	Others bool    // whether the backends without chats get it
+}
+
+// Notifier is a notification backend.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, m Message) error
+}
+
+// ChatNotifier is a backend with chats of its own, the Telegram bot. It gets the messages
+// for its chats in Message.Chats, while the other backends get those for Others.
+type ChatNotifier interface {
+	Notifier
+	Chats() []int64
+}
+
+// FromConfig builds all notifiers besides Telegram enabled in config.
+func FromConfig(cfg *config.Config) []Notifier {
+	var notifiers []Notifier
+	if cfg.NotifiesVia("slack") {
+		notifiers = append(notifiers, NewSlack(cfg))
+	}
+	if cfg.NotifiesVia("discord") {
+		notifiers = append(notifiers, NewDiscord(cfg))
+	}
+	if cfg.NotifiesVia("email") {
+		notifiers = append(notifiers, NewEmail(cfg))
+	}
+	if cfg.NotifiesVia("pushover") {
+		notifiers = append(notifiers, NewPushover(cfg))
+	}
+	return notifiers
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+const pushoverAPI = "https://api.pushover.net/1/"
+
+// Message limits
+const (
+	pushoverTitleLimit   = 250
+	pushoverMessageLimit = 1024
+)
+
+// pushoverEmergency is the priority Pushover repeats until the user acknowledges it.
+const pushoverEmergency = 2
+
+// pushoverTags are the Telegram HTML tags Pushover renders as well.
+var pushoverTags = map[string]bool{"b": true, "i": true, "u": true, "a": true}
+
+// Pushover sends push notifications through the Pushover API.
+type Pushover struct {
+	client     *http.Client
+	token      string
+	user       string
+	priorities map[string]int
+	critical   int
+	retry      time.Duration
+	expire     time.Duration
+
+	mu       sync.Mutex
+	receipts map[string][]string // emergency notifications still repeating, by Message.Alert
+}
+
+func NewPushover(cfg *config.Config) *Pushover {
+	return &Pushover{
+		client:     &http.Client{Timeout: 15 * time.Second},
+		token:      cfg.PushoverToken,
+		user:       cfg.PushoverUser,
+		priorities: cfg.PushoverPriority,
+		critical:   cfg.PushoverCritical,
+		retry:      cfg.PushoverRetry,
+		expire:     cfg.PushoverExpire,
+		receipts:   make(map[string][]string),
+	}
+}
+
+func (p *Pushover) Name() string {
+	return "pushover"
+}
+
+// Notify sends the message with the priority of its class. Critical alerts, such as a lost
+// connection, get PUSHOVER_CRITICAL_PRIORITY, and a recovery stops the emergency alerts about
+// the same condition repeating.
+func (p *Pushover) Notify(ctx context.Context, m Message) error {
+	form := p.form(m)
+	if m.Class == "recovery" {
+		p.cancelEmergencies(ctx, m.Alert)
+	}
+
+	var result struct {
+		Receipt string `json:"receipt"`
+	}
+	if err := p.post(ctx, "messages.json", form, &result); err != nil {
+		return err
+	}
+	if result.Receipt != "" {
+		p.mu.Lock()
+		p.receipts[m.Alert] = append(p.receipts[m.Alert], result.Receipt)
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// form builds the API parameters of a message, its first line being the title.
+func (p *Pushover) form(m Message) url.Values {
+	priority, ok := p.priorities[m.Class]
+	if !ok {
+		priority = p.priorities["default"]
+	}
+	if m.Critical && m.Class == "alert" {
+		priority = p.critical
+	}
+
+	form := url.Values{"token": {p.token}, "user": {p.user}, "priority": {strconv.Itoa(priority)}}
+	if priority == pushoverEmergency {
+		form.Set("retry", strconv.Itoa(int(p.retry.Seconds())))
+		form.Set("expire", strconv.Itoa(int(p.expire.Seconds())))
+	}
+	title, body := splitTitle(m.Text)
+	if t := plainText(title); t != "" && len([]rune(t)) <= pushoverTitleLimit {
+		form.Set("title", t)
+	} else {
+		body = m.Text
+	}
+	if body == "" {
+		body = m.Text
+	}
+	// Markup can't be cut safely, so long messages are sent as plain text
+	text := tagPattern.ReplaceAllStringFunc(body, func(tag string) string {
+		if pushoverTags[tagPattern.FindStringSubmatch(tag)[2]] {
+			return tag
+		}
+		return ""
+	})
+	if len([]rune(text)) <= pushoverMessageLimit {
+		form.Set("html", "1")
+	} else if text = plainText(body); len([]rune(text)) > pushoverMessageLimit {
+		text = string([]rune(text)[:pushoverMessageLimit-1]) + "…"
+	}
+	form.Set("message", text)
+	return form
+}
+
+// cancelEmergencies stops the emergency notifications about an alert condition that are still repeating.
+func (p *Pushover) cancelEmergencies(ctx context.Context, alert string) {
+	p.mu.Lock()
+	receipts := p.receipts[alert]
+	delete(p.receipts, alert)
+	p.mu.Unlock()
+
+	for _, receipt := range receipts {
+		form := url.Values{"token": {p.token}}
+		if err := p.post(ctx, "receipts/"+url.PathEscape(receipt)+"/cancel.json", form, nil); err != nil {
+			log.Warn().Err(err).Str("receipt", receipt).Msg("Failed to cancel Pushover emergency notification")
+		}
+	}
+}
+
+func (p *Pushover) post(ctx context.Context, path string, form url.Values, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverAPI+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(body, &failure) == nil && len(failure.Errors) > 0 {
+			return fmt.Errorf("pushover returned %s: %s", resp.Status, strings.Join(failure.Errors, "; "))
+		}
+		return fmt.Errorf("pushover returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode pushover response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPushoverForm(t *testing.T) {
+	p := &Pushover{
+		priorities: map[string]int{"warning": -1, "default": 0},
+		critical:   2,
+		retry:      time.Minute,
+		expire:     time.Hour,
+	}
+
+	form := p.form(Message{Class: "alert", Text: "🔴 <b>Internet down</b>\n<code>2</code> tests failed", Critical: true})
+	if form.Get("priority") != "2" || form.Get("retry") != "60" || form.Get("expire") != "3600" {
+		t.Errorf("critical alert form = %v", form)
+	}
+	if form.Get("title") != "🔴 Internet down" || form.Get("message") != "2 tests failed" || form.Get("html") != "1" {
+		t.Errorf("critical alert form = %v", form)
+	}
+
+	form = p.form(Message{Class: "warning", Text: "⚠️ Slow\n" + strings.Repeat("<b>xxxxxx</b>", 200)})
+	if form.Get("priority") != "-1" || form.Get("retry") != "" || form.Get("html") != "" {
+		t.Errorf("long warning form = %v", form)
+	}
+	if n := len([]rune(form.Get("message"))); n != pushoverMessageLimit {
+		t.Errorf("long warning message has %d characters", n)
+	}
+}
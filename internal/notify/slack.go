@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+)
+
+// slackPostURL is the Web API method used with a bot token.
+const slackPostURL = "https://slack.com/api/chat.postMessage"
+
+// Block Kit limits
+const (
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+)
+
+// slackMarkup is Slack's mrkdwn, where only &, < and > need escaping.
+var slackMarkup = markup{
+	bold:   "*",
+	italic: "_",
+	code:   "`",
+	pre:    "```",
+	link:   func(url, text string) string { return "<" + url + "|" + text + ">" },
+	escape: strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace,
+}
+
+// Slack posts messages to a channel through an incoming webhook, or with a bot token when
+// no webhook is set.
+type Slack struct {
+	client     *http.Client
+	webhookURL string
+	token      string
+	channel    string
+}
+
+func NewSlack(cfg *config.Config) *Slack {
+	return &Slack{
+		client:     &http.Client{Timeout: 15 * time.Second},
+		webhookURL: cfg.SlackWebhookURL,
+		token:      cfg.SlackBotToken,
+		channel:    cfg.SlackChannel,
+	}
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+func (s *Slack) Notify(ctx context.Context, m Message) error {
+	payload := slackMessage(m)
+	url := s.webhookURL
+	if url == "" {
+		payload["channel"] = s.channel
+		url = slackPostURL
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if s.webhookURL == "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	// The Web API reports errors in the body of a 200 response
+	if s.webhookURL == "" {
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return fmt.Errorf("failed to decode slack response: %w", err)
+		}
+		if !result.OK {
+			return fmt.Errorf("slack API error: %s", result.Error)
+		}
+	}
+	return nil
+}
+
+// slackMessage lays a message out in Block Kit: its first line as the header, the rest in
+// mrkdwn sections, like the bold heading and body of the Telegram message.
+func slackMessage(m Message) map[string]any {
+	title, body := splitTitle(m.Text)
+	var blocks []map[string]any
+	if header := plainText(title); header != "" && len([]rune(header)) <= slackHeaderLimit {
+		blocks = append(blocks, map[string]any{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": header, "emoji": true},
+		})
+	} else {
+		body = m.Text
+	}
+	for _, chunk := range chunks(slackMarkup.convert(body), slackSectionLimit) {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": chunk},
+		})
+	}
+	return map[string]any{
+		"text":   plainText(m.Text), // shown in notifications and by clients without blocks
+		"blocks": blocks,
+	}
+}
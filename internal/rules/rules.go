@@ -0,0 +1,231 @@
+// Package rules evaluates alert rules loaded from a YAML or JSON file against every scheduled
+// test result, such as "download < 50 for 2 tests in a row between 19 and 23 → critical to a
+// chat". When rules are configured they replace the comparison with the speed thresholds.
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities a rule can alert with, as stored with the result.
+const (
+	SeverityWarning  = stats.SeverityWarning
+	SeverityCritical = stats.SeverityCritical
+)
+
+var (
+	conditionPattern = regexp.MustCompile(`^(download|upload|ping|jitter)\s*(<=|>=|==|!=|<|>)\s*(\d+(?:\.\d+)?)$`)
+	hoursPattern     = regexp.MustCompile(`^(\d{1,2})\s*-\s*(\d{1,2})$`)
+)
+
+// Rule is one alert rule as written in the rules file.
+type Rule struct {
+	Name     string   `json:"name" yaml:"name"`
+	When     []string `json:"when" yaml:"when"`         // conditions that must all hold, e.g. "download < 50"
+	Hours    string   `json:"hours" yaml:"hours"`       // hours of the day it applies, e.g. "19-23" or "22-6"
+	For      int      `json:"for" yaml:"for"`           // tests in a row that must match, default 1
+	Severity string   `json:"severity" yaml:"severity"` // warning or critical, the default
+	Chats    []int64  `json:"chats" yaml:"chats"`       // chats alerted, default every chat
+	Message  string   `json:"message" yaml:"message"`   // extra line in the alert, Telegram HTML
+
+	conditions []condition
+	start, end int // hours, the end excluded; both zero when the rule applies all day
+}
+
+type condition struct {
+	metric string
+	op     string
+	value  float64
+}
+
+// Match is a rule that a result made alert, or that stopped alerting.
+type Match struct {
+	Rule   *Rule
+	Result stats.Result
+	Streak int      // matching tests in a row
+	Met    []string // the conditions with the measured values, e.g. "download 42.1 Mbps < 50"
+}
+
+// Engine holds the rules and how many tests in a row matched each of them, by result label.
+type Engine struct {
+	rules  []*Rule
+	mu     sync.Mutex
+	streak map[streakKey]int
+}
+
+type streakKey struct {
+	rule  int
+	label string
+}
+
+// Load reads the rules file, YAML unless its name ends in .json. An empty path means no rules.
+func Load(path string) (*Engine, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Rules []*Rule `json:"rules" yaml:"rules"`
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("no rules in %s", path)
+	}
+	for i, r := range file.Rules {
+		if err := r.parse(); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i+1, r.Name, err)
+		}
+	}
+	return &Engine{rules: file.Rules, streak: make(map[streakKey]int)}, nil
+}
+
+func (r *Rule) parse() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.When) == 0 {
+		return fmt.Errorf("when needs at least one condition")
+	}
+	for _, text := range r.When {
+		m := conditionPattern.FindStringSubmatch(strings.TrimSpace(text))
+		if m == nil {
+			return fmt.Errorf("invalid condition '%s': expected <download|upload|ping|jitter> <op> <number>", text)
+		}
+		value, _ := strconv.ParseFloat(m[3], 64)
+		r.conditions = append(r.conditions, condition{metric: m[1], op: m[2], value: value})
+	}
+	if r.Hours != "" {
+		m := hoursPattern.FindStringSubmatch(strings.TrimSpace(r.Hours))
+		if m == nil {
+			return fmt.Errorf("invalid hours '%s': expected e.g. 19-23", r.Hours)
+		}
+		r.start, _ = strconv.Atoi(m[1])
+		r.end, _ = strconv.Atoi(m[2])
+		if r.start > 23 || r.end > 24 || r.start == r.end {
+			return fmt.Errorf("invalid hours '%s'", r.Hours)
+		}
+	}
+	if r.For == 0 {
+		r.For = 1
+	}
+	if r.For < 0 {
+		return fmt.Errorf("for must be positive")
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityCritical
+	case SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("invalid severity '%s' (available: warning, critical)", r.Severity)
+	}
+	return nil
+}
+
+// Evaluate checks a scheduled test result taken at t, in the configured time zone, against
+// every rule. It returns the rules that alert for it and those that alerted for the previous
+// result of the same engine and no longer do. Failed tests and lite checks can't be compared,
+// so they leave the rules as they were.
+func (e *Engine) Evaluate(r stats.Result, t time.Time) (alerts, cleared []Match) {
+	if r.Error != nil || r.Lite {
+		return nil, nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, rule := range e.rules {
+		key := streakKey{i, r.Label()}
+		met, ok := rule.match(r, t)
+		if !ok {
+			if e.streak[key] >= rule.For {
+				cleared = append(cleared, Match{Rule: rule, Result: r})
+			}
+			delete(e.streak, key)
+			continue
+		}
+		e.streak[key]++
+		if streak := e.streak[key]; streak >= rule.For {
+			alerts = append(alerts, Match{Rule: rule, Result: r, Streak: streak, Met: met})
+		}
+	}
+	return alerts, cleared
+}
+
+// match reports whether the result meets every condition of the rule at t, and how.
+// Upload conditions are never met by a result that didn't measure upload.
+func (r *Rule) match(res stats.Result, t time.Time) ([]string, bool) {
+	if !r.applies(t.Hour()) {
+		return nil, false
+	}
+	var met []string
+	for _, c := range r.conditions {
+		var value float64
+		unit := "Mbps"
+		switch c.metric {
+		case "download":
+			value = res.Download
+		case "upload":
+			if res.NoUpload {
+				return nil, false
+			}
+			value = res.Upload
+		case "ping":
+			value, unit = float64(res.Ping)/float64(time.Millisecond), "ms"
+		case "jitter":
+			value, unit = float64(res.Jitter)/float64(time.Millisecond), "ms"
+		}
+		if !compare(value, c.op, c.value) {
+			return nil, false
+		}
+		met = append(met, fmt.Sprintf("%s %.1f %s %s %g", c.metric, value, unit, c.op, c.value))
+	}
+	return met, true
+}
+
+// applies reports whether the rule is in force during the given hour of the day.
+func (r *Rule) applies(hour int) bool {
+	switch {
+	case r.start == r.end:
+		return true
+	case r.start < r.end:
+		return hour >= r.start && hour < r.end
+	default:
+		return hour >= r.start || hour < r.end
+	}
+}
+
+func compare(a float64, op string, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "==":
+		return a == b
+	default:
+		return a != b
+	}
+}
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+func writeRules(t *testing.T, name, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEvaluate(t *testing.T) {
+	e, err := Load(writeRules(t, "rules.yaml", `
+rules:
+  - name: Evening slowdown
+    when: ["download < 50", "ping >= 20"]
+    hours: 19-23
+    for: 2
+    chats: [-100123]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evening := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	slow := stats.Result{Engine: "ookla", Download: 42, Ping: 25 * time.Millisecond}
+
+	if alerts, _ := e.Evaluate(slow, evening); len(alerts) != 0 {
+		t.Errorf("first match alerted: %+v", alerts)
+	}
+	// A failed test neither confirms nor resets the streak
+	e.Evaluate(stats.Result{Engine: "ookla", Error: os.ErrDeadlineExceeded}, evening)
+	alerts, _ := e.Evaluate(slow, evening)
+	if len(alerts) != 1 || alerts[0].Streak != 2 || alerts[0].Rule.Severity != SeverityCritical {
+		t.Fatalf("second match = %+v", alerts)
+	}
+	if got := alerts[0].Met; len(got) != 2 || got[0] != "download 42.0 Mbps < 50" {
+		t.Errorf("Met = %q", got)
+	}
+
+	// Outside the hours the rule doesn't apply, which ends its alert
+	if alerts, cleared := e.Evaluate(slow, evening.Add(3*time.Hour)); len(alerts) != 0 || len(cleared) != 1 {
+		t.Errorf("after hours: alerts %+v, cleared %+v", alerts, cleared)
+	}
+	if alerts, _ := e.Evaluate(slow, evening); len(alerts) != 0 {
+		t.Error("streak wasn't reset")
+	}
+}
+
+func TestUploadNotMeasured(t *testing.T) {
+	e, err := Load(writeRules(t, "rules.json", `{"rules": [{"name": "Slow upload", "when": ["upload < 50"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	if alerts, _ := e.Evaluate(stats.Result{Engine: "http", Download: 300, NoUpload: true}, now); len(alerts) != 0 {
+		t.Errorf("unmeasured upload alerted: %+v", alerts)
+	}
+	if alerts, _ := e.Evaluate(stats.Result{Engine: "ookla", Download: 300, Upload: 20}, now); len(alerts) != 1 {
+		t.Errorf("slow upload = %+v, want one alert", alerts)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	e, err := Load(writeRules(t, "rules.json", `{"rules": [{"name": "Night", "when": ["upload <= 5"], "hours": "22-6", "severity": "warning"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alerts, _ := e.Evaluate(stats.Result{Upload: 5}, time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)); len(alerts) != 1 {
+		t.Errorf("night rule didn't match at 03:00")
+	}
+
+	for _, text := range []string{
+		"rules: []",
+		"rules: [{name: a, when: ['download ~ 5']}]",
+		"rules: [{name: a, when: ['latency < 5']}]",
+		"rules: [{name: a, when: ['ping < 5'], hours: '7-7'}]",
+		"rules: [{name: a, when: ['ping < 5'], severity: loud}]",
+		"rules: [{when: ['ping < 5']}]",
+	} {
+		if _, err := Load(writeRules(t, "rules.yml", text)); err == nil {
+			t.Errorf("Load(%q) succeeded", text)
+		}
+	}
+}
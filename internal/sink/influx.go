@@ -0,0 +1,132 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// Influx writes results to InfluxDB using the line protocol over HTTP.
+// Both the v1 (/write) and v2 (/api/v2/write) APIs are supported.
+type Influx struct {
+	client      *http.Client
+	writeURL    string
+	token       string
+	user        string
+	password    string
+	measurement string
+}
+
+func NewInflux(cfg *config.Config) (*Influx, error) {
+	base, err := url.Parse(strings.TrimRight(cfg.InfluxURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INFLUX_URL: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("precision", "s")
+	switch cfg.InfluxVersion {
+	case 1:
+		if cfg.InfluxDatabase == "" {
+			return nil, fmt.Errorf("INFLUX_DB is required for InfluxDB v1")
+		}
+		base.Path += "/write"
+		q.Set("db", cfg.InfluxDatabase)
+	case 2:
+		if cfg.InfluxOrg == "" || cfg.InfluxBucket == "" {
+			return nil, fmt.Errorf("INFLUX_ORG and INFLUX_BUCKET are required for InfluxDB v2")
+		}
+		base.Path += "/api/v2/write"
+		q.Set("org", cfg.InfluxOrg)
+		q.Set("bucket", cfg.InfluxBucket)
+	default:
+		return nil, fmt.Errorf("unsupported INFLUX_VERSION %d (expected 1 or 2)", cfg.InfluxVersion)
+	}
+	base.RawQuery = q.Encode()
+
+	return &Influx{
+		client:      &http.Client{Timeout: 15 * time.Second},
+		writeURL:    base.String(),
+		token:       cfg.InfluxToken,
+		user:        cfg.InfluxUser,
+		password:    cfg.InfluxPassword,
+		measurement: cfg.InfluxMeasurement,
+	}, nil
+}
+
+func (i *Influx) Name() string {
+	return "influxdb"
+}
+
+func (i *Influx) Write(ctx context.Context, r stats.Result) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.writeURL, strings.NewReader(i.line(r)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if i.token != "" {
+		req.Header.Set("Authorization", "Token "+i.token)
+	} else if i.user != "" {
+		req.SetBasicAuth(i.user, i.password)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("influx write failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx write returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// line formats a result as a single line-protocol point.
+func (i *Influx) line(r stats.Result) string {
+	var fields []string
+	if r.Error != nil {
+		fields = append(fields, "failed=true", "error="+quoteFieldString(r.Error.Error()))
+	} else {
+		fields = append(fields,
+			"failed=false",
+			fmt.Sprintf("download=%f", r.Download),
+			fmt.Sprintf("ping_ms=%d", r.Ping.Milliseconds()),
+			fmt.Sprintf("jitter_ms=%d", r.Jitter.Milliseconds()),
+		)
+		if !r.NoUpload {
+			fields = append(fields, fmt.Sprintf("upload=%f", r.Upload))
+		}
+	}
+	fields = append(fields, fmt.Sprintf("alert=%t", r.AlertSent))
+
+	series := escapeKey(i.measurement)
+	if r.Engine != "" {
+		series += ",engine=" + escapeKey(r.Engine)
+	}
+	if r.IPVersion != "" {
+		series += ",ip_version=" + escapeKey(r.IPVersion)
+	}
+	if r.Interface != "" {
+		series += ",interface=" + escapeKey(r.Interface)
+	}
+	return fmt.Sprintf("%s %s %d\n", series, strings.Join(fields, ","), r.Time.Unix())
+}
+
+// escapeKey escapes measurement names and tag values.
+func escapeKey(s string) string {
+	return strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`).Replace(s)
+}
+
+// quoteFieldString quotes a string field value; line protocol only escapes quotes and backslashes.
+func quoteFieldString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,183 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// RemoteWrite pushes results to a Prometheus remote_write endpoint
+// (Prometheus, VictoriaMetrics, Mimir, ...), so no scraper is needed on the bot host.
+type RemoteWrite struct {
+	client   *http.Client
+	url      string
+	user     string
+	password string
+	token    string
+	labels   map[string]string
+
+	mu       sync.Mutex
+	counters map[string]*counters // per Result.Label
+}
+
+type counters struct {
+	tests    uint64
+	failures uint64
+	alerts   uint64
+}
+
+type sample struct {
+	name  string
+	value float64
+}
+
+func NewRemoteWrite(cfg *config.Config) *RemoteWrite {
+	instance := cfg.RemoteWriteInstance
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	return &RemoteWrite{
+		client:   &http.Client{Timeout: 15 * time.Second},
+		url:      cfg.RemoteWriteURL,
+		user:     cfg.RemoteWriteUser,
+		password: cfg.RemoteWritePassword,
+		token:    cfg.RemoteWriteToken,
+		labels: map[string]string{
+			"job":      cfg.RemoteWriteJob,
+			"instance": instance,
+		},
+		counters: make(map[string]*counters),
+	}
+}
+
+func (rw *RemoteWrite) Name() string {
+	return "remote_write"
+}
+
+func (rw *RemoteWrite) Write(ctx context.Context, r stats.Result) error {
+	rw.mu.Lock()
+	c, ok := rw.counters[r.Label()]
+	if !ok {
+		c = &counters{}
+		rw.counters[r.Label()] = c
+	}
+	c.tests++
+	if r.Error != nil {
+		c.failures++
+	}
+	if r.AlertSent {
+		c.alerts++
+	}
+	samples := []sample{
+		{"tetra_tests_total", float64(c.tests)},
+		{"tetra_test_failures_total", float64(c.failures)},
+		{"tetra_alerts_total", float64(c.alerts)},
+	}
+	rw.mu.Unlock()
+
+	if r.Error == nil {
+		samples = append(samples,
+			sample{"tetra_download_mbps", r.Download},
+			sample{"tetra_ping_seconds", r.Ping.Seconds()},
+			sample{"tetra_jitter_seconds", r.Jitter.Seconds()},
+		)
+		if !r.NoUpload {
+			samples = append(samples, sample{"tetra_upload_mbps", r.Upload})
+		}
+	}
+
+	body := snappy.Encode(nil, rw.encode(samples, r))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rw.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if rw.token != "" {
+		req.Header.Set("Authorization", "Bearer "+rw.token)
+	} else if rw.user != "" {
+		req.SetBasicAuth(rw.user, rw.password)
+	}
+
+	resp, err := rw.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote write failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote write returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// encode builds a prometheus.WriteRequest protobuf message by hand.
+// The schema is tiny and stable, which saves pulling in the whole Prometheus module:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func (rw *RemoteWrite) encode(samples []sample, r stats.Result) []byte {
+	var out []byte
+	for _, s := range samples {
+		labels := map[string]string{"__name__": s.name}
+		for k, v := range rw.labels {
+			labels[k] = v
+		}
+		if r.Engine != "" {
+			labels["engine"] = r.Engine
+		}
+		if r.IPVersion != "" {
+			labels["ip_version"] = r.IPVersion
+		}
+		if r.Interface != "" {
+			labels["interface"] = r.Interface
+		}
+		names := make([]string, 0, len(labels))
+		for k := range labels {
+			names = append(names, k)
+		}
+		sort.Strings(names) // remote_write requires labels sorted by name
+
+		var series []byte
+		for _, name := range names {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, labels[name])
+
+			series = protowire.AppendTag(series, 1, protowire.BytesType)
+			series = protowire.AppendBytes(series, label)
+		}
+
+		var smp []byte
+		smp = protowire.AppendTag(smp, 1, protowire.Fixed64Type)
+		smp = protowire.AppendFixed64(smp, math.Float64bits(s.value))
+		smp = protowire.AppendTag(smp, 2, protowire.VarintType)
+		smp = protowire.AppendVarint(smp, uint64(r.Time.UnixMilli()))
+
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, smp)
+
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, series)
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package sink
+
+import (
+	"context"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// Sink receives every recorded result, e.g. to forward it to a metrics system.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, r stats.Result) error
+}
+
+// FromConfig builds all sinks enabled in config.
+func FromConfig(cfg *config.Config) ([]Sink, error) {
+	var sinks []Sink
+	if cfg.InfluxURL != "" {
+		s, err := NewInflux(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if cfg.RemoteWriteURL != "" {
+		sinks = append(sinks, NewRemoteWrite(cfg))
+	}
+	return sinks, nil
+}
+
+// WriteAll sends the result to every sink, logging failures instead of returning them
+// so one unreachable backend doesn't affect the others.
+func WriteAll(ctx context.Context, sinks []Sink, r stats.Result) {
+	for _, s := range sinks {
+		writeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := s.Write(writeCtx, r); err != nil {
+			log.Error().Err(err).Str("sink", s.Name()).Msg("Failed to write result to sink")
+		}
+		cancel()
+	}
+}
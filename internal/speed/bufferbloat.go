@@ -0,0 +1,59 @@
+package speed
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// loadedPingInterval is the pause between latency probes during a load phase.
+const loadedPingInterval = 500 * time.Millisecond
+
+type latencyProbe func(ctx context.Context) (time.Duration, error)
+
+// sampleLatency probes latency in the background while a download or upload phase runs.
+// The returned stop function ends sampling and returns the median latency under load,
+// or zero if no usable samples were taken. The first sample pays for connection setup
+// and is discarded.
+func sampleLatency(ctx context.Context, probe latencyProbe) (stop func() time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+	var (
+		wg      sync.WaitGroup
+		samples []time.Duration
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			if d, err := probe(ctx); err == nil && ctx.Err() == nil {
+				samples = append(samples, d)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(loadedPingInterval):
+			}
+		}
+	}()
+
+	return func() time.Duration {
+		cancel()
+		wg.Wait()
+		if len(samples) < 2 {
+			return 0
+		}
+		return medianDuration(samples[1:])
+	}
+}
+
+// httpLatencyProbe times newReq on a dedicated connection, so probes don't queue
+// behind the load streams or pay for a new handshake every time.
+func httpLatencyProbe(network Network, newReq requestFunc) latencyProbe {
+	client := &http.Client{
+		Transport: network.transport(),
+		Timeout:   5 * time.Second,
+	}
+	return func(ctx context.Context) (time.Duration, error) {
+		return timeRequest(ctx, client, newReq)
+	}
+}
@@ -0,0 +1,84 @@
+package speed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+const (
+	cloudflareBaseURL      = "https://speed.cloudflare.com"
+	cloudflareDownloadSize = 25_000_000
+	cloudflareUploadSize   = 10_000_000
+)
+
+// CloudflareEngine measures against speed.cloudflare.com, which is served from
+// Cloudflare's anycast edge and is a good alternative when nearby Ookla servers are flaky.
+type CloudflareEngine struct {
+	client  *http.Client
+	network Network
+}
+
+func NewCloudflareEngine(network Network) *CloudflareEngine {
+	return &CloudflareEngine{
+		client:  network.httpClient(),
+		network: network,
+	}
+}
+
+func (e *CloudflareEngine) Name() string {
+	return EngineCloudflare
+}
+
+func (e *CloudflareEngine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time:   time.Now(),
+		Server: urlHost(cloudflareBaseURL),
+	}
+
+	// Ping
+	reportProgress(ctx, PhasePing)
+	pingReq := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, cloudflareBaseURL+"/__down?bytes=0", nil)
+	}
+	samples, err := measureLatency(ctx, e.client, pingReq, defaultPingCount)
+	if err != nil {
+		return res, fmt.Errorf("ping test failed: %w", err)
+	}
+	res.Ping = medianDuration(samples)
+	res.Jitter = jitterDuration(samples)
+
+	// Download, probing latency under load to detect bufferbloat
+	reportProgress(ctx, PhaseDownload)
+	stop := sampleLatency(ctx, httpLatencyProbe(e.network, pingReq))
+	res.Download, res.BytesReceived, err = measureDownload(ctx, e.client, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/__down?bytes=%d", cloudflareBaseURL, cloudflareDownloadSize), nil)
+	}, defaultStreams, defaultPhaseDuration)
+	res.LoadedPingDown = stop()
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+
+	// Upload
+	reportProgress(ctx, PhaseUpload)
+	stop = sampleLatency(ctx, httpLatencyProbe(e.network, pingReq))
+	res.Upload, res.BytesSent, err = measureUpload(ctx, e.client, func(ctx context.Context, body io.Reader, size int64) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, cloudflareBaseURL+"/__up", body)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	}, cloudflareUploadSize, defaultStreams, defaultPhaseDuration)
+	res.LoadedPingUp = stop()
+	if err != nil {
+		return res, fmt.Errorf("upload test failed: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,211 @@
+package speed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Helpers shared by the HTTP-based engines: parallel streams transfer data
+// until a deadline and throughput is computed from the bytes actually moved.
+
+const (
+	defaultStreams       = 4
+	defaultPhaseDuration = 10 * time.Second
+	defaultPingCount     = 10
+)
+
+type requestFunc func(ctx context.Context) (*http.Request, error)
+
+// measureLatency issues count small requests sequentially and returns the round-trip times.
+// The first request is a warm-up so connection setup doesn't skew the samples.
+func measureLatency(ctx context.Context, client *http.Client, newReq requestFunc, count int) ([]time.Duration, error) {
+	var samples []time.Duration
+	for i := 0; i <= count; i++ {
+		d, err := timeRequest(ctx, client, newReq)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			samples = append(samples, d)
+		}
+	}
+	return samples, nil
+}
+
+// timeRequest returns the round-trip time of a single request.
+func timeRequest(ctx context.Context, client *http.Client, newReq requestFunc) (time.Duration, error) {
+	req, err := newReq(ctx)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return 0, fmt.Errorf("latency probe returned %s", resp.Status)
+	}
+	return time.Since(start), nil
+}
+
+// medianDuration returns the median of samples, which is robust against single slow probes.
+func medianDuration(samples []time.Duration) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// jitterDuration returns the mean absolute difference between consecutive samples,
+// the same definition speedtest.net and most VoIP tools use.
+func jitterDuration(samples []time.Duration) time.Duration {
+	if len(samples) < 2 {
+		return 0
+	}
+	var sum time.Duration
+	for i := 1; i < len(samples); i++ {
+		d := samples[i] - samples[i-1]
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return sum / time.Duration(len(samples)-1)
+}
+
+// measureDownload runs parallel GET streams for duration and returns Mbps and bytes received.
+func measureDownload(ctx context.Context, client *http.Client, newReq requestFunc, streams int, duration time.Duration) (float64, uint64, error) {
+	var total atomic.Uint64
+	err := runStreams(ctx, streams, duration, func(ctx context.Context) error {
+		req, err := newReq(ctx)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("download returned %s", resp.Status)
+		}
+		_, err = io.Copy(countingWriter{&total}, resp.Body)
+		return err
+	})
+	if err != nil {
+		return 0, total.Load(), err
+	}
+	return toMbps(total.Load(), duration), total.Load(), nil
+}
+
+// measureUpload runs parallel POST streams of size bytes for duration and returns Mbps and bytes sent.
+func measureUpload(ctx context.Context, client *http.Client, newReq func(ctx context.Context, body io.Reader, size int64) (*http.Request, error), size int64, streams int, duration time.Duration) (float64, uint64, error) {
+	var total atomic.Uint64
+	err := runStreams(ctx, streams, duration, func(ctx context.Context) error {
+		body := &countingReader{r: io.LimitReader(zeroReader{}, size), n: &total}
+		req, err := newReq(ctx, body, size)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("upload returned %s", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, total.Load(), err
+	}
+	return toMbps(total.Load(), duration), total.Load(), nil
+}
+
+// runStreams calls transfer repeatedly from several goroutines until duration elapses.
+// Errors caused by the deadline are expected and ignored; any other error aborts the phase.
+func runStreams(ctx context.Context, streams int, duration time.Duration, transfer func(ctx context.Context) error) error {
+	phaseCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var firstErr error
+	var errOnce sync.Once
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for phaseCtx.Err() == nil {
+				if err := transfer(phaseCtx); err != nil && phaseCtx.Err() == nil {
+					errOnce.Do(func() { firstErr = err })
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if firstErr != nil && !errors.Is(firstErr, context.DeadlineExceeded) {
+		return firstErr
+	}
+	return nil
+}
+
+// urlHost returns the host name of rawURL, or an empty string if it can't be parsed.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func toMbps(bytes uint64, d time.Duration) float64 {
+	return float64(bytes) * 8 / d.Seconds() / 1e6
+}
+
+type countingWriter struct {
+	n *atomic.Uint64
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	w.n.Add(uint64(len(p)))
+	return len(p), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n *atomic.Uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(uint64(n))
+	return n, err
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,93 @@
+package speed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// HTTPProbeEngine downloads a configured URL and uploads a payload to a configured
+// endpoint, timing throughput itself. Use it to monitor the path to your own CDN or origin.
+type HTTPProbeEngine struct {
+	client      *http.Client
+	network     Network
+	downloadURL string
+	uploadURL   string
+	uploadSize  int64
+}
+
+func NewHTTPProbeEngine(cfg *config.Config, network Network) (*HTTPProbeEngine, error) {
+	if cfg.HTTPProbeDownloadURL == "" {
+		return nil, errors.New("HTTP_PROBE_DOWNLOAD_URL is required for the http engine")
+	}
+	return &HTTPProbeEngine{
+		client:      network.httpClient(),
+		network:     network,
+		downloadURL: cfg.HTTPProbeDownloadURL,
+		uploadURL:   cfg.HTTPProbeUploadURL,
+		uploadSize:  cfg.HTTPProbeUploadSize,
+	}, nil
+}
+
+func (e *HTTPProbeEngine) Name() string {
+	return EngineHTTP
+}
+
+func (e *HTTPProbeEngine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time:   time.Now(),
+		Server: urlHost(e.downloadURL),
+	}
+
+	// Ping: HEAD requests avoid transferring the body
+	reportProgress(ctx, PhasePing)
+	pingReq := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodHead, e.downloadURL, nil)
+	}
+	samples, err := measureLatency(ctx, e.client, pingReq, defaultPingCount)
+	if err != nil {
+		return res, fmt.Errorf("ping test failed: %w", err)
+	}
+	res.Ping = medianDuration(samples)
+	res.Jitter = jitterDuration(samples)
+
+	// Download, probing latency under load to detect bufferbloat
+	reportProgress(ctx, PhaseDownload)
+	stop := sampleLatency(ctx, httpLatencyProbe(e.network, pingReq))
+	res.Download, res.BytesReceived, err = measureDownload(ctx, e.client, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, e.downloadURL, nil)
+	}, defaultStreams, defaultPhaseDuration)
+	res.LoadedPingDown = stop()
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+
+	// Upload is optional, not every origin accepts POSTs
+	if e.uploadURL == "" {
+		res.NoUpload = true
+		return res, nil
+	}
+	reportProgress(ctx, PhaseUpload)
+	stop = sampleLatency(ctx, httpLatencyProbe(e.network, pingReq))
+	res.Upload, res.BytesSent, err = measureUpload(ctx, e.client, func(ctx context.Context, body io.Reader, size int64) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.uploadURL, body)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	}, e.uploadSize, defaultStreams, defaultPhaseDuration)
+	res.LoadedPingUp = stop()
+	if err != nil {
+		return res, fmt.Errorf("upload test failed: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,180 @@
+package speed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// Iperf3Engine runs the iperf3 client against a configured server, e.g. across a
+// site-to-site VPN. Upload is measured in normal mode and download with --reverse.
+type Iperf3Engine struct {
+	path      string
+	host      string
+	port      string
+	udp       bool
+	bandwidth string
+	duration  time.Duration
+	network   Network
+}
+
+// iperf3Report is the subset of `iperf3 --json` output we use.
+type iperf3Report struct {
+	Error string `json:"error"`
+	End   struct {
+		Streams []struct {
+			Sender struct {
+				MeanRTT int64 `json:"mean_rtt"` // microseconds, TCP on Linux only
+			} `json:"sender"`
+		} `json:"streams"`
+		SumSent     iperf3Sum `json:"sum_sent"`
+		SumReceived iperf3Sum `json:"sum_received"`
+		Sum         iperf3Sum `json:"sum"` // UDP
+	} `json:"end"`
+}
+
+type iperf3Sum struct {
+	Bytes         uint64  `json:"bytes"`
+	BitsPerSecond float64 `json:"bits_per_second"`
+	JitterMs      float64 `json:"jitter_ms"`
+	LostPercent   float64 `json:"lost_percent"`
+}
+
+func NewIperf3Engine(cfg *config.Config, network Network) (*Iperf3Engine, error) {
+	if cfg.Iperf3Server == "" {
+		return nil, errors.New("IPERF3_SERVER is required for the iperf3 engine")
+	}
+	resolved, err := exec.LookPath(cfg.Iperf3Path)
+	if err != nil {
+		return nil, fmt.Errorf("iperf3 binary not found: %w", err)
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Iperf3Server)
+	if err != nil {
+		host, port = cfg.Iperf3Server, "5201"
+	}
+
+	var udp bool
+	switch cfg.Iperf3Mode {
+	case "", "tcp":
+	case "udp":
+		udp = true
+	default:
+		return nil, fmt.Errorf("unsupported IPERF3_MODE '%s' (expected tcp or udp)", cfg.Iperf3Mode)
+	}
+
+	return &Iperf3Engine{
+		path:      resolved,
+		host:      host,
+		port:      port,
+		udp:       udp,
+		bandwidth: cfg.Iperf3Bandwidth,
+		duration:  cfg.Iperf3Duration,
+		network:   network,
+	}, nil
+}
+
+func (e *Iperf3Engine) Name() string {
+	return EngineIperf3
+}
+
+func (e *Iperf3Engine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time:   time.Now(),
+		Server: e.host,
+	}
+
+	// Download (server sends)
+	reportProgress(ctx, PhaseDownload)
+	down, err := e.run(ctx, true)
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+	// Upload (client sends)
+	reportProgress(ctx, PhaseUpload)
+	up, err := e.run(ctx, false)
+	if err != nil {
+		return res, fmt.Errorf("upload test failed: %w", err)
+	}
+
+	if e.udp {
+		res.Download = down.End.Sum.BitsPerSecond / 1e6
+		res.BytesReceived = down.End.Sum.Bytes
+		res.Upload = up.End.Sum.BitsPerSecond / 1e6
+		res.BytesSent = up.End.Sum.Bytes
+		// Jitter is measured by the receiver, which is us in reverse mode
+		res.Jitter = time.Duration(down.End.Sum.JitterMs * float64(time.Millisecond))
+	} else {
+		res.Download = down.End.SumReceived.BitsPerSecond / 1e6
+		res.BytesReceived = down.End.SumReceived.Bytes
+		res.Upload = up.End.SumReceived.BitsPerSecond / 1e6
+		res.BytesSent = up.End.SumSent.Bytes
+		// iperf3 has no ping phase; TCP's smoothed RTT from the upload run is the closest equivalent
+		if len(up.End.Streams) > 0 {
+			res.Ping = time.Duration(up.End.Streams[0].Sender.MeanRTT) * time.Microsecond
+		}
+	}
+
+	return res, nil
+}
+
+func (e *Iperf3Engine) run(ctx context.Context, reverse bool) (*iperf3Report, error) {
+	args := []string{
+		"--client", e.host,
+		"--port", e.port,
+		"--json",
+		"--time", strconv.Itoa(int(e.duration.Seconds())),
+	}
+	if reverse {
+		args = append(args, "--reverse")
+	}
+	if e.udp {
+		args = append(args, "--udp")
+	}
+	switch e.network.Family {
+	case FamilyIPv4:
+		args = append(args, "--version4")
+	case FamilyIPv6:
+		args = append(args, "--version6")
+	}
+	if ip := e.network.sourceIP(); ip != nil {
+		args = append(args, "--bind", ip.String())
+	} else if dev := e.network.device(); dev != "" {
+		args = append(args, "--bind-dev", dev) // iperf3 3.17+
+	}
+	if e.bandwidth != "" {
+		args = append(args, "--bitrate", e.bandwidth)
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, e.duration+30*time.Second)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(cmdCtx, e.path, args...)
+	cmd.Stdout = &stdout
+	runErr := cmd.Run()
+
+	var report iperf3Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("iperf3 failed: %w", runErr)
+		}
+		return nil, fmt.Errorf("failed to parse iperf3 output: %w", err)
+	}
+	if report.Error != "" {
+		return nil, fmt.Errorf("iperf3: %s", report.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("iperf3 failed: %w", runErr)
+	}
+	return &report, nil
+}
@@ -0,0 +1,100 @@
+package speed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+const (
+	libreSpeedChunks     = 100 // garbage.php chunks of 1 MiB per download request
+	libreSpeedUploadSize = 10_000_000
+)
+
+// LibreSpeedEngine measures against a (self-hosted) LibreSpeed server using its
+// standard backend endpoints: garbage.php for download and empty.php for upload and ping.
+type LibreSpeedEngine struct {
+	client  *http.Client
+	network Network
+	baseURL string
+}
+
+func NewLibreSpeedEngine(serverURL string, network Network) (*LibreSpeedEngine, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid LIBRESPEED_URL '%s'", serverURL)
+	}
+	return &LibreSpeedEngine{
+		client:  network.httpClient(),
+		network: network,
+		baseURL: strings.TrimRight(serverURL, "/"),
+	}, nil
+}
+
+func (e *LibreSpeedEngine) Name() string {
+	return EngineLibreSpeed
+}
+
+func (e *LibreSpeedEngine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time:   time.Now(),
+		Server: urlHost(e.baseURL),
+	}
+
+	// Ping
+	reportProgress(ctx, PhasePing)
+	pingReq := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, e.endpoint("empty.php"), nil)
+	}
+	samples, err := measureLatency(ctx, e.client, pingReq, defaultPingCount)
+	if err != nil {
+		return res, fmt.Errorf("ping test failed: %w", err)
+	}
+	res.Ping = medianDuration(samples)
+	res.Jitter = jitterDuration(samples)
+
+	// Download, probing latency under load to detect bufferbloat
+	reportProgress(ctx, PhaseDownload)
+	stop := sampleLatency(ctx, httpLatencyProbe(e.network, pingReq))
+	res.Download, res.BytesReceived, err = measureDownload(ctx, e.client, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, e.endpoint(fmt.Sprintf("garbage.php?ckSize=%d", libreSpeedChunks)), nil)
+	}, defaultStreams, defaultPhaseDuration)
+	res.LoadedPingDown = stop()
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+
+	// Upload
+	reportProgress(ctx, PhaseUpload)
+	stop = sampleLatency(ctx, httpLatencyProbe(e.network, pingReq))
+	res.Upload, res.BytesSent, err = measureUpload(ctx, e.client, func(ctx context.Context, body io.Reader, size int64) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint("empty.php"), body)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	}, libreSpeedUploadSize, defaultStreams, defaultPhaseDuration)
+	res.LoadedPingUp = stop()
+	if err != nil {
+		return res, fmt.Errorf("upload test failed: %w", err)
+	}
+
+	return res, nil
+}
+
+// endpoint builds a backend URL; the random parameter defeats caching proxies like LibreSpeed's own client does.
+func (e *LibreSpeedEngine) endpoint(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s/%s%sr=%d", e.baseURL, path, sep, time.Now().UnixNano())
+}
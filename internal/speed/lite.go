@@ -0,0 +1,72 @@
+package speed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// EngineLite names results of the lite check.
+const EngineLite = "lite"
+
+// LiteEngine is a cheap check for metered links: a few latency probes and one small
+// download, transferring about a megabyte instead of hundreds.
+type LiteEngine struct {
+	client *http.Client
+	url    string
+}
+
+func NewLiteEngine(url string, network Network) *LiteEngine {
+	return &LiteEngine{client: network.httpClient(), url: url}
+}
+
+func (e *LiteEngine) Name() string {
+	return EngineLite
+}
+
+func (e *LiteEngine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time:   time.Now(),
+		Server: urlHost(e.url),
+		Lite:   true,
+	}
+
+	// Ping: HEAD requests avoid transferring the body
+	reportProgress(ctx, PhasePing)
+	samples, err := measureLatency(ctx, e.client, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodHead, e.url, nil)
+	}, 5)
+	if err != nil {
+		return res, fmt.Errorf("ping test failed: %w", err)
+	}
+	res.Ping = medianDuration(samples)
+	res.Jitter = jitterDuration(samples)
+
+	// Single small download
+	reportProgress(ctx, PhaseDownload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url, nil)
+	if err != nil {
+		return res, err
+	}
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return res, fmt.Errorf("download returned %s", resp.Status)
+	}
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+	res.BytesReceived = uint64(n)
+	res.Download = toMbps(uint64(n), time.Since(start))
+
+	return res, nil
+}
@@ -0,0 +1,90 @@
+package speed
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// IP families accepted in IP_FAMILIES.
+const (
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+)
+
+// Network controls how engines connect to test servers.
+type Network struct {
+	Family    string // FamilyIPv4 or FamilyIPv6 pins the IP version; empty allows both
+	Interface string // local interface name or source IP to bind to; empty uses the routing table
+	Proxy     string // http://, https:// or socks5:// proxy URL for HTTP traffic; empty connects directly
+}
+
+// sourceIP returns the bind address if Interface is an IP rather than an interface name.
+func (n Network) sourceIP() net.IP {
+	return net.ParseIP(n.Interface)
+}
+
+// device returns the interface name to bind to, if Interface isn't an IP.
+func (n Network) device() string {
+	if n.Interface == "" || n.sourceIP() != nil {
+		return ""
+	}
+	return n.Interface
+}
+
+// dialer returns a dialer honoring the network settings.
+func (n Network) dialer() *net.Dialer {
+	d := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   n.control(),
+	}
+	if ip := n.sourceIP(); ip != nil {
+		d.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	return d
+}
+
+// control rejects sockets of the wrong IP family and binds them to the configured
+// interface. Go falls back to the server's next address on a rejected family, so
+// hosts with both A and AAAA records just work.
+func (n Network) control() func(network, address string, c syscall.RawConn) error {
+	if n.Family == "" && n.device() == "" {
+		return nil
+	}
+	suffix := "4"
+	if n.Family == FamilyIPv6 {
+		suffix = "6"
+	}
+	return func(network, address string, c syscall.RawConn) error {
+		if n.Family != "" && !strings.HasSuffix(network, suffix) {
+			return fmt.Errorf("%s connection to %s not allowed in %s mode", network, address, n.Family)
+		}
+		if dev := n.device(); dev != "" {
+			return bindToDevice(c, dev)
+		}
+		return nil
+	}
+}
+
+// transport returns a fresh HTTP transport using the network's dialer.
+func (n Network) transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = n.dialer().DialContext
+	if n.Proxy != "" {
+		// NewEngines validates the URL
+		if u, err := url.Parse(n.Proxy); err == nil {
+			t.Proxy = http.ProxyURL(u)
+		}
+	}
+	return t
+}
+
+// httpClient returns a client for the HTTP-based engines.
+func (n Network) httpClient() *http.Client {
+	return &http.Client{Transport: n.transport(), Timeout: time.Minute}
+}
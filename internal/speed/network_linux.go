@@ -0,0 +1,22 @@
+package speed
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToDevice pins a socket to an interface with SO_BINDTODEVICE, so traffic leaves
+// through that uplink regardless of the routing table. Needs CAP_NET_RAW.
+func bindToDevice(c syscall.RawConn, dev string) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, dev)
+	})
+	if err != nil {
+		return err
+	}
+	if sockErr != nil {
+		return fmt.Errorf("failed to bind to interface %s: %w", dev, sockErr)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package speed
+
+import (
+	"errors"
+	"syscall"
+)
+
+// bindToDevice is only implemented on Linux; elsewhere bind by source IP instead.
+func bindToDevice(c syscall.RawConn, dev string) error {
+	return errors.New("binding to an interface by name is only supported on Linux, use its IP address")
+}
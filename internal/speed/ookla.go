@@ -0,0 +1,201 @@
+package speed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/rs/zerolog/log"
+	"github.com/showwin/speedtest-go/speedtest"
+)
+
+// OoklaEngine measures against speedtest.net servers using speedtest-go.
+// The chosen server (and the user info needed to pick it) is cached for cacheTTL,
+// so tests start faster and survive transient hiccups of the server list API.
+type OoklaEngine struct {
+	cacheTTL time.Duration // zero disables caching
+	network  Network
+
+	mu       sync.Mutex
+	serverID string // pinned server, empty picks the closest one
+	client   *speedtest.Speedtest
+	server   *speedtest.Server
+	cachedAt time.Time
+}
+
+func NewOoklaEngine(serverID string, cacheTTL time.Duration, network Network) *OoklaEngine {
+	return &OoklaEngine{serverID: serverID, cacheTTL: cacheTTL, network: network}
+}
+
+func (e *OoklaEngine) Name() string {
+	return EngineOokla
+}
+
+func (e *OoklaEngine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time: time.Now(),
+	}
+
+	server, user, cached, err := e.getServer(ctx)
+	if err != nil {
+		return res, err
+	}
+	setOoklaServer(&res, server, user)
+
+	// Ping doubles as the health check of a cached server
+	reportProgress(ctx, PhasePing)
+	err = server.PingTestContext(ctx, nil)
+	if err != nil && cached {
+		log.Warn().Err(err).Str("server", server.Name).Msg("Cached speedtest server failed health check, refreshing")
+		e.invalidate()
+		if server, user, _, err = e.getServer(ctx); err != nil {
+			return res, err
+		}
+		setOoklaServer(&res, server, user)
+		err = server.PingTestContext(ctx, nil)
+	}
+	if err != nil {
+		return res, fmt.Errorf("ping test failed: %w", err)
+	}
+	res.Ping = server.Latency
+	res.Jitter = server.Jitter
+
+	// Download, probing latency under load to detect bufferbloat
+	reportProgress(ctx, PhaseDownload)
+	stop := sampleLatency(ctx, ooklaLatencyProbe(server))
+	err = server.DownloadTestContext(ctx)
+	res.LoadedPingDown = stop()
+	if err != nil {
+		return res, fmt.Errorf("download test failed: %w", err)
+	}
+	res.Download = server.DLSpeed.Mbps()
+	res.BytesReceived = uint64(server.Context.GetTotalDownload())
+
+	// Upload
+	reportProgress(ctx, PhaseUpload)
+	stop = sampleLatency(ctx, ooklaLatencyProbe(server))
+	err = server.UploadTestContext(ctx)
+	res.LoadedPingUp = stop()
+	if err != nil {
+		return res, fmt.Errorf("upload test failed: %w", err)
+	}
+	res.Upload = server.ULSpeed.Mbps()
+	res.BytesSent = uint64(server.Context.GetTotalUpload())
+
+	return res, nil
+}
+
+// setOoklaServer records the server a test ran against and, if known, the public IP and ISP it saw.
+func setOoklaServer(res *stats.Result, server *speedtest.Server, user *speedtest.User) {
+	res.Server, res.ServerID, res.ServerName = server.Host, server.ID, stats.ServerName(server.Sponsor, server.Name)
+	res.Meta = map[string]string{
+		"server_id":   server.ID,
+		"server_name": server.Name,
+		"sponsor":     server.Sponsor,
+		"country":     server.Country,
+		"distance_km": strconv.FormatFloat(server.Distance, 'f', 1, 64),
+	}
+	if user != nil {
+		res.Meta["public_ip"], res.Meta["isp"] = user.IP, user.Isp
+	}
+}
+
+// ooklaLatencyProbe uses the server's TCP echo, the same mechanism as Ookla's own loaded latency.
+func ooklaLatencyProbe(server *speedtest.Server) latencyProbe {
+	return func(ctx context.Context) (time.Duration, error) {
+		latencies, err := server.TCPPing(ctx, 1, 0, nil)
+		if err != nil {
+			return 0, err
+		}
+		if len(latencies) == 0 {
+			return 0, errors.New("no tcp ping reply")
+		}
+		return time.Duration(latencies[0]), nil
+	}
+}
+
+// getServer returns the cached server if still fresh, otherwise discovers one, along with the
+// user info of this test. The third return value reports whether the server came from the cache.
+func (e *OoklaEngine) getServer(ctx context.Context) (*speedtest.Server, *speedtest.User, bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.server != nil && e.cacheTTL > 0 && time.Since(e.cachedAt) < e.cacheTTL {
+		// Counters of the shared data manager accumulate across tests
+		e.client.Manager.Reset()
+		// The public IP may have changed since, but the test doesn't depend on it
+		user, err := e.client.FetchUserInfoContext(ctx)
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to refresh speedtest user info")
+		}
+		return e.server, user, true, nil
+	}
+
+	uc := &speedtest.UserConfig{DialerControl: e.network.control(), Proxy: e.network.Proxy}
+	if ip := e.network.sourceIP(); ip != nil {
+		uc.Source = ip.String()
+	}
+	// A dedicated doer keeps the settings from leaking into http.DefaultClient
+	client := speedtest.New(speedtest.WithDoer(&http.Client{}), speedtest.WithUserConfig(uc))
+
+	// Fetch user info
+	user, err := client.FetchUserInfoContext(ctx)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+
+	server, err := e.findServer(ctx, client)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	e.client, e.server, e.cachedAt = client, server, time.Now()
+	log.Debug().Str("server", server.Name).Str("id", server.ID).Msg("Selected speedtest server")
+	return server, user, false, nil
+}
+
+// PinServer switches to another server from the next test on.
+func (e *OoklaEngine) PinServer(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if id != e.serverID {
+		e.serverID = id
+		e.client, e.server = nil, nil
+	}
+}
+
+func (e *OoklaEngine) invalidate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client, e.server = nil, nil
+}
+
+// findServer returns the pinned server if configured, otherwise the closest one. Caller must hold the lock.
+func (e *OoklaEngine) findServer(ctx context.Context, client *speedtest.Speedtest) (*speedtest.Server, error) {
+	if e.serverID != "" {
+		server, err := client.FetchServerByIDContext(ctx, e.serverID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pinned server %s: %w", e.serverID, err)
+		}
+		return server, nil
+	}
+
+	// Fetch servers
+	serverList, err := client.FetchServerListContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch server list: %w", err)
+	}
+
+	// Find closest server
+	targets, err := serverList.FindServer([]int{})
+	if err != nil || len(targets) == 0 {
+		return nil, fmt.Errorf("failed to find server: %w", err)
+	}
+
+	return targets[0], nil // Pick the best one
+}
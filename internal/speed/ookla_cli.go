@@ -0,0 +1,93 @@
+package speed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// OoklaCLIEngine shells out to the official Ookla `speedtest` binary. Its results match
+// the Speedtest website more closely than speedtest-go, especially on gigabit links.
+type OoklaCLIEngine struct {
+	path    string
+	network Network
+
+	mu       sync.Mutex
+	serverID string
+}
+
+func NewOoklaCLIEngine(path, serverID string, network Network) (*OoklaCLIEngine, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("ookla speedtest binary not found: %w", err)
+	}
+	return &OoklaCLIEngine{path: resolved, serverID: serverID, network: network}, nil
+}
+
+// PinServer switches to another server from the next test on.
+func (e *OoklaCLIEngine) PinServer(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.serverID = id
+}
+
+func (e *OoklaCLIEngine) Name() string {
+	return EngineOoklaCLI
+}
+
+func (e *OoklaCLIEngine) Measure(ctx context.Context) (stats.Result, error) {
+	res := stats.Result{
+		Time: time.Now(),
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	args := []string{"--format=json", "--accept-license", "--accept-gdpr"}
+	e.mu.Lock()
+	serverID := e.serverID
+	e.mu.Unlock()
+	if serverID != "" {
+		args = append(args, "--server-id="+serverID)
+	}
+	if ip := e.network.sourceIP(); ip != nil {
+		args = append(args, "--ip="+ip.String())
+	} else if dev := e.network.device(); dev != "" {
+		args = append(args, "--interface="+dev)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(cmdCtx, e.path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	results, err := ParseOoklaJSON(stdout.Bytes())
+	if err != nil {
+		if runErr != nil {
+			return res, fmt.Errorf("speedtest cli failed: %w: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return res, fmt.Errorf("failed to parse speedtest cli output: %w", err)
+	}
+
+	// The CLI prints error log entries before exiting non-zero; report the most relevant one
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i].Error == nil {
+			return results[i], nil
+		}
+	}
+	if len(results) > 0 {
+		return res, fmt.Errorf("speedtest cli failed: %w", results[len(results)-1].Error)
+	}
+	if runErr != nil {
+		return res, fmt.Errorf("speedtest cli failed: %w: %s", runErr, strings.TrimSpace(stderr.String()))
+	}
+	return res, errors.New("speedtest cli produced no result")
+}
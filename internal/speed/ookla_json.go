@@ -0,0 +1,128 @@
+package speed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+// ooklaResult mirrors the relevant parts of `speedtest --format=json` output
+// from the official Ookla CLI. Bandwidth values are in bytes per second.
+type ooklaResult struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	Ping      struct {
+		Jitter  float64 `json:"jitter"`
+		Latency float64 `json:"latency"`
+	} `json:"ping"`
+	Download struct {
+		Bandwidth float64      `json:"bandwidth"`
+		Bytes     uint64       `json:"bytes"`
+		Latency   ooklaLatency `json:"latency"`
+	} `json:"download"`
+	Upload struct {
+		Bandwidth float64      `json:"bandwidth"`
+		Bytes     uint64       `json:"bytes"`
+		Latency   ooklaLatency `json:"latency"`
+	} `json:"upload"`
+	Server struct {
+		ID       int    `json:"id"`
+		Host     string `json:"host"`
+		Name     string `json:"name"`
+		Location string `json:"location"`
+		Country  string `json:"country"`
+	} `json:"server"`
+	ISP       string `json:"isp"`
+	Interface struct {
+		ExternalIP string `json:"externalIp"`
+	} `json:"interface"`
+	Result struct {
+		URL string `json:"url"` // share link to the result on speedtest.net
+	} `json:"result"`
+}
+
+// ooklaLatency is the latency measured during a load phase (recent CLI versions only).
+type ooklaLatency struct {
+	IQM float64 `json:"iqm"` // interquartile mean, ms
+}
+
+func (o ooklaResult) toResult() stats.Result {
+	if o.Type == "log" {
+		return stats.Result{Time: o.Timestamp, Error: errors.New(o.Message)}
+	}
+	r := stats.Result{
+		Time:           o.Timestamp,
+		Download:       o.Download.Bandwidth * 8 / 1e6,
+		Upload:         o.Upload.Bandwidth * 8 / 1e6,
+		Ping:           time.Duration(o.Ping.Latency * float64(time.Millisecond)),
+		Jitter:         time.Duration(o.Ping.Jitter * float64(time.Millisecond)),
+		LoadedPingDown: time.Duration(o.Download.Latency.IQM * float64(time.Millisecond)),
+		LoadedPingUp:   time.Duration(o.Upload.Latency.IQM * float64(time.Millisecond)),
+		BytesReceived:  o.Download.Bytes,
+		BytesSent:      o.Upload.Bytes,
+		Server:         o.Server.Host,
+		ServerName:     stats.ServerName(o.Server.Name, o.Server.Location),
+		ShareURL:       o.Result.URL,
+		Meta: map[string]string{
+			"server_id":   strconv.Itoa(o.Server.ID),
+			"server_name": o.Server.Location,
+			"sponsor":     o.Server.Name,
+			"country":     o.Server.Country,
+			"isp":         o.ISP,
+			"public_ip":   o.Interface.ExternalIP,
+		},
+	}
+	if o.Server.ID != 0 {
+		r.ServerID = strconv.Itoa(o.Server.ID)
+	}
+	return r
+}
+
+// ParseOoklaJSON parses Ookla CLI JSON output. The input may be a single object,
+// a JSON array, or several concatenated objects (e.g. a cron job appending to one file).
+// Error log entries become failed results; other non-result entries are skipped.
+func ParseOoklaJSON(data []byte) ([]stats.Result, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var entries []ooklaResult
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("invalid ookla json array: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var e ooklaResult
+			err := dec.Decode(&e)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid ookla json: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	var results []stats.Result
+	for _, e := range entries {
+		if e.Timestamp.IsZero() {
+			continue
+		}
+		if e.Type == "result" || (e.Type == "log" && e.Level == "error") {
+			results = append(results, e.toResult())
+		}
+	}
+	return results, nil
+}
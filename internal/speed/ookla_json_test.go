@@ -0,0 +1,50 @@
+package speed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOoklaJSON(t *testing.T) {
+	data := []byte(`
+{"type":"result","timestamp":"2024-05-01T10:00:00Z","ping":{"jitter":1.2,"latency":12.5},"download":{"bandwidth":12500000,"bytes":100,"latency":{"iqm":52.5}},"upload":{"bandwidth":2500000,"bytes":50},"server":{"id":1234,"host":"speed.example.net:8080","name":"Kyivstar","location":"Kyiv"},"result":{"id":"abc","url":"https://www.speedtest.net/result/c/abc"}}
+{"type":"log","timestamp":"2024-05-01T10:30:00Z","level":"error","message":"Cannot open socket"}
+{"type":"log","timestamp":"2024-05-01T10:31:00Z","level":"info","message":"ignored"}
+`)
+
+	results, err := ParseOoklaJSON(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	r := results[0]
+	if r.Download != 100 || r.Upload != 20 {
+		t.Errorf("Expected 100/20 Mbps, got %.2f/%.2f", r.Download, r.Upload)
+	}
+	if r.Ping != 12500*time.Microsecond {
+		t.Errorf("Expected 12.5ms ping, got %v", r.Ping)
+	}
+	if r.BufferbloatGrade() != "B" {
+		t.Errorf("Expected bufferbloat grade B for +40ms under load, got %q", r.BufferbloatGrade())
+	}
+	if r.BytesReceived != 100 || r.BytesSent != 50 {
+		t.Errorf("Expected byte counts 100/50, got %d/%d", r.BytesReceived, r.BytesSent)
+	}
+	if r.ShareURL != "https://www.speedtest.net/result/c/abc" {
+		t.Errorf("Expected share URL from result.url, got %q", r.ShareURL)
+	}
+	if r.ServerID != "1234" || r.ServerLabel() != "Kyivstar, Kyiv" {
+		t.Errorf("Expected server 1234 Kyivstar, Kyiv, got %q %q", r.ServerID, r.ServerLabel())
+	}
+	if results[1].Error == nil {
+		t.Errorf("Expected error log entry to become a failed result")
+	}
+
+	array, err := ParseOoklaJSON([]byte(`[{"type":"result","timestamp":"2024-05-01T10:00:00Z"}]`))
+	if err != nil || len(array) != 1 {
+		t.Errorf("Expected 1 result from array input, got %d (err %v)", len(array), err)
+	}
+}
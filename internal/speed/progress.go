@@ -0,0 +1,46 @@
+package speed
+
+import "context"
+
+// Phase is a step of a measurement, reported while a test is running.
+type Phase string
+
+const (
+	PhaseStart    Phase = "start" // engines without finer phases only report this one
+	PhasePing     Phase = "ping"
+	PhaseDownload Phase = "download"
+	PhaseUpload   Phase = "upload"
+)
+
+// ProgressFunc is called with the label of the running engine (see stats.Result.Label)
+// every time it enters a new phase.
+type ProgressFunc func(engine string, phase Phase)
+
+type progressKey struct{}
+
+type progress struct {
+	fn     ProgressFunc
+	engine string
+}
+
+// WithProgress returns a context that reports the phases of tests run with it to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress{fn: fn})
+}
+
+// withEngine tags the progress reports made through ctx with the engine's label.
+func withEngine(ctx context.Context, engine string) context.Context {
+	p, ok := ctx.Value(progressKey{}).(progress)
+	if !ok {
+		return ctx
+	}
+	p.engine = engine
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// reportProgress tells the listener of ctx, if any, that a phase has started.
+func reportProgress(ctx context.Context, phase Phase) {
+	if p, ok := ctx.Value(progressKey{}).(progress); ok {
+		p.fn(p.engine, phase)
+	}
+}
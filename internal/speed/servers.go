@@ -0,0 +1,65 @@
+package speed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/showwin/speedtest-go/speedtest"
+)
+
+// ServerInfo describes a speedtest.net server for display.
+type ServerInfo struct {
+	ID       string
+	Name     string // city
+	Sponsor  string
+	Country  string
+	Distance float64       // km
+	Latency  time.Duration // zero when the server didn't answer
+}
+
+// ListServers returns up to limit speedtest.net servers closest to this host, with their latency.
+func ListServers(ctx context.Context, network Network, limit int) ([]ServerInfo, error) {
+	uc := &speedtest.UserConfig{DialerControl: network.control(), Proxy: network.Proxy}
+	client := speedtest.New(speedtest.WithDoer(&http.Client{}), speedtest.WithUserConfig(uc))
+
+	// User info makes the distances relative to this host instead of Ookla's guess
+	if _, err := client.FetchUserInfoContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+	servers, err := client.FetchServerListContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch server list: %w", err)
+	}
+
+	var list []ServerInfo
+	for _, s := range servers {
+		if len(list) == limit {
+			break
+		}
+		info := ServerInfo{ID: s.ID, Name: s.Name, Sponsor: s.Sponsor, Country: s.Country, Distance: s.Distance}
+		if s.Latency != speedtest.PingTimeout {
+			info.Latency = s.Latency
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+// serverPinner is implemented by engines that test against a selectable speedtest.net server.
+type serverPinner interface {
+	PinServer(id string)
+}
+
+// PinServer switches every Ookla engine to the given server ID, or back to the closest server when empty.
+func (r *Runner) PinServer(id string) {
+	for _, engine := range r.engines {
+		if be, ok := engine.(boundEngine); ok {
+			engine = be.Engine
+		}
+		if p, ok := engine.(serverPinner); ok {
+			p.PinServer(id)
+		}
+	}
+}
@@ -2,23 +2,154 @@ package speed
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/ckayt/tetra/internal/config"
 	"github.com/ckayt/tetra/internal/stats"
 	"github.com/rs/zerolog/log"
-	"github.com/showwin/speedtest-go/speedtest"
 )
 
-type Runner struct{}
+const (
+	EngineOokla      = "ookla"
+	EngineCloudflare = "cloudflare"
+	EngineLibreSpeed = "librespeed"
+	EngineOoklaCLI   = "ookla-cli"
+	EngineIperf3     = "iperf3"
+	EngineHTTP       = "http"
+)
+
+// Engine performs a single measurement against one speed test provider.
+type Engine interface {
+	Name() string
+	Measure(ctx context.Context) (stats.Result, error)
+}
+
+// NewEngines returns the engines listed in SPEEDTEST_ENGINE, in order.
+// With BIND_INTERFACES or IP_FAMILIES set, every engine runs once per interface and IP family.
+func NewEngines(cfg *config.Config) ([]Engine, error) {
+	families := cfg.IPFamilies
+	if len(families) == 0 {
+		families = []string{""}
+	}
+	interfaces := cfg.BindInterfaces
+	if len(interfaces) == 0 {
+		interfaces = []string{""}
+	}
+
+	if cfg.SpeedtestProxy != "" {
+		if _, err := url.Parse(cfg.SpeedtestProxy); err != nil {
+			return nil, fmt.Errorf("invalid SPEEDTEST_PROXY: %w", err)
+		}
+	}
+
+	var engines []Engine
+	for _, name := range cfg.SpeedtestEngines {
+		for _, iface := range interfaces {
+			for _, family := range families {
+				switch family {
+				case "", FamilyIPv4, FamilyIPv6:
+				default:
+					return nil, fmt.Errorf("unsupported IP family '%s' (expected ipv4 or ipv6)", family)
+				}
+				network := Network{Family: family, Interface: iface, Proxy: cfg.SpeedtestProxy}
+				e, err := NewEngine(cfg, name, network)
+				if err != nil {
+					return nil, err
+				}
+				if family != "" || iface != "" {
+					e = boundEngine{Engine: e, network: network}
+				}
+				engines = append(engines, e)
+			}
+		}
+	}
+	return engines, nil
+}
 
-func NewRunner() *Runner {
-	return &Runner{}
+// NewEngine returns the engine with the given name.
+func NewEngine(cfg *config.Config, name string, network Network) (Engine, error) {
+	switch name {
+	case "", EngineOokla:
+		return NewOoklaEngine(cfg.SpeedtestServerID, cfg.SpeedtestServerCacheTTL, network), nil
+	case EngineCloudflare:
+		return NewCloudflareEngine(network), nil
+	case EngineLibreSpeed:
+		e, err := NewLibreSpeedEngine(cfg.LibreSpeedURL, network)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	case EngineOoklaCLI:
+		if network.Family != "" {
+			return nil, errors.New("the ookla-cli engine doesn't support IP_FAMILIES")
+		}
+		if network.Proxy != "" {
+			return nil, errors.New("the ookla-cli engine doesn't support SPEEDTEST_PROXY")
+		}
+		e, err := NewOoklaCLIEngine(cfg.OoklaCLIPath, cfg.SpeedtestServerID, network)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	case EngineIperf3:
+		if network.Proxy != "" {
+			return nil, errors.New("the iperf3 engine doesn't support SPEEDTEST_PROXY")
+		}
+		e, err := NewIperf3Engine(cfg, network)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	case EngineHTTP:
+		e, err := NewHTTPProbeEngine(cfg, network)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("unknown speed test engine '%s'", name)
+	}
+}
+
+// boundEngine marks an engine pinned to an interface or IP family, so the runner can tag its results.
+type boundEngine struct {
+	Engine
+	network Network
 }
 
-// Run executes the speedtest with retries.
-// Returns a stats.Result.
-func (r *Runner) Run(ctx context.Context) stats.Result {
+type Runner struct {
+	engines []Engine
+}
+
+func NewRunner(engines ...Engine) *Runner {
+	return &Runner{engines: engines}
+}
+
+// Run executes every configured engine in turn, so they don't compete for bandwidth.
+// Returns one stats.Result per engine, tagged with the engine name.
+func (r *Runner) Run(ctx context.Context) []stats.Result {
+	results := make([]stats.Result, 0, len(r.engines))
+	for _, engine := range r.engines {
+		tags := stats.Result{Engine: engine.Name()}
+		if be, ok := engine.(boundEngine); ok {
+			tags.IPVersion = be.network.Family
+			tags.Interface = be.network.Interface
+		}
+		engineCtx := withEngine(ctx, tags.Label())
+		reportProgress(engineCtx, PhaseStart)
+
+		res := r.runEngine(engineCtx, engine)
+		res.Engine, res.IPVersion, res.Interface = tags.Engine, tags.IPVersion, tags.Interface
+		results = append(results, res)
+	}
+	return results
+}
+
+// runEngine executes the speedtest with retries.
+func (r *Runner) runEngine(ctx context.Context, engine Engine) stats.Result {
 	var result stats.Result
 	var err error
 
@@ -34,69 +165,14 @@ func (r *Runner) Run(ctx context.Context) stats.Result {
 			time.Sleep(5 * time.Second) // Wait a bit before retry
 		}
 
-		result, err = r.executeCheck(ctx)
+		result, err = engine.Measure(ctx)
 		if err == nil {
 			return result
 		}
-		log.Warn().Err(err).Msg("Speedtest failed")
+		log.Warn().Err(err).Str("engine", engine.Name()).Msg("Speedtest failed")
 	}
 
 	result.Error = err
 	result.Time = time.Now()
 	return result
 }
-
-func (r *Runner) executeCheck(ctx context.Context) (stats.Result, error) {
-	res := stats.Result{
-		Time: time.Now(),
-	}
-
-	client := speedtest.New()
-
-	// Fetch user info
-	_, err := client.FetchUserInfoContext(ctx)
-	if err != nil {
-		return res, fmt.Errorf("failed to fetch user info: %w", err)
-	}
-
-	// Fetch servers
-	serverList, err := client.FetchServerListContext(ctx)
-	if err != nil {
-		return res, fmt.Errorf("failed to fetch server list: %w", err)
-	}
-
-	// Find closest server
-	targets, err := serverList.FindServer([]int{})
-	if err != nil || len(targets) == 0 {
-		return res, fmt.Errorf("failed to find server: %w", err)
-	}
-
-	server := targets[0] // Pick the best one
-
-	// Ping
-	err = server.PingTest(nil)
-	if err != nil {
-		return res, fmt.Errorf("ping test failed: %w", err)
-	}
-	res.Ping = server.Latency
-
-	// Download
-	err = server.DownloadTest()
-	if err != nil {
-		return res, fmt.Errorf("download test failed: %w", err)
-	}
-	res.Download = server.DLSpeed.Mbps()
-
-	// Upload
-	err = server.UploadTest()
-	if err != nil {
-		return res, fmt.Errorf("upload test failed: %w", err)
-	}
-	res.Upload = server.ULSpeed.Mbps()
-
-	// Store byte counts if available (speedtest-go usually exposes them via server.Context but mostly we utilize DLSpeed/ULSpeed)
-	// We won't worry about byte counts for this specific request as it's not explicitly asked for in the report,
-	// but the struct has them. We'll leave them 0 for now unless we dig deep into internal counters.
-
-	return res, nil
-}
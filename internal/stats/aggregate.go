@@ -0,0 +1,223 @@
+package stats
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// worstDays is how many of the slowest days an Aggregate lists.
+const worstDays = 3
+
+// Aggregate rolls up a longer window, such as a week or a month, day by day.
+// Unlike Summary it only covers speed test results, which are the data kept in storage.
+type Aggregate struct {
+	Days        int       // length of the window in calendar days
+	From, To    time.Time // bounds of the window
+	Since       time.Time // time of the oldest result found, zero without results
+	TotalTests  int
+	FailedTests int
+	AlertsCount int
+
+	// Downtime from failed tests, plus the outages recorded in the last DefaultRetention
+	Availability Availability
+	// Traffic of the tests themselves, lite checks included
+	BytesReceived, BytesSent uint64
+
+	AvgDownload, AvgUpload float64
+	AvgPing                time.Duration
+	// Download and upload at the 5th percentile, median and 95th percentile
+	DownloadP5, DownloadP50, DownloadP95 float64
+	UploadP5, UploadP50, UploadP95       float64
+	PingP50, PingP95                     time.Duration
+
+	Daily []DaySummary // every day with results, oldest first
+}
+
+// DaySummary holds the averages of one calendar day.
+type DaySummary struct {
+	Date        time.Time // midnight in the aggregate's location
+	Tests       int
+	Alerts      int
+	AvgDownload float64
+	AvgUpload   float64
+	AvgPing     time.Duration
+}
+
+// GetAggregate summarizes the last days calendar days in loc, today included.
+func (m *Manager) GetAggregate(now time.Time, days int, loc *time.Location) Aggregate {
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return m.aggregate(Aggregate{Days: days, From: midnight.AddDate(0, 0, 1-days), To: now}, loc)
+}
+
+// aggregate fills a with the results between its bounds, grouped into calendar days in loc.
+func (m *Manager) aggregate(a Aggregate, loc *time.Location) Aggregate {
+	results, err := m.storage.Query(a.From, a.To)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results")
+		return a
+	}
+	return m.aggregateResults(a, results, loc)
+}
+
+// aggregateResults is aggregate over the given results, for callers that leave some out.
+func (m *Manager) aggregateResults(a Aggregate, results []Result, loc *time.Location) Aggregate {
+	a.Availability = m.availability(results, a.From, a.To)
+	a.BytesReceived, a.BytesSent = traffic(results)
+
+	var downloads, uploads []float64
+	var pings []time.Duration
+	byDay := make(map[time.Time]*DaySummary)
+	dayUploads := make(map[time.Time]int)
+	for _, r := range results {
+		if r.Lite {
+			continue
+		}
+		if a.Since.IsZero() {
+			a.Since = r.Time
+		}
+		a.TotalTests++
+		if r.AlertSent {
+			a.AlertsCount++
+		}
+		if r.Error != nil {
+			a.FailedTests++
+			continue
+		}
+		downloads = append(downloads, r.Download)
+		if !r.NoUpload {
+			uploads = append(uploads, r.Upload)
+		}
+		pings = append(pings, r.Ping)
+
+		t := r.Time.In(loc)
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		d, ok := byDay[date]
+		if !ok {
+			d = &DaySummary{Date: date}
+			byDay[date] = d
+		}
+		// Accumulate sums, turned into averages below
+		d.Tests++
+		d.AvgDownload += r.Download
+		if !r.NoUpload {
+			d.AvgUpload += r.Upload
+			dayUploads[date]++
+		}
+		d.AvgPing += r.Ping
+		if r.AlertSent {
+			d.Alerts++
+		}
+	}
+
+	if len(downloads) == 0 {
+		return a
+	}
+	a.AvgDownload = mean(downloads)
+	a.AvgPing = mean(pings)
+	a.DownloadP5, a.DownloadP50, a.DownloadP95 = percentile(downloads, 5), percentile(downloads, 50), percentile(downloads, 95)
+	if len(uploads) > 0 {
+		a.AvgUpload = mean(uploads)
+		a.UploadP5, a.UploadP50, a.UploadP95 = percentile(uploads, 5), percentile(uploads, 50), percentile(uploads, 95)
+	}
+	a.PingP50, a.PingP95 = percentile(pings, 50), percentile(pings, 95)
+
+	for _, d := range byDay {
+		d.AvgDownload /= float64(d.Tests)
+		if n := dayUploads[d.Date]; n > 0 {
+			d.AvgUpload /= float64(n)
+		}
+		d.AvgPing /= time.Duration(d.Tests)
+		a.Daily = append(a.Daily, *d)
+	}
+	slices.SortFunc(a.Daily, func(x, y DaySummary) int { return x.Date.Compare(y.Date) })
+	return a
+}
+
+// trafficLine renders the test traffic for reports, empty when no test reported it.
+func (a Aggregate) trafficLine() string {
+	if a.BytesReceived+a.BytesSent == 0 {
+		return ""
+	}
+	return i18n.T("report.traffic", FormatBytes(a.BytesReceived), FormatBytes(a.BytesSent))
+}
+
+// WorstDays returns up to n days with the lowest average download, slowest first.
+func (a Aggregate) WorstDays(n int) []DaySummary {
+	days := slices.Clone(a.Daily)
+	slices.SortStableFunc(days, func(x, y DaySummary) int { return cmp.Compare(x.AvgDownload, y.AvgDownload) })
+	return days[:min(n, len(days))]
+}
+
+// BestDays returns up to n days with the highest average download, fastest first.
+func (a Aggregate) BestDays(n int) []DaySummary {
+	days := slices.Clone(a.Daily)
+	slices.SortStableFunc(days, func(x, y DaySummary) int { return cmp.Compare(y.AvgDownload, x.AvgDownload) })
+	return days[:min(n, len(days))]
+}
+
+// line renders the day for a list of days.
+func (d DaySummary) line() string {
+	return i18n.T("aggregate.day", d.Date.Format("Mon 01-02"), d.AvgDownload, d.AvgUpload, d.AvgPing.Milliseconds(), d.Tests, d.Alerts)
+}
+
+func (a Aggregate) String() string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("aggregate.title", a.Days, a.From.Format("2006-01-02"), a.To.Format("2006-01-02")))
+	if a.TotalTests == 0 {
+		sb.WriteString(i18n.T("aggregate.none"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("aggregate.tests", a.TotalTests, a.FailedTests, a.AlertsCount))
+	sb.WriteString(a.Availability.line())
+	sb.WriteString(a.trafficLine())
+	if len(a.Daily) > 0 {
+		sb.WriteString(i18n.T("aggregate.download", a.AvgDownload, a.DownloadP5, a.DownloadP50, a.DownloadP95))
+		sb.WriteString(i18n.T("aggregate.upload", a.AvgUpload, a.UploadP5, a.UploadP50, a.UploadP95))
+		sb.WriteString(i18n.T("aggregate.ping", a.AvgPing.Milliseconds(), a.PingP50.Milliseconds(), a.PingP95.Milliseconds()))
+	}
+	// Only a few days of data usually means in-memory storage or a short RETENTION
+	if a.Since.Sub(a.From) > 24*time.Hour {
+		sb.WriteString(i18n.T("aggregate.since", a.Since.In(a.From.Location()).Format("2006-01-02")))
+	}
+	if len(a.Daily) > 1 {
+		sb.WriteString(i18n.T("aggregate.worst"))
+		for _, d := range a.WorstDays(worstDays) {
+			sb.WriteString(d.line())
+		}
+	}
+	return sb.String()
+}
+
+func mean[T float64 | time.Duration](values []T) T {
+	var sum T
+	for _, v := range values {
+		sum += v
+	}
+	return sum / T(len(values))
+}
+
+// percentile returns the nearest-rank p-th percentile of values, which must not be empty.
+func percentile[T float64 | time.Duration](values []T, p int) T {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	return sorted[max(rank, 1)-1]
+}
+
+// stdDev returns the population standard deviation of values, which must not be empty.
+func stdDev[T float64 | time.Duration](values []T) T {
+	m := float64(mean(values))
+	var sum float64
+	for _, v := range values {
+		d := float64(v) - m
+		sum += d * d
+	}
+	return T(math.Sqrt(sum / float64(len(values))))
+}
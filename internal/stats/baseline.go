@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// minBaselineSamples is how many earlier results at the same hour a baseline needs before
+// measurements are compared with it.
+const minBaselineSamples = 3
+
+// Baseline is the usual speed of one engine at one hour of the day.
+type Baseline struct {
+	Download, Upload float64
+	Samples          int
+	UploadSamples    int // of those, results that measured upload
+}
+
+// GetBaseline averages the full, successful results with the given label from the days
+// before t that were taken in the same hour of the day as t in loc.
+func (m *Manager) GetBaseline(label string, t time.Time, days int, loc *time.Location) Baseline {
+	var b Baseline
+	results, err := m.storage.Query(t.AddDate(0, 0, -days), t)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results")
+		return b
+	}
+	hour := t.In(loc).Hour()
+	for _, r := range results {
+		if r.Lite || r.Error != nil || !r.Time.Before(t) || r.Label() != label || r.Time.In(loc).Hour() != hour {
+			continue
+		}
+		b.Download += r.Download
+		b.Samples++
+		if !r.NoUpload {
+			b.Upload += r.Upload
+			b.UploadSamples++
+		}
+	}
+	if b.Samples > 0 {
+		b.Download /= float64(b.Samples)
+	}
+	if b.UploadSamples > 0 {
+		b.Upload /= float64(b.UploadSamples)
+	}
+	return b
+}
+
+// BelowBaseline describes every direction in which r is more than drop percent below the
+// baseline. It returns nothing while the baseline has too few samples to tell.
+func BelowBaseline(r Result, b Baseline, drop float64) []string {
+	if b.Samples < minBaselineSamples || r.Error != nil || r.Lite {
+		return nil
+	}
+	var notes []string
+	if below := belowBy(r.Download, b.Download); below > drop {
+		notes = append(notes, i18n.T("baseline.download", r.Download, below, b.Download))
+	}
+	if r.NoUpload || b.UploadSamples < minBaselineSamples {
+		return notes
+	}
+	if below := belowBy(r.Upload, b.Upload); below > drop {
+		notes = append(notes, i18n.T("baseline.upload", r.Upload, below, b.Upload))
+	}
+	return notes
+}
+
+// belowBy returns how many percent value is below usual, zero if it isn't.
+func belowBy(value, usual float64) float64 {
+	if usual <= 0 || value >= usual {
+		return 0
+	}
+	return (usual - value) / usual * 100
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// significantChange is the relative change, in percent, from which a comparison is marked good or bad.
+const significantChange = 10
+
+// Comparison holds the speed test results of a period ending now next to the period before it.
+type Comparison struct {
+	Period            time.Duration
+	Current, Previous Summary
+}
+
+// Compare summarizes the period ending at now and the one right before it, in loc.
+func (m *Manager) Compare(now time.Time, period time.Duration, loc *time.Location) Comparison {
+	return Comparison{
+		Period:   period,
+		Current:  m.summarizeResults(now.Add(-period), now, Thresholds{}, loc),
+		Previous: m.summarizeResults(now.Add(-2*period), now.Add(-period), Thresholds{}, loc),
+	}
+}
+
+func (c Comparison) String() string {
+	var sb strings.Builder
+	p := FormatPeriod(c.Period)
+	sb.WriteString(i18n.T("compare.title", p, p))
+	cur, prev := c.Current, c.Previous
+	if cur.TotalTests == 0 || prev.TotalTests == 0 {
+		sb.WriteString(i18n.T("compare.no_data"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("compare.download", prev.AvgDownload, cur.AvgDownload, delta(prev.AvgDownload, cur.AvgDownload, true)))
+	sb.WriteString(i18n.T("compare.upload", prev.AvgUpload, cur.AvgUpload, delta(prev.AvgUpload, cur.AvgUpload, true)))
+	sb.WriteString(i18n.T("compare.ping", prev.AvgPing.Milliseconds(), cur.AvgPing.Milliseconds(),
+		delta(float64(prev.AvgPing), float64(cur.AvgPing), false)))
+	sb.WriteString(i18n.T("compare.alerts", prev.AlertsCount, cur.AlertsCount))
+	sb.WriteString(i18n.T("compare.tests", prev.TotalTests, cur.TotalTests))
+	return sb.String()
+}
+
+// delta renders the change from before to after as an arrow and a percentage, e.g. "↘️ −22% 🔴".
+// A significant change is marked green or red depending on whether higher is better.
+func delta(before, after float64, higherIsBetter bool) string {
+	if before == 0 {
+		return "–"
+	}
+	change := (after - before) / before * 100
+	if math.Abs(change) < 0.5 {
+		return "→ 0%"
+	}
+
+	s := fmt.Sprintf("↗️ +%.0f%%", change)
+	if change < 0 {
+		s = fmt.Sprintf("↘️ −%.0f%%", -change)
+	}
+	if math.Abs(change) >= significantChange {
+		if (change > 0) == higherIsBetter {
+			s += " 🟢"
+		} else {
+			s += " 🔴"
+		}
+	}
+	return s
+}
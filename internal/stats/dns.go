@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// DNSResult is one timed lookup of a host against a resolver.
+type DNSResult struct {
+	Time     time.Time
+	Host     string
+	Resolver string // "system" for the host's configured resolver
+	Duration time.Duration
+	Error    error
+	Slow     bool // lookup succeeded but took longer than the configured threshold
+}
+
+// DNSSummary aggregates lookups against one resolver.
+type DNSSummary struct {
+	Lookups int
+	Failed  int
+	Slow    int
+	Avg     time.Duration // over successful lookups
+}
+
+// AddDNS records DNS probe results. Like the ping monitor data they are kept in memory only.
+func (m *Manager) AddDNS(results []DNSResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dns = append(m.dns, results...)
+
+	cutoff := results[len(results)-1].Time.Add(-m.monitorRetention())
+	i := 0
+	for i < len(m.dns) && m.dns[i].Time.Before(cutoff) {
+		i++
+	}
+	m.dns = m.dns[i:]
+}
+
+// summarizeDNS returns per-resolver DNS statistics for lookups in [from, to].
+func (m *Manager) summarizeDNS(from, to time.Time) map[string]DNSSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out map[string]DNSSummary
+	sums := make(map[string]time.Duration)
+	for _, r := range m.dns {
+		if r.Time.Before(from) || r.Time.After(to) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]DNSSummary)
+		}
+		s := out[r.Resolver]
+		s.Lookups++
+		if r.Error != nil {
+			s.Failed++
+		} else {
+			sums[r.Resolver] += r.Duration
+			if r.Slow {
+				s.Slow++
+			}
+		}
+		out[r.Resolver] = s
+	}
+	for name, s := range out {
+		if ok := s.Lookups - s.Failed; ok > 0 {
+			s.Avg = sums[name] / time.Duration(ok)
+		}
+		out[name] = s
+	}
+	return out
+}
+
+// sortedKeys returns the keys of a summary map in a stable order for reports.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"time"
+)
+
+// EndpointResult is one availability check of a configured URL.
+type EndpointResult struct {
+	Time       time.Time
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+	Error      error // set when the endpoint is considered down
+}
+
+// EndpointSummary aggregates the checks of one URL.
+type EndpointSummary struct {
+	Checks int
+	Down   int
+	Avg    time.Duration // response time over successful checks
+}
+
+// Availability returns the share of successful checks in percent.
+func (e EndpointSummary) Availability() float64 {
+	if e.Checks == 0 {
+		return 0
+	}
+	return float64(e.Checks-e.Down) * 100 / float64(e.Checks)
+}
+
+// AddEndpoints records endpoint check results. Like other probe data they are kept in memory only.
+func (m *Manager) AddEndpoints(results []EndpointResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoints = append(m.endpoints, results...)
+
+	cutoff := results[len(results)-1].Time.Add(-m.monitorRetention())
+	i := 0
+	for i < len(m.endpoints) && m.endpoints[i].Time.Before(cutoff) {
+		i++
+	}
+	m.endpoints = m.endpoints[i:]
+}
+
+// summarizeEndpoints returns per-URL availability for checks in [from, to].
+func (m *Manager) summarizeEndpoints(from, to time.Time) map[string]EndpointSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out map[string]EndpointSummary
+	sums := make(map[string]time.Duration)
+	for _, r := range m.endpoints {
+		if r.Time.Before(from) || r.Time.After(to) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]EndpointSummary)
+		}
+		s := out[r.URL]
+		s.Checks++
+		if r.Error != nil {
+			s.Down++
+		} else {
+			sums[r.URL] += r.Duration
+		}
+		out[r.URL] = s
+	}
+	for u, s := range out {
+		if ok := s.Checks - s.Down; ok > 0 {
+			s.Avg = sums[u] / time.Duration(ok)
+		}
+		out[u] = s
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+var csvHeader = []string{"time", "download_mbps", "upload_mbps", "ping_ms", "jitter_ms", "error", "alert_sent", "engine", "server"}
+
+// WriteCSV writes results as CSV with a header row, suitable for spreadsheets.
+// The upload cell is empty when upload wasn't measured.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		errStr := ""
+		if r.Error != nil {
+			errStr = r.Error.Error()
+		}
+		upload := ""
+		if !r.NoUpload {
+			upload = strconv.FormatFloat(r.Upload, 'f', 2, 64)
+		}
+		record := []string{
+			r.Time.Format(time.RFC3339),
+			strconv.FormatFloat(r.Download, 'f', 2, 64),
+			upload,
+			strconv.FormatInt(r.Ping.Milliseconds(), 10),
+			strconv.FormatInt(r.Jitter.Milliseconds(), 10),
+			errStr,
+			strconv.FormatBool(r.AlertSent),
+			r.Engine,
+			r.ServerLabel(),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSONL writes results as one JSON object per line.
+func WriteJSONL(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parquetRow is the Parquet schema for exported results.
+type parquetRow struct {
+	Time          time.Time `parquet:"time,timestamp(millisecond)"`
+	Download      float64   `parquet:"download_mbps"`
+	Upload        *float64  `parquet:"upload_mbps,optional"` // nil when upload wasn't measured
+	PingMs        int64     `parquet:"ping_ms"`
+	JitterMs      int64     `parquet:"jitter_ms"`
+	BytesReceived uint64    `parquet:"bytes_received"`
+	BytesSent     uint64    `parquet:"bytes_sent"`
+	Error         string    `parquet:"error,optional"`
+	AlertSent     bool      `parquet:"alert_sent"`
+	Engine        string    `parquet:"engine,optional"`
+	Server        string    `parquet:"server,optional"`
+}
+
+// WriteParquet writes results as a Parquet file for analytical tools (DuckDB, Pandas, ...).
+func WriteParquet(w io.Writer, results []Result) error {
+	rows := make([]parquetRow, 0, len(results))
+	for _, r := range results {
+		row := parquetRow{
+			Time:          r.Time,
+			Download:      r.Download,
+			PingMs:        r.Ping.Milliseconds(),
+			JitterMs:      r.Jitter.Milliseconds(),
+			BytesReceived: r.BytesReceived,
+			BytesSent:     r.BytesSent,
+			AlertSent:     r.AlertSent,
+			Engine:        r.Engine,
+			Server:        r.ServerLabel(),
+		}
+		if !r.NoUpload {
+			row.Upload = &r.Upload
+		}
+		if r.Error != nil {
+			row.Error = r.Error.Error()
+		}
+		rows = append(rows, row)
+	}
+	return parquet.Write(w, rows, parquet.Compression(&parquet.Snappy))
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// CompareFamilies looks for engines measured over both IPv4 and IPv6 in one cycle and
+// describes every case where one family failed, or fell more than maxDiff percent
+// behind the other, while the other family was fine.
+func CompareFamilies(results []Result, maxDiff float64) []string {
+	type pair struct{ v4, v6 *Result }
+	pairs := make(map[string]*pair)
+	var order []string
+	for i := range results {
+		r := &results[i]
+		if r.IPVersion == "" {
+			continue
+		}
+		key := r.Engine
+		if r.Interface != "" {
+			key += "@" + r.Interface
+		}
+		p, ok := pairs[key]
+		if !ok {
+			p = &pair{}
+			pairs[key] = p
+			order = append(order, key)
+		}
+		if r.IPVersion == "ipv4" {
+			p.v4 = r
+		} else {
+			p.v6 = r
+		}
+	}
+
+	var notes []string
+	for _, engine := range order {
+		p := pairs[engine]
+		if p.v4 == nil || p.v6 == nil {
+			continue
+		}
+		if note := compareFamily("IPv6", p.v6, "IPv4", p.v4, maxDiff); note != "" {
+			notes = append(notes, fmt.Sprintf("%s (%s)", note, engine))
+		}
+		if note := compareFamily("IPv4", p.v4, "IPv6", p.v6, maxDiff); note != "" {
+			notes = append(notes, fmt.Sprintf("%s (%s)", note, engine))
+		}
+	}
+	return notes
+}
+
+// compareFamily reports whether r degraded compared to the healthy result other.
+func compareFamily(name string, r *Result, otherName string, other *Result, maxDiff float64) string {
+	if other.Error != nil {
+		return ""
+	}
+	if r.Error != nil {
+		return i18n.T("family.failed", html.EscapeString(name), html.EscapeString(otherName))
+	}
+	limit := 1 - maxDiff/100
+	if r.Download < other.Download*limit || r.Upload < other.Upload*limit {
+		return i18n.T("family.slower",
+			html.EscapeString(name), html.EscapeString(otherName), r.Download, r.Upload, other.Download, other.Upload)
+	}
+	return ""
+}
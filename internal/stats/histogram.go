@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// histogramWidth is how many characters the bar of a full bucket takes.
+const histogramWidth = 10
+
+// Bucket counts the successful full tests with a download in [Min, Max) Mbps.
+type Bucket struct {
+	Min, Max float64 // Max is +Inf for the last bucket
+	Tests    int
+}
+
+// SetSpeedBuckets sets the ascending download bounds in Mbps that split reports' speed
+// distribution into buckets, e.g. 50, 100, 200 for <50, 50–100, 100–200 and ≥200. None, the
+// default, leaves the distribution out.
+func (m *Manager) SetSpeedBuckets(bounds []float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.speedBuckets = bounds
+}
+
+// histogram sorts the downloads of successful full tests into the buckets between bounds.
+func histogram(results []Result, bounds []float64) []Bucket {
+	if len(bounds) == 0 {
+		return nil
+	}
+	buckets := make([]Bucket, len(bounds)+1)
+	lower := 0.0
+	for i := range buckets {
+		buckets[i].Min, buckets[i].Max = lower, math.Inf(1)
+		if i < len(bounds) {
+			buckets[i].Max, lower = bounds[i], bounds[i]
+		}
+	}
+	for _, r := range results {
+		if r.Error != nil || r.Lite {
+			continue
+		}
+		for i := range buckets {
+			if r.Download < buckets[i].Max {
+				buckets[i].Tests++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// label names the bucket's range, e.g. "<50", "50–100" or "≥200".
+func (b Bucket) label() string {
+	switch {
+	case b.Min == 0:
+		return fmt.Sprintf("<%g", b.Max)
+	case math.IsInf(b.Max, 1):
+		return fmt.Sprintf("≥%g", b.Min)
+	}
+	return fmt.Sprintf("%g–%g", b.Min, b.Max)
+}
+
+// histogramLines renders one bar per bucket with its share of the tests.
+func histogramLines(buckets []Bucket) string {
+	total := 0
+	width := 0
+	for _, b := range buckets {
+		total += b.Tests
+		width = max(width, len([]rune(b.label())))
+	}
+	if total == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, b := range buckets {
+		share := float64(b.Tests) / float64(total)
+		filled := int(math.Round(share * histogramWidth))
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", histogramWidth-filled)
+		label := b.label() + strings.Repeat(" ", width-len([]rune(b.label())))
+		sb.WriteString(i18n.T("report.bucket", html.EscapeString(label), bar, share*100, b.Tests))
+	}
+	return sb.String()
+}
@@ -0,0 +1,174 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Alert event metrics besides the "rule:<name>" of alert rules
+const (
+	AlertDownload = "download"
+	AlertUpload   = "upload"
+	AlertJitter   = "jitter"   // ms
+	AlertBaseline = "baseline" // download far below the usual speed at that hour
+	AlertOutage   = "outage"
+)
+
+// AlertEvent is one alert condition, from the test that alerted until the metric recovered.
+type AlertEvent struct {
+	ID        int64     `json:"id"`
+	Metric    string    `json:"metric"`
+	Target    string    `json:"target,omitempty"` // label of the results it is about
+	Value     float64   `json:"value"`            // measured when it alerted, in the metric's unit
+	Threshold float64   `json:"threshold"`        // the limit it crossed, zero if none
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end,omitzero"` // zero while ongoing
+}
+
+// Ongoing reports whether the event hasn't recovered yet.
+func (e AlertEvent) Ongoing() bool {
+	return e.End.IsZero()
+}
+
+// Duration returns how long the event lasted, or has lasted until now if ongoing.
+func (e AlertEvent) Duration(now time.Time) time.Duration {
+	if e.Ongoing() {
+		return now.Sub(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+// AlertHistory keeps alert events apart from the raw results, for /alerts. When a path is
+// configured, every change is appended to it as a JSON line, so the history survives restarts.
+type AlertHistory struct {
+	mu     sync.Mutex
+	path   string
+	limit  int
+	events []AlertEvent // oldest first
+	nextID int64
+}
+
+// NewAlertHistory keeps the latest limit events, loading them from path if set. A later line
+// of the same event replaces the earlier one.
+func NewAlertHistory(path string, limit int) (*AlertHistory, error) {
+	h := &AlertHistory{path: path, limit: limit, nextID: 1}
+	if path == "" {
+		return h, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert history: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AlertEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.Warn().Err(err).Msg("Skipping malformed alert history line")
+			continue
+		}
+		h.put(e)
+		h.nextID = max(h.nextID, e.ID+1)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert history: %w", err)
+	}
+	return h, nil
+}
+
+// put stores e, replacing the stored event with its ID. Caller must hold the lock or own h.
+func (h *AlertHistory) put(e AlertEvent) {
+	for i := len(h.events) - 1; i >= 0; i-- {
+		if h.events[i].ID == e.ID {
+			h.events[i] = e
+			return
+		}
+	}
+	h.events = append(h.events, e)
+	if len(h.events) > h.limit {
+		h.events = h.events[len(h.events)-h.limit:]
+	}
+}
+
+// ongoing returns the index of the ongoing event of a metric and target, or -1. Caller must hold the lock.
+func (h *AlertHistory) ongoing(metric, target string) int {
+	for i := len(h.events) - 1; i >= 0; i-- {
+		if e := h.events[i]; e.Ongoing() && e.Metric == metric && e.Target == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// Trigger starts an event unless one of the same metric and target is still ongoing.
+func (h *AlertHistory) Trigger(e AlertEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ongoing(e.Metric, e.Target) >= 0 {
+		return
+	}
+	e.ID, e.End = h.nextID, time.Time{}
+	h.nextID++
+	h.put(e)
+	h.write(e)
+}
+
+// Recover ends the ongoing event of a metric and target, if any, at t.
+func (h *AlertHistory) Recover(metric, target string, t time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.ongoing(metric, target)
+	if i < 0 {
+		return
+	}
+	h.events[i].End = t
+	h.write(h.events[i])
+}
+
+// write appends e to the history file. Failing to is logged, not fatal: the alert itself went out.
+// Caller must hold the lock.
+func (h *AlertHistory) write(e AlertEvent) {
+	if h.path == "" {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode alert event")
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open alert history")
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		log.Error().Err(err).Msg("Failed to write alert history")
+	}
+}
+
+// Recent returns up to n of the latest events, newest first.
+func (h *AlertHistory) Recent(n int) []AlertEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n = min(n, len(h.events))
+	out := make([]AlertEvent, 0, n)
+	for i := len(h.events) - 1; len(out) < n; i-- {
+		out = append(out, h.events[i])
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// sparkBars are the levels of a sparkline, lowest first.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkGap stands for a missing value in a sparkline.
+const sparkGap = '·'
+
+// HourSummary holds the averages of the tests taken in one hour of the day.
+type HourSummary struct {
+	Tests       int
+	AvgDownload float64
+	AvgUpload   float64 // over UploadTests
+	UploadTests int     // tests that measured upload
+}
+
+// hourly groups successful full tests by hour of the day in loc.
+func hourly(results []Result, loc *time.Location) [24]HourSummary {
+	var hours [24]HourSummary
+	for _, r := range results {
+		if r.Error != nil || r.Lite {
+			continue
+		}
+		// Accumulate sums, turned into averages below
+		h := &hours[r.Time.In(loc).Hour()]
+		h.Tests++
+		h.AvgDownload += r.Download
+		if !r.NoUpload {
+			h.AvgUpload += r.Upload
+			h.UploadTests++
+		}
+	}
+	for i := range hours {
+		if n := hours[i].Tests; n > 0 {
+			hours[i].AvgDownload /= float64(n)
+		}
+		if n := hours[i].UploadTests; n > 0 {
+			hours[i].AvgUpload /= float64(n)
+		}
+	}
+	return hours
+}
+
+// slowestFastest returns the hours with the lowest and highest average download; ok is false
+// unless at least two hours have tests.
+func slowestFastest(hours [24]HourSummary) (slowest, fastest int, ok bool) {
+	slowest, fastest = -1, -1
+	for i, h := range hours {
+		if h.Tests == 0 {
+			continue
+		}
+		if slowest < 0 || h.AvgDownload < hours[slowest].AvgDownload {
+			slowest = i
+		}
+		if fastest < 0 || h.AvgDownload > hours[fastest].AvgDownload {
+			fastest = i
+		}
+	}
+	return slowest, fastest, slowest >= 0 && slowest != fastest
+}
+
+// sparkline renders values as bars scaled between their minimum and maximum, NaN as a gap.
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			sb.WriteRune(sparkGap)
+		case hi == lo:
+			sb.WriteRune(sparkBars[len(sparkBars)/2])
+		default:
+			level := int((v - lo) / (hi - lo) * float64(len(sparkBars)-1))
+			sb.WriteRune(sparkBars[level])
+		}
+	}
+	return sb.String()
+}
+
+// hourlySparkline renders the average download of every hour of the day, midnight first.
+func hourlySparkline(hours [24]HourSummary) string {
+	values := make([]float64, len(hours))
+	for i, h := range hours {
+		values[i] = h.AvgDownload
+		if h.Tests == 0 {
+			values[i] = math.NaN()
+		}
+	}
+	return sparkline(values)
+}
+
+// hourAxis labels the hours below an hourly sparkline.
+const hourAxis = "0     6     12    18   23"
+
+// timelineSlots is how many steps the download timeline of a summary has, half hours over a day.
+const timelineSlots = 48
+
+// Timeline is the download over a summary's period in equal steps, oldest first, so reports can
+// show it as text where a chart image is unwanted.
+type Timeline struct {
+	From, To time.Time
+	Download []float64 // average per step, NaN for steps without a successful full test
+}
+
+// timeline averages the downloads of successful full tests in timelineSlots steps of [from, to].
+func timeline(results []Result, from, to time.Time) Timeline {
+	t := Timeline{From: from, To: to, Download: make([]float64, timelineSlots)}
+	step := to.Sub(from) / timelineSlots
+	if step <= 0 {
+		return Timeline{}
+	}
+	tests := make([]int, timelineSlots)
+	for _, r := range results {
+		if r.Error != nil || r.Lite || r.Time.Before(from) || r.Time.After(to) {
+			continue
+		}
+		i := min(int(r.Time.Sub(from)/step), timelineSlots-1)
+		t.Download[i] += r.Download
+		tests[i]++
+	}
+	for i, n := range tests {
+		if n == 0 {
+			t.Download[i] = math.NaN()
+		} else {
+			t.Download[i] /= float64(n)
+		}
+	}
+	return t
+}
+
+// line renders the timeline as a sparkline between its start and end times, empty when no step
+// has a test.
+func (t Timeline) line() string {
+	if !slices.ContainsFunc(t.Download, func(v float64) bool { return !math.IsNaN(v) }) {
+		return ""
+	}
+	layout := "15:04"
+	if t.To.Sub(t.From) > 24*time.Hour {
+		layout = "01-02"
+	}
+	return i18n.T("report.timeline", t.From.Format(layout), sparkline(t.Download), t.To.Format(layout))
+}
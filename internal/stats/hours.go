@@ -0,0 +1,147 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	rankedHours = 3 // best and worst hours listed
+	// An hour is consistently slow when its download was below the day's average on this share of
+	// at least consistentDays days
+	consistentShare = 0.75
+	consistentDays  = 3
+)
+
+// HourRank is one hour of the day over the window of an HourAnalysis.
+type HourRank struct {
+	Hour        int
+	Tests       int
+	AvgDownload float64
+	AvgUpload   float64 // over UploadTests
+	UploadTests int     // tests that measured upload
+	Days        int     // days with a successful full test in this hour
+	SlowDays    int     // of those, days the hour's download was below the day's average
+}
+
+// Consistent reports whether the hour was slower than the rest of the day on most days.
+func (h HourRank) Consistent() bool {
+	return h.Days >= consistentDays && float64(h.SlowDays) >= consistentShare*float64(h.Days)
+}
+
+// HourAnalysis ranks the hours of the day by average download over a window of days, to tell
+// congestion at certain hours from a connection that is slow all day.
+type HourAnalysis struct {
+	Days     int
+	From, To time.Time
+	Hours    []HourRank // hours with tests, slowest first
+}
+
+// GetHourAnalysis ranks the hours of the day in loc over the last days days before now.
+func (m *Manager) GetHourAnalysis(now time.Time, days int, loc *time.Location) HourAnalysis {
+	a := HourAnalysis{Days: days, From: now.AddDate(0, 0, -days), To: now}
+	results, err := m.storage.Query(a.From, a.To)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results for hour analysis")
+		return a
+	}
+
+	// Sums of download per day, and per day and hour, turned into averages below
+	type sum struct {
+		total float64
+		tests int
+	}
+	byDay := make(map[time.Time]*sum)
+	byDayHour := make(map[time.Time]*[24]sum)
+	var hours [24]HourRank
+	for _, r := range results {
+		if r.Error != nil || r.Lite {
+			continue
+		}
+		t := r.Time.In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if byDay[day] == nil {
+			byDay[day], byDayHour[day] = &sum{}, &[24]sum{}
+		}
+		byDay[day].total += r.Download
+		byDay[day].tests++
+		byDayHour[day][t.Hour()].total += r.Download
+		byDayHour[day][t.Hour()].tests++
+
+		h := &hours[t.Hour()]
+		h.Tests++
+		h.AvgDownload += r.Download
+		if !r.NoUpload {
+			h.AvgUpload += r.Upload
+			h.UploadTests++
+		}
+	}
+
+	for day, d := range byDay {
+		dayAvg := d.total / float64(d.tests)
+		for hour, s := range byDayHour[day] {
+			if s.tests == 0 {
+				continue
+			}
+			hours[hour].Days++
+			if s.total/float64(s.tests) < dayAvg {
+				hours[hour].SlowDays++
+			}
+		}
+	}
+	for i, h := range hours {
+		if h.Tests == 0 {
+			continue
+		}
+		h.Hour = i
+		h.AvgDownload /= float64(h.Tests)
+		if h.UploadTests > 0 {
+			h.AvgUpload /= float64(h.UploadTests)
+		}
+		a.Hours = append(a.Hours, h)
+	}
+	slices.SortStableFunc(a.Hours, func(x, y HourRank) int { return cmp.Compare(x.AvgDownload, y.AvgDownload) })
+	return a
+}
+
+func (h HourRank) line() string {
+	if h.UploadTests == 0 {
+		return i18n.T("hours.no_upload", h.Hour, h.AvgDownload, h.SlowDays, h.Days)
+	}
+	return i18n.T("hours.line", h.Hour, h.AvgDownload, h.AvgUpload, h.SlowDays, h.Days)
+}
+
+func (a HourAnalysis) String() string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("hours.title", a.Days))
+	// With fewer hours than twice the list, best and worst would overlap
+	if len(a.Hours) < 2*rankedHours {
+		sb.WriteString(i18n.T("hours.none"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("hours.worst"))
+	for _, h := range a.Hours[:rankedHours] {
+		sb.WriteString(h.line())
+	}
+	sb.WriteString(i18n.T("hours.best"))
+	for i := len(a.Hours) - 1; i >= len(a.Hours)-rankedHours; i-- {
+		sb.WriteString(a.Hours[i].line())
+	}
+
+	var consistent []string
+	for _, h := range a.Hours {
+		if h.Consistent() {
+			consistent = append(consistent, fmt.Sprintf("%02d:00", h.Hour))
+		}
+	}
+	if len(consistent) > 0 {
+		sb.WriteString(i18n.T("hours.consistent", strings.Join(consistent, ", ")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package stats
+
+import "time"
+
+// sustainedJitterTests is how many tests in a row need high jitter for the report to list them.
+const sustainedJitterTests = 2
+
+// JitterPeriod is a run of consecutive tests with jitter above JITTER_THRESHOLD.
+type JitterPeriod struct {
+	Start, End time.Time // times of the first and the last test
+	Tests      int
+	Max        time.Duration
+}
+
+// SetJitterThreshold sets the jitter above which runs of tests are reported as high-jitter
+// periods. Zero, the default, disables them.
+func (m *Manager) SetJitterThreshold(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jitterThreshold = d
+}
+
+// jitterPeriods finds the runs of at least sustainedJitterTests full tests with jitter above
+// threshold, in chronological results. Failed tests measured nothing, so they don't end a run.
+func jitterPeriods(results []Result, threshold time.Duration) []JitterPeriod {
+	if threshold <= 0 {
+		return nil
+	}
+	var periods []JitterPeriod
+	var run JitterPeriod
+	flush := func() {
+		if run.Tests >= sustainedJitterTests {
+			periods = append(periods, run)
+		}
+		run = JitterPeriod{}
+	}
+	for _, r := range results {
+		switch {
+		case r.Error != nil || r.Lite:
+		case r.Jitter > threshold:
+			if run.Tests == 0 {
+				run.Start = r.Time
+			}
+			run.End = r.Time
+			run.Tests++
+			run.Max = max(run.Max, r.Jitter)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return periods
+}
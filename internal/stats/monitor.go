@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"time"
+)
+
+// Outage is a period during which the ping monitor couldn't reach its target, or every
+// speed test failed.
+type Outage struct {
+	Start  time.Time
+	End    time.Time
+	Target string
+}
+
+// Duration returns how long the outage lasted.
+func (o Outage) Duration() time.Duration {
+	return o.End.Sub(o.Start)
+}
+
+// pingBucket aggregates ping monitor probes over one minute, so a day of
+// probes every few seconds stays small in memory.
+type pingBucket struct {
+	Time    time.Time
+	Sent    int
+	Lost    int
+	Latency time.Duration // sum over successful probes
+}
+
+// AddPing records one ping monitor probe; lost probes pass ok=false.
+// Probe data and outages are kept in memory only.
+func (m *Manager) AddPing(t time.Time, latency time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	minute := t.Truncate(time.Minute)
+	if n := len(m.pings); n == 0 || !m.pings[n-1].Time.Equal(minute) {
+		m.pings = append(m.pings, pingBucket{Time: minute})
+	}
+	b := &m.pings[len(m.pings)-1]
+	b.Sent++
+	if ok {
+		b.Latency += latency
+	} else {
+		b.Lost++
+	}
+
+	cutoff := t.Add(-m.monitorRetention())
+	i := 0
+	for i < len(m.pings) && m.pings[i].Time.Before(cutoff) {
+		i++
+	}
+	m.pings = m.pings[i:]
+}
+
+// AddOutage records a finished outage.
+func (m *Manager) AddOutage(o Outage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outages = append(m.outages, o)
+
+	cutoff := o.End.Add(-m.monitorRetention())
+	i := 0
+	for i < len(m.outages) && m.outages[i].End.Before(cutoff) {
+		i++
+	}
+	m.outages = m.outages[i:]
+}
+
+// monitorRetention bounds the in-memory monitor data even when results are kept forever.
+func (m *Manager) monitorRetention() time.Duration {
+	if m.retention > 0 && m.retention < DefaultRetention {
+		return m.retention
+	}
+	return DefaultRetention
+}
+
+// summarizeMonitor fills the ping monitor fields of s with data in [from, to].
+func (m *Manager) summarizeMonitor(s *Summary, from, to time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latency time.Duration
+	for _, b := range m.pings {
+		if b.Time.Before(from) || b.Time.After(to) {
+			continue
+		}
+		s.MonitorProbes += b.Sent
+		s.MonitorLost += b.Lost
+		latency += b.Latency
+	}
+	if ok := s.MonitorProbes - s.MonitorLost; ok > 0 {
+		s.MonitorAvgPing = latency / time.Duration(ok)
+	}
+
+	for _, o := range m.outages {
+		if o.End.Before(from) || o.Start.After(to) {
+			continue
+		}
+		s.Outages = append(s.Outages, o)
+	}
+}
@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// planReached is the percentage of the advertised speed a test must reach to count as delivering
+// the plan, the yardstick regulators commonly use.
+const planReached = 80
+
+// Plan is the speed the ISP advertises. Zero fields aren't compared.
+type Plan struct {
+	Download, Upload float64 // Mbps
+}
+
+// SetPlan sets the advertised speeds that summaries express the averages as a share of.
+func (m *Manager) SetPlan(p Plan) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.plan = p
+}
+
+// planShare returns measured as a percentage of advertised.
+func planShare(measured, advertised float64) float64 {
+	return measured / advertised * 100
+}
+
+// planLines renders the averages as a share of the plan, empty without one.
+func planLines(p Plan, download, upload float64) string {
+	var sb strings.Builder
+	if p.Download > 0 {
+		sb.WriteString(i18n.T("report.plan_download", planShare(download, p.Download), p.Download))
+	}
+	if p.Upload > 0 {
+		sb.WriteString(i18n.T("report.plan_upload", planShare(upload, p.Upload), p.Upload))
+	}
+	return sb.String()
+}
+
+// PlanReport is how a period measured up against the advertised plan, written to be forwarded
+// to the ISP.
+type PlanReport struct {
+	Plan Plan
+	Aggregate
+	Measured        int // successful full tests outside maintenance windows
+	DownloadReached int // of those, tests reaching planReached percent of the advertised download
+	UploadReached   int
+}
+
+// GetPlanReport measures [from, to) in loc against the plan.
+func (m *Manager) GetPlanReport(plan Plan, from, to time.Time, loc *time.Location) PlanReport {
+	days := int(math.Round(to.Sub(from).Hours() / 24))
+	r := PlanReport{Plan: plan, Aggregate: Aggregate{Days: days, From: from, To: to}}
+	results, err := m.storage.Query(from, to)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results for plan report")
+		return r
+	}
+	// Maintenance windows say nothing about what the ISP delivers, neither in the figures nor the shares
+	results = slices.DeleteFunc(results, func(res Result) bool { return res.Maintenance || !res.Time.Before(to) })
+	r.Aggregate = m.aggregateResults(r.Aggregate, results, loc)
+	for _, res := range results {
+		if res.Error != nil || res.Lite {
+			continue
+		}
+		r.Measured++
+		if res.Download >= plan.Download*planReached/100 {
+			r.DownloadReached++
+		}
+		if res.NoUpload || res.Upload >= plan.Upload*planReached/100 {
+			r.UploadReached++
+		}
+	}
+	return r
+}
+
+func (r PlanReport) String() string {
+	var sb strings.Builder
+	a := r.Aggregate
+	sb.WriteString(i18n.T("plan.title", a.From.Format("2006-01-02"), a.To.AddDate(0, 0, -1).Format("2006-01-02")))
+	sb.WriteString(i18n.T("plan.advertised", r.Plan.Download, r.Plan.Upload))
+	if a.TotalTests == 0 {
+		sb.WriteString(i18n.T("aggregate.none"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("plan.tests", a.TotalTests, a.FailedTests))
+	sb.WriteString(a.Availability.line())
+	if r.Measured == 0 {
+		return sb.String()
+	}
+	if r.Plan.Download > 0 {
+		sb.WriteString(i18n.T("plan.download", a.AvgDownload, planShare(a.AvgDownload, r.Plan.Download), a.DownloadP50, a.DownloadP5))
+		sb.WriteString(i18n.T("plan.reached", float64(r.DownloadReached)/float64(r.Measured)*100, planReached))
+	}
+	if r.Plan.Upload > 0 {
+		sb.WriteString(i18n.T("plan.upload", a.AvgUpload, planShare(a.AvgUpload, r.Plan.Upload), a.UploadP50, a.UploadP5))
+		sb.WriteString(i18n.T("plan.reached", float64(r.UploadReached)/float64(r.Measured)*100, planReached))
+	}
+	sb.WriteString(i18n.T("plan.ping", a.AvgPing.Milliseconds(), a.PingP95.Milliseconds()))
+	return sb.String()
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// Rollup is a completed calendar week or month next to the one before it.
+type Rollup struct {
+	Monthly           bool
+	Current, Previous Aggregate
+}
+
+// GetWeeklyRollup rolls up the last completed calendar week in loc, Monday to Sunday.
+func (m *Manager) GetWeeklyRollup(now time.Time, loc *time.Location) Rollup {
+	now = now.In(loc)
+	monday := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	from := monday.AddDate(0, 0, -7)
+	return Rollup{
+		Current:  m.aggregate(Aggregate{Days: 7, From: from, To: monday}, loc),
+		Previous: m.aggregate(Aggregate{Days: 7, From: from.AddDate(0, 0, -7), To: from}, loc),
+	}
+}
+
+// GetMonthlyRollup rolls up the last completed calendar month in loc.
+func (m *Manager) GetMonthlyRollup(now time.Time, loc *time.Location) Rollup {
+	now = now.In(loc)
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	from, before := first.AddDate(0, -1, 0), first.AddDate(0, -2, 0)
+	return Rollup{
+		Monthly:  true,
+		Current:  m.aggregate(Aggregate{Days: first.AddDate(0, 0, -1).Day(), From: from, To: first}, loc),
+		Previous: m.aggregate(Aggregate{Days: from.AddDate(0, 0, -1).Day(), From: before, To: from}, loc),
+	}
+}
+
+func (r Rollup) String() string {
+	var sb strings.Builder
+	cur, prev := r.Current, r.Previous
+	if r.Monthly {
+		sb.WriteString(i18n.T("rollup.month_title", cur.From.Format("2006-01")))
+	} else {
+		sb.WriteString(i18n.T("rollup.week_title", cur.From.Format("2006-01-02"), cur.To.AddDate(0, 0, -1).Format("2006-01-02")))
+	}
+	if cur.TotalTests == 0 {
+		sb.WriteString(i18n.T("aggregate.none"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("aggregate.tests", cur.TotalTests, cur.FailedTests, cur.AlertsCount))
+	sb.WriteString(cur.Availability.line())
+	sb.WriteString(cur.trafficLine())
+	if len(cur.Daily) > 0 {
+		if r.Monthly {
+			sb.WriteString(i18n.T("rollup.vs_month"))
+		} else {
+			sb.WriteString(i18n.T("rollup.vs_week"))
+		}
+		sb.WriteString(i18n.T("rollup.download", cur.AvgDownload, delta(prev.AvgDownload, cur.AvgDownload, true)))
+		sb.WriteString(i18n.T("rollup.upload", cur.AvgUpload, delta(prev.AvgUpload, cur.AvgUpload, true)))
+		sb.WriteString(i18n.T("rollup.ping", cur.AvgPing.Milliseconds(), delta(float64(prev.AvgPing), float64(cur.AvgPing), false)))
+		sb.WriteString(i18n.T("rollup.alerts", cur.AlertsCount, prev.AlertsCount))
+	}
+	if len(cur.Daily) > 1 {
+		sb.WriteString(i18n.T("rollup.best") + cur.BestDays(1)[0].line())
+		sb.WriteString(i18n.T("rollup.worst") + cur.WorstDays(1)[0].line())
+	}
+	if cur.Since.Sub(cur.From) > 24*time.Hour {
+		sb.WriteString(i18n.T("aggregate.since", cur.Since.In(cur.From.Location()).Format("2006-01-02")))
+	}
+	return sb.String()
+}
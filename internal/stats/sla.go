@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// SLATarget is the service level promised by the ISP. Zero fields aren't checked.
+type SLATarget struct {
+	Download, Upload float64 // Mbps
+	Compliance       float64 // percent of tests that must reach Download and Upload
+	Uptime           float64 // percent
+}
+
+// checksSpeed reports whether the target promises a speed.
+func (t SLATarget) checksSpeed() bool {
+	return t.Download > 0 || t.Upload > 0
+}
+
+// SLAReport is how a billing period measures up against the SLA, so far if it isn't over.
+type SLAReport struct {
+	Target    SLATarget
+	From, To  time.Time
+	Tests     int           // full tests taken outside maintenance windows
+	Compliant int           // of those, tests that reached the promised speed
+	Remaining int           // scheduled tests still to come in the period
+	Downtime  time.Duration // runs of failed tests, from the first failure until a test passed
+}
+
+// BillingPeriod returns the billing month containing t, starting at midnight of day in t's location.
+func BillingPeriod(t time.Time, day int) (from, to time.Time) {
+	from = time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, t.Location())
+	if from.After(t) {
+		from = from.AddDate(0, -1, 0)
+	}
+	return from, from.AddDate(0, 1, 0)
+}
+
+// GetSLAReport measures the billing period [from, to) up to now. Tests still to come are
+// estimated from the check interval.
+func (m *Manager) GetSLAReport(target SLATarget, from, to, now time.Time, interval time.Duration) SLAReport {
+	r := SLAReport{Target: target, From: from, To: to}
+	end := to
+	if now.Before(to) {
+		end = now
+		if interval > 0 {
+			r.Remaining = int(to.Sub(now) / interval)
+		}
+	}
+	results, err := m.storage.Query(from, end)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results for SLA")
+		return r
+	}
+
+	for _, res := range results {
+		if res.Maintenance || res.Lite || !res.Time.Before(end) {
+			continue
+		}
+		r.Tests++
+		if res.Error == nil && res.Download >= target.Download && (res.NoUpload || res.Upload >= target.Upload) {
+			r.Compliant++
+		}
+	}
+	for _, o := range failureRuns(results, end) {
+		r.Downtime += o.Duration()
+	}
+	return r
+}
+
+// SpeedCompliance returns the percentage of tests that reached the promised speed.
+func (r SLAReport) SpeedCompliance() float64 {
+	if r.Tests == 0 {
+		return 100
+	}
+	return float64(r.Compliant) / float64(r.Tests) * 100
+}
+
+// BestSpeedCompliance returns the percentage of tests reaching the promised speed at the end of
+// the period if every remaining test does.
+func (r SLAReport) BestSpeedCompliance() float64 {
+	total := r.Tests + r.Remaining
+	if total == 0 {
+		return 100
+	}
+	return float64(r.Compliant+r.Remaining) / float64(total) * 100
+}
+
+// SpeedBreached reports whether the speed target can't be met anymore, even if every remaining
+// test reaches the promised speed.
+func (r SLAReport) SpeedBreached() bool {
+	return r.Target.checksSpeed() && r.BestSpeedCompliance() < r.Target.Compliance
+}
+
+// AllowedDowntime returns the downtime the uptime target allows over the whole period.
+func (r SLAReport) AllowedDowntime() time.Duration {
+	return time.Duration(float64(r.To.Sub(r.From)) * (100 - r.Target.Uptime) / 100)
+}
+
+// Uptime returns the uptime over the whole period, assuming no more downtime.
+func (r SLAReport) Uptime() float64 {
+	return 100 - float64(r.Downtime)/float64(r.To.Sub(r.From))*100
+}
+
+// UptimeBreached reports whether there was more downtime than the uptime target allows.
+func (r SLAReport) UptimeBreached() bool {
+	return r.Target.Uptime > 0 && r.Downtime > r.AllowedDowntime()
+}
+
+func (r SLAReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("sla.title", r.From.Format("2006-01-02"), r.To.AddDate(0, 0, -1).Format("2006-01-02")))
+	if r.Target.checksSpeed() {
+		sb.WriteString(i18n.T("sla.speed", r.Target.Download, r.Target.Upload, r.SpeedCompliance(), r.Tests, r.Target.Compliance))
+	}
+	if r.Target.Uptime > 0 {
+		sb.WriteString(i18n.T("sla.uptime", r.Uptime(), FormatPeriod(r.Downtime.Round(time.Minute)), r.Target.Uptime))
+	}
+	switch {
+	case r.SpeedBreached() || r.UptimeBreached():
+		sb.WriteString(i18n.T("sla.breached"))
+	case r.Remaining > 0:
+		sb.WriteString(i18n.T("sla.on_track"))
+	default:
+		sb.WriteString(i18n.T("sla.met"))
+	}
+	return sb.String()
+}
@@ -2,93 +2,376 @@ package stats
 
 import (
 	"fmt"
+	"html"
 	"math"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// Severities of an alert. Warnings are sent silently, critical alerts make a sound.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
 )
 
 type Result struct {
-	Time          time.Time
-	Download      float64 // Mbps
-	Upload        float64 // Mbps
-	Ping          time.Duration
-	BytesReceived uint64
-	BytesSent     uint64
-	Error         error
-	AlertSent     bool
+	Time           time.Time
+	Download       float64 // Mbps
+	Upload         float64 // Mbps
+	Ping           time.Duration
+	Jitter         time.Duration // variation between consecutive latency samples
+	LoadedPingDown time.Duration // median latency during the download phase, zero if not measured
+	LoadedPingUp   time.Duration // median latency during the upload phase, zero if not measured
+	BytesReceived  uint64
+	BytesSent      uint64
+	Error          error
+	AlertSent      bool
+	Severity       string // SeverityWarning or SeverityCritical when AlertSent, empty for older results
+	Engine         string // speed test engine that produced the result
+	Server         string // host the measurement ran against, if known
+	ServerID       string // speedtest.net server ID, only known for the Ookla engines
+	ServerName     string // sponsor and city of the speedtest.net server, e.g. "Kyivstar, Kyiv"
+	IPVersion      string // "ipv4" or "ipv6" when the test was pinned to one IP family
+	Interface      string // local interface or source IP the test was bound to
+	Lite           bool   // cheap check (ping + small download), not comparable with full tests
+	NoUpload       bool   // upload wasn't measured, e.g. the http engine without HTTP_PROBE_UPLOAD_URL
+	Maintenance    bool   // taken during a maintenance window, so it didn't alert
+	ShareURL       string // official result page, only reported by the ookla-cli engine
+	// Engine details such as the server's name and location, for /test verbose
+	Meta map[string]string
+}
+
+// Label names the engine and, if pinned, the interface and IP family that produced the result.
+func (r Result) Label() string {
+	label := r.Engine
+	if r.Interface != "" {
+		label += "@" + r.Interface
+	}
+	switch r.IPVersion {
+	case "ipv4":
+		label += " (IPv4)"
+	case "ipv6":
+		label += " (IPv6)"
+	}
+	return label
+}
+
+// ServerLabel names the server the measurement ran against, falling back to its ID or host.
+func (r Result) ServerLabel() string {
+	switch {
+	case r.ServerName != "":
+		return r.ServerName
+	case r.ServerID != "":
+		return "#" + r.ServerID
+	}
+	return r.Server
+}
+
+// ServerName joins the sponsor and city of a speedtest.net server, either of which may be empty.
+func ServerName(sponsor, city string) string {
+	if sponsor == "" || city == "" {
+		return sponsor + city
+	}
+	return sponsor + ", " + city
+}
+
+// BufferbloatIncrease returns how much latency grows under load, using the worse of
+// the download and upload phases. ok is false when the engine didn't measure it.
+func (r Result) BufferbloatIncrease() (increase time.Duration, ok bool) {
+	loaded := max(r.LoadedPingDown, r.LoadedPingUp)
+	if loaded == 0 {
+		return 0, false
+	}
+	return max(loaded-r.Ping, 0), true
+}
+
+// BufferbloatGrade grades the latency increase under load on the familiar A+ to F scale,
+// or returns an empty string when it wasn't measured.
+func (r Result) BufferbloatGrade() string {
+	increase, ok := r.BufferbloatIncrease()
+	if !ok {
+		return ""
+	}
+	return GradeBufferbloat(increase)
+}
+
+// GradeBufferbloat maps a latency increase under load to a grade.
+func GradeBufferbloat(increase time.Duration) string {
+	switch {
+	case increase < 5*time.Millisecond:
+		return "A+"
+	case increase < 30*time.Millisecond:
+		return "A"
+	case increase < 60*time.Millisecond:
+		return "B"
+	case increase < 200*time.Millisecond:
+		return "C"
+	case increase < 400*time.Millisecond:
+		return "D"
+	default:
+		return "F"
+	}
 }
 
 type Summary struct {
+	Period         time.Duration // length of the summarized window, 24h when zero
+	From, To       time.Time     // bounds of a fixed window, zero for windows ending now
 	TotalTests     int
+	LiteChecks     int
+	Maintenance    int // tests and lite checks taken during maintenance windows
 	AvgDownload    float64
 	MinDownload    float64
 	MaxDownload    float64
 	AvgUpload      float64
 	MinUpload      float64
 	MaxUpload      float64
+	UploadTests    int // tests that measured upload, the upload statistics cover only these
 	AvgPing        time.Duration
 	MinPing        time.Duration
 	MaxPing        time.Duration
+	MedianDownload float64 // medians and standard deviations are less swayed by a single outlier than averages
+	StdDevDownload float64
+	MedianUpload   float64
+	StdDevUpload   float64
+	MedianPing     time.Duration
+	StdDevPing     time.Duration
+	AvgJitter      time.Duration
+	MaxJitter      time.Duration
+	AvgBufferbloat time.Duration // average latency increase under load, zero if never measured
 	AlertsCount    int
+	WarningsCount  int // alerts of warning severity, the rest were critical
 	LowSpeedEvents []Result
+	JitterPeriods  []JitterPeriod           // sustained high jitter, see SetJitterThreshold
+	Hourly         [24]HourSummary          // by hour of the day, to show congestion at certain hours
+	Timeline       Timeline                 // download over the period, for chats without charts
+	Histogram      []Bucket                 // download distribution, nil without SetSpeedBuckets
+	Plan           Plan                     // advertised speeds, see SetPlan
+	Engines        map[string]EngineSummary // per-engine breakdown, keyed by Result.Label
+	Servers        map[string]EngineSummary // per-server breakdown, keyed by Result.ServerLabel
+	Declines       []string                 // steady declines over the last days, set by the caller from Trend.Declines
+
+	// Continuous ping monitor, zero when it is disabled
+	MonitorProbes  int
+	MonitorLost    int
+	MonitorAvgPing time.Duration
+	Outages        []Outage
+	Availability   Availability // downtime from Outages and failed tests
+	LongestStreak  Streak       // most failed tests in a row, lite checks included
+	BytesReceived  uint64       // traffic of the tests themselves, lite checks included
+	BytesSent      uint64
+
+	// DNS probe results per resolver, nil when DNS probing is disabled
+	DNS map[string]DNSSummary
+
+	// Endpoint availability per URL, nil when endpoint checks are disabled
+	Endpoints map[string]EndpointSummary
+}
+
+// EngineSummary holds averages for the results of one speed test engine or server.
+type EngineSummary struct {
+	TotalTests  int
+	FailedTests int
+	AlertsCount int
+	AvgDownload float64
+	AvgUpload   float64 // over UploadTests
+	AvgPing     time.Duration
+	UploadTests int // successful tests that measured upload
 }
 
 type Manager struct {
-	mu      sync.RWMutex
-	results []Result
-	maxSize int
+	storage   Storage
+	retention time.Duration
+
+	mu        sync.Mutex // guards the in-memory data below
+	latest    *Result    // most recent result, read from storage on first use
+	pings     []pingBucket
+	outages   []Outage
+	dns       []DNSResult
+	endpoints []EndpointResult
+
+	jitterThreshold time.Duration
+	speedBuckets    []float64
+	plan            Plan
 }
 
-func NewManager(maxSize int) *Manager {
-	if maxSize <= 0 {
-		maxSize = 100 // Default safe size
+// DefaultRetention is used for in-memory storage when no positive retention is configured.
+const DefaultRetention = 7 * 24 * time.Hour
+
+// NewManager creates a Manager with in-memory storage keeping results younger than retention.
+func NewManager(retention time.Duration) *Manager {
+	if retention <= 0 {
+		retention = DefaultRetention
 	}
+	return NewManagerWithStorage(retention, NewMemoryStorage())
+}
+
+// NewManagerWithStorage creates a Manager on top of the given storage.
+// A zero retention keeps results forever.
+func NewManagerWithStorage(retention time.Duration, storage Storage) *Manager {
 	return &Manager{
-		results: make([]Result, 0, maxSize),
-		maxSize: maxSize,
+		storage:   storage,
+		retention: retention,
 	}
 }
 
 func (m *Manager) Add(r Result) {
+	if err := m.storage.Add(r); err != nil {
+		log.Error().Err(err).Msg("Failed to store result")
+	}
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	if m.latest == nil || !r.Time.Before(m.latest.Time) {
+		m.latest = &r
+	}
+	m.mu.Unlock()
 
-	// Append
-	m.results = append(m.results, r)
+	// Drop results older than the retention period
+	if m.retention > 0 {
+		if err := m.storage.Prune(r.Time.Add(-m.retention)); err != nil {
+			log.Error().Err(err).Msg("Failed to prune old results")
+		}
+	}
+}
 
-	// Trim if needed (keep latest maxSize)
-	if len(m.results) > m.maxSize {
-		m.results = m.results[len(m.results)-m.maxSize:]
+// FormatBytes renders a byte count with decimal units, e.g. "1.5 GB".
+func FormatBytes(n uint64) string {
+	switch {
+	case n >= 1e12:
+		return fmt.Sprintf("%.2f TB", float64(n)/1e12)
+	case n >= 1e9:
+		return fmt.Sprintf("%.2f GB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1f MB", float64(n)/1e6)
+	default:
+		return fmt.Sprintf("%.0f KB", float64(n)/1e3)
 	}
 }
 
-func (m *Manager) GetLast24hSummary(now time.Time, dlThreshold, ulThreshold float64) Summary {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// DataUsed returns the bytes transferred by tests within [from, to].
+func (m *Manager) DataUsed(from, to time.Time) (uint64, error) {
+	results, err := m.storage.Query(from, to)
+	if err != nil {
+		return 0, err
+	}
+	received, sent := traffic(results)
+	return received + sent, nil
+}
 
-	cutoff := now.Add(-24 * time.Hour)
-	var filtered []Result
+// traffic sums the bytes the tests in results transferred, lite checks included.
+func traffic(results []Result) (received, sent uint64) {
+	for _, r := range results {
+		received += r.BytesReceived
+		sent += r.BytesSent
+	}
+	return received, sent
+}
+
+// Query returns stored results within [from, to]; zero bounds are open.
+func (m *Manager) Query(from, to time.Time) ([]Result, error) {
+	return m.storage.Query(from, to)
+}
 
-	for _, r := range m.results {
-		if r.Time.After(cutoff) {
+// Latest returns the most recent result; ok is false if nothing has been stored yet.
+func (m *Manager) Latest() (r Result, ok bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.latest == nil {
+		results, err := m.storage.Query(time.Time{}, time.Time{})
+		if err != nil {
+			return Result{}, false, err
+		}
+		if len(results) == 0 {
+			return Result{}, false, nil
+		}
+		m.latest = &results[len(results)-1]
+	}
+	return *m.latest, true, nil
+}
+
+// History returns every stored result in chronological order.
+func (m *Manager) History() ([]Result, error) {
+	return m.storage.Query(time.Time{}, time.Time{})
+}
+
+// Thresholds are the speeds below which a test counts as a low-speed event in a Summary, zero for none.
+type Thresholds struct {
+	Download, Upload float64 // Mbps
+}
+
+func (m *Manager) GetLast24hSummary(now time.Time, dlThreshold, ulThreshold float64, loc *time.Location) Summary {
+	return m.GetSummary(now, 24*time.Hour, dlThreshold, ulThreshold, loc)
+}
+
+// GetSummary summarizes the period ending at now, with hours of the day in loc. Monitor, DNS
+// and endpoint data only cover what is still kept in memory.
+func (m *Manager) GetSummary(now time.Time, period time.Duration, dlThreshold, ulThreshold float64, loc *time.Location) Summary {
+	s := m.Summarize(now.Add(-period), now, Thresholds{Download: dlThreshold, Upload: ulThreshold}, loc)
+	// Titled by its length rather than its bounds
+	s.From, s.To = time.Time{}, time.Time{}
+	return s
+}
+
+// Summarize summarizes the fixed window [from, to], e.g. a range of past days, with hours of
+// the day in loc. Every summary is built by it, so commands and reports slice the history the
+// same way.
+func (m *Manager) Summarize(from, to time.Time, t Thresholds, loc *time.Location) Summary {
+	s := m.summarizeResults(from, to, t, loc)
+	m.summarizeMonitor(&s, from, to)
+	s.DNS = m.summarizeDNS(from, to)
+	s.Endpoints = m.summarizeEndpoints(from, to)
+	s.Period = to.Sub(from)
+	s.From, s.To = from, to
+	return s
+}
+
+func (m *Manager) summarizeResults(from, to time.Time, t Thresholds, loc *time.Location) Summary {
+	results, err := m.storage.Query(from, to)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results")
+		return Summary{}
+	}
+
+	// Lite checks are counted but kept out of the speed statistics
+	var filtered []Result
+	liteChecks, maintenance := 0, 0
+	for _, r := range results {
+		if r.Maintenance {
+			maintenance++
+		}
+		if r.Lite {
+			liteChecks++
+		} else {
 			filtered = append(filtered, r)
 		}
 	}
 
+	avail := m.availability(results, from, to)
+	received, sent := traffic(results)
 	if len(filtered) == 0 {
-		return Summary{}
+		return Summary{LiteChecks: liteChecks, Maintenance: maintenance, Availability: avail, BytesReceived: received, BytesSent: sent}
 	}
 
 	s := Summary{
+		LiteChecks:  liteChecks,
+		Maintenance: maintenance,
 		TotalTests:  len(filtered),
 		MinDownload: math.MaxFloat64,
 		MinUpload:   math.MaxFloat64,
 		MinPing:     time.Duration(math.MaxInt64),
 	}
 
-	var sumDL, sumUL float64
-	var sumPing time.Duration
+	var sumDL float64
+	var sumPing, sumJitter, sumBloat time.Duration
+	var bloatTests int
+	var downloads, uploads []float64
+	var pings []time.Duration
+
+	s.Engines = summarizeBy(filtered, Result.Label)
+	s.Servers = summarizeBy(filtered, Result.ServerLabel)
 
 	for _, r := range filtered {
 		if r.Error != nil {
@@ -99,8 +382,17 @@ func (m *Manager) GetLast24hSummary(now time.Time, dlThreshold, ulThreshold floa
 		}
 
 		sumDL += r.Download
-		sumUL += r.Upload
 		sumPing += r.Ping
+		downloads, pings = append(downloads, r.Download), append(pings, r.Ping)
+		if !r.NoUpload {
+			uploads = append(uploads, r.Upload)
+			s.MinUpload, s.MaxUpload = min(s.MinUpload, r.Upload), max(s.MaxUpload, r.Upload)
+		}
+		sumJitter += r.Jitter
+		if increase, ok := r.BufferbloatIncrease(); ok {
+			sumBloat += increase
+			bloatTests++
+		}
 
 		if r.Download < s.MinDownload {
 			s.MinDownload = r.Download
@@ -109,13 +401,6 @@ func (m *Manager) GetLast24hSummary(now time.Time, dlThreshold, ulThreshold floa
 			s.MaxDownload = r.Download
 		}
 
-		if r.Upload < s.MinUpload {
-			s.MinUpload = r.Upload
-		}
-		if r.Upload > s.MaxUpload {
-			s.MaxUpload = r.Upload
-		}
-
 		if r.Ping < s.MinPing {
 			s.MinPing = r.Ping
 		}
@@ -123,17 +408,36 @@ func (m *Manager) GetLast24hSummary(now time.Time, dlThreshold, ulThreshold floa
 			s.MaxPing = r.Ping
 		}
 
+		if r.Jitter > s.MaxJitter {
+			s.MaxJitter = r.Jitter
+		}
+
 		if r.AlertSent {
 			s.AlertsCount++
+			if r.Severity == SeverityWarning {
+				s.WarningsCount++
+			}
 		}
 
 		// Identify low speed events based on thresholds provided (or just rely on AlertSent)
 		// Prompt says "brief list of low-speed events if any".
-		if r.Download < dlThreshold || r.Upload < ulThreshold {
+		if r.Download < t.Download || (!r.NoUpload && r.Upload < t.Upload) {
 			s.LowSpeedEvents = append(s.LowSpeedEvents, r)
 		}
 	}
 
+	m.mu.Lock()
+	threshold, buckets := m.jitterThreshold, m.speedBuckets
+	s.Plan = m.plan
+	m.mu.Unlock()
+	s.JitterPeriods = jitterPeriods(filtered, threshold)
+	s.Hourly = hourly(filtered, loc)
+	s.Timeline = timeline(filtered, from.In(loc), to.In(loc))
+	s.Histogram = histogram(filtered, buckets)
+	s.Availability = avail
+	s.BytesReceived, s.BytesSent = received, sent
+	s.LongestStreak = longestStreak(failureStreaks(results, to))
+
 	validTests := 0
 	for _, r := range filtered {
 		if r.Error == nil {
@@ -143,40 +447,222 @@ func (m *Manager) GetLast24hSummary(now time.Time, dlThreshold, ulThreshold floa
 
 	if validTests > 0 {
 		s.AvgDownload = sumDL / float64(validTests)
-		s.AvgUpload = sumUL / float64(validTests)
 		s.AvgPing = sumPing / time.Duration(validTests)
+		s.AvgJitter = sumJitter / time.Duration(validTests)
+		if bloatTests > 0 {
+			s.AvgBufferbloat = sumBloat / time.Duration(bloatTests)
+		}
+		s.MedianDownload, s.StdDevDownload = percentile(downloads, 50), stdDev(downloads)
+		s.MedianPing, s.StdDevPing = percentile(pings, 50), stdDev(pings)
 	} else {
 		// Reset mins if no valid tests
 		s.MinDownload = 0
 		s.MinUpload = 0
 		s.MinPing = 0
 	}
+	s.UploadTests = len(uploads)
+	if len(uploads) > 0 {
+		s.AvgUpload = mean(uploads)
+		s.MedianUpload, s.StdDevUpload = percentile(uploads, 50), stdDev(uploads)
+	} else {
+		s.MinUpload = 0
+	}
+
+	return s
+}
+
+// summarizeBy computes averages per key, such as the engine (and IP family) or the server;
+// results without one are grouped under "".
+func summarizeBy(results []Result, key func(Result) string) map[string]EngineSummary {
+	engines := make(map[string]EngineSummary)
+	for _, r := range results {
+		e := engines[key(r)]
+		e.TotalTests++
+		if r.AlertSent {
+			e.AlertsCount++
+		}
+		if r.Error != nil {
+			e.FailedTests++
+		} else {
+			// Accumulate sums, turned into averages below
+			e.AvgDownload += r.Download
+			e.AvgPing += r.Ping
+			if !r.NoUpload {
+				e.AvgUpload += r.Upload
+				e.UploadTests++
+			}
+		}
+		engines[key(r)] = e
+	}
+	for name, e := range engines {
+		if valid := e.TotalTests - e.FailedTests; valid > 0 {
+			e.AvgDownload /= float64(valid)
+			e.AvgPing /= time.Duration(valid)
+		}
+		if e.UploadTests > 0 {
+			e.AvgUpload /= float64(e.UploadTests)
+		}
+		engines[name] = e
+	}
+	return engines
+}
+
+// breakdown lists the averages of each group, such as an engine or a server, by name.
+func breakdown(groups map[string]EngineSummary) string {
+	var sb strings.Builder
+	for _, name := range sortedKeys(groups) {
+		e := groups[name]
+		if name == "" {
+			name = i18n.T("report.unknown")
+		}
+		if e.UploadTests > 0 {
+			sb.WriteString(i18n.T("report.engine", html.EscapeString(name), e.AvgDownload, e.AvgUpload, e.AvgPing.Milliseconds(), e.TotalTests))
+		} else {
+			sb.WriteString(i18n.T("report.engine_no_upload", html.EscapeString(name), e.AvgDownload, e.AvgPing.Milliseconds(), e.TotalTests))
+		}
+		if e.FailedTests > 0 {
+			sb.WriteString(i18n.T("report.engine_failed", e.FailedTests))
+		}
+		if e.AlertsCount > 0 {
+			sb.WriteString(i18n.T("report.engine_alerts", e.AlertsCount))
+		}
+		sb.WriteString(")\n")
+	}
+	return sb.String()
+}
 
+// FormatPeriod renders whole days as "7d" and anything else as a Go duration
+// without trailing zero units, e.g. "12h" or "1h30m" instead of "12h0m0s" and "1h30m0s".
+func FormatPeriod(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	s := d.String()
+	if d < time.Minute {
+		return s
+	}
+	s = strings.TrimSuffix(s, "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
 	return s
 }
 
 func (s Summary) String() string {
 	var sb strings.Builder
-	sb.WriteString("📊 <b>Daily Report</b> (Last 24h)\n")
-	sb.WriteString(fmt.Sprintf("Tests run: %d\n", s.TotalTests))
+	switch {
+	case !s.From.IsZero():
+		sb.WriteString(i18n.T("report.range_title", s.From.Format("2006-01-02 15:04"), s.To.Format("2006-01-02 15:04")))
+	case s.Period == 0 || s.Period == 24*time.Hour:
+		sb.WriteString(i18n.T("report.daily_title"))
+	default:
+		sb.WriteString(i18n.T("report.title", FormatPeriod(s.Period)))
+	}
+	sb.WriteString(i18n.T("report.tests", s.TotalTests))
+	if s.LiteChecks > 0 {
+		sb.WriteString(i18n.T("report.lite", s.LiteChecks))
+	}
+	if s.Maintenance > 0 {
+		sb.WriteString(i18n.T("report.maintenance", s.Maintenance))
+	}
+	sb.WriteString("\n")
 	if s.TotalTests > 0 {
-		sb.WriteString(fmt.Sprintf("Alerts triggered: %d\n\n", s.AlertsCount))
-		sb.WriteString(fmt.Sprintf("📉 <b>Download</b>:\nAvg: %.2f | Min: %.2f | Max: %.2f Mbps\n", s.AvgDownload, s.MinDownload, s.MaxDownload))
-		sb.WriteString(fmt.Sprintf("📈 <b>Upload</b>:\nAvg: %.2f | Min: %.2f | Max: %.2f Mbps\n", s.AvgUpload, s.MinUpload, s.MaxUpload))
-		sb.WriteString(fmt.Sprintf("📶 <b>Ping</b>:\nAvg: %dms | Min: %dms | Max: %dms\n", s.AvgPing.Milliseconds(), s.MinPing.Milliseconds(), s.MaxPing.Milliseconds()))
+		if s.WarningsCount > 0 {
+			sb.WriteString(i18n.T("report.alerts_severity", s.AlertsCount, s.WarningsCount, s.AlertsCount-s.WarningsCount))
+		} else {
+			sb.WriteString(i18n.T("report.alerts", s.AlertsCount))
+		}
+		sb.WriteString(s.Availability.line())
+		if st := s.LongestStreak; st.Tests > 0 {
+			sb.WriteString(i18n.T("report.streak", st.Tests, FormatPeriod(st.Duration().Round(time.Minute))))
+		}
+		sb.WriteString(i18n.T("report.download", s.AvgDownload, s.StdDevDownload, s.MedianDownload, s.MinDownload, s.MaxDownload))
+		sb.WriteString(s.Timeline.line())
+		if s.UploadTests > 0 {
+			sb.WriteString(i18n.T("report.upload", s.AvgUpload, s.StdDevUpload, s.MedianUpload, s.MinUpload, s.MaxUpload))
+		}
+		sb.WriteString(planLines(s.Plan, s.AvgDownload, s.AvgUpload))
+		sb.WriteString(i18n.T("report.ping", s.AvgPing.Milliseconds(), s.StdDevPing.Milliseconds(), s.MedianPing.Milliseconds(), s.MinPing.Milliseconds(), s.MaxPing.Milliseconds()))
+		if s.MaxJitter > 0 {
+			sb.WriteString(i18n.T("report.jitter", s.AvgJitter.Milliseconds(), s.MaxJitter.Milliseconds()))
+		}
+		if s.AvgBufferbloat > 0 {
+			sb.WriteString(i18n.T("report.bufferbloat", s.AvgBufferbloat.Milliseconds(), GradeBufferbloat(s.AvgBufferbloat)))
+		}
+	}
+	if s.BytesReceived+s.BytesSent > 0 {
+		sb.WriteString(i18n.T("report.traffic", FormatBytes(s.BytesReceived), FormatBytes(s.BytesSent)))
 	}
 
-	if len(s.LowSpeedEvents) > 0 {
-		sb.WriteString("\n⚠️ <b>Low Speed Events:</b>\n")
+	// Averages over a day hide the evening slowdown that the hours show
+	if slowest, fastest, ok := slowestFastest(s.Hourly); ok && (s.Period == 0 || s.Period == 24*time.Hour) {
+		sb.WriteString(i18n.T("report.hourly", hourlySparkline(s.Hourly), hourAxis))
+		sb.WriteString(i18n.T("report.hourly_range", slowest, s.Hourly[slowest].AvgDownload, fastest, s.Hourly[fastest].AvgDownload))
+	}
+	if lines := histogramLines(s.Histogram); lines != "" {
+		sb.WriteString(i18n.T("report.histogram") + lines)
+	}
+	if len(s.Declines) > 0 {
+		sb.WriteString(i18n.T("report.trend"))
+		for _, d := range s.Declines {
+			sb.WriteString("- " + d + "\n")
+		}
+	}
+
+	if len(s.Engines) > 1 {
+		sb.WriteString(i18n.T("report.engines") + breakdown(s.Engines))
+	}
+	// Bad results piling up on one server point at the server rather than the line
+	if len(s.Servers) > 1 {
+		sb.WriteString(i18n.T("report.servers") + breakdown(s.Servers))
+	}
+
+	if s.MonitorProbes > 0 {
+		sb.WriteString(i18n.T("report.monitor"))
+		sb.WriteString(i18n.T("report.monitor_line", s.MonitorAvgPing.Milliseconds(), float64(s.MonitorLost)*100/float64(s.MonitorProbes), s.MonitorProbes))
+	}
+
+	if len(s.DNS) > 0 {
+		sb.WriteString(i18n.T("report.dns"))
+		for _, name := range sortedKeys(s.DNS) {
+			d := s.DNS[name]
+			sb.WriteString(i18n.T("report.dns_line", html.EscapeString(name), d.Avg.Milliseconds(), d.Lookups))
+			if d.Failed > 0 {
+				sb.WriteString(i18n.T("report.dns_failed", d.Failed))
+			}
+			if d.Slow > 0 {
+				sb.WriteString(i18n.T("report.dns_slow", d.Slow))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(s.Endpoints) > 0 {
+		sb.WriteString(i18n.T("report.endpoints"))
+		for _, u := range sortedKeys(s.Endpoints) {
+			e := s.Endpoints[u]
+			sb.WriteString(i18n.T("report.endpoint", html.EscapeString(u), e.Availability(), e.Avg.Milliseconds()))
+		}
+	}
+
+	if len(s.LowSpeedEvents) > 0 || len(s.JitterPeriods) > 0 {
+		sb.WriteString(i18n.T("report.low_speed"))
+		for _, p := range s.JitterPeriods {
+			sb.WriteString(i18n.T("report.jitter_period", p.Start.Format("15:04"), p.End.Format("15:04"), p.Max.Milliseconds(), p.Tests))
+		}
 		// Limit to last 5 to avoid spam
 		count := 0
 		for i := len(s.LowSpeedEvents) - 1; i >= 0; i-- {
 			if count >= 5 {
-				sb.WriteString("...and more\n")
+				sb.WriteString(i18n.T("report.low_speed_more"))
 				break
 			}
 			e := s.LowSpeedEvents[i]
-			sb.WriteString(fmt.Sprintf("- %s: ▼%.1f ▲%.1f Mbps, %dms\n", e.Time.Format("15:04"), e.Download, e.Upload, e.Ping.Milliseconds()))
+			key := "report.low_speed_event"
+			if e.Maintenance {
+				key = "report.low_speed_maintenance"
+			}
+			sb.WriteString(i18n.T(key, e.Time.Format("15:04"), e.Download, e.Upload, e.Ping.Milliseconds()))
 			count++
 		}
 	}
@@ -1,12 +1,17 @@
 package stats
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestManager_GetLast24hSummary(t *testing.T) {
-	mgr := NewManager(10)
+	mgr := NewManager(48 * time.Hour)
 	now := time.Now()
 
 	// Add some results
@@ -34,7 +39,7 @@ func TestManager_GetLast24hSummary(t *testing.T) {
 		AlertSent: true, // Should count as alert
 	})
 
-	summary := mgr.GetLast24hSummary(now, 80.0, 100.0)
+	summary := mgr.GetLast24hSummary(now, 80.0, 100.0, time.UTC)
 
 	if summary.TotalTests != 3 {
 		t.Errorf("Expected 3 tests, got %d", summary.TotalTests)
@@ -54,6 +59,14 @@ func TestManager_GetLast24hSummary(t *testing.T) {
 		t.Errorf("Expected min download 10, got %f", summary.MinDownload)
 	}
 
+	// Median DL: 50, standard deviation of 100, 50 and 10: ~36.82
+	if summary.MedianDownload != 50 {
+		t.Errorf("Expected median download 50, got %f", summary.MedianDownload)
+	}
+	if summary.StdDevDownload < 36.7 || summary.StdDevDownload > 36.9 {
+		t.Errorf("Expected download standard deviation ~36.82, got %f", summary.StdDevDownload)
+	}
+
 	// Check low speed events
 	// DL < 80: 50 and 10.
 	// UL < 100: 50, 20, 5. (All 3 are < 100).
@@ -62,3 +75,671 @@ func TestManager_GetLast24hSummary(t *testing.T) {
 		t.Errorf("Expected 3 low speed events, got %d", len(summary.LowSpeedEvents))
 	}
 }
+
+func TestManager_GetSummaryPeriod(t *testing.T) {
+	mgr := NewManager(0)
+	now := time.Now()
+
+	mgr.Add(Result{Time: now.Add(-1 * time.Hour), Download: 100})
+	mgr.Add(Result{Time: now.Add(-3 * 24 * time.Hour), Download: 50})
+	mgr.Add(Result{Time: now.Add(-8 * 24 * time.Hour), Download: 10}) // beyond 7 days
+
+	summary := mgr.GetSummary(now, 7*24*time.Hour, 0, 0, time.UTC)
+	if summary.TotalTests != 2 {
+		t.Errorf("Expected 2 tests in the last 7 days, got %d", summary.TotalTests)
+	}
+	if !strings.Contains(summary.String(), "(Last 7d)") {
+		t.Errorf("Expected report title for 7d, got %q", summary.String())
+	}
+}
+
+func TestManager_Retention(t *testing.T) {
+	mgr := NewManager(24 * time.Hour)
+	now := time.Now()
+
+	mgr.Add(Result{Time: now.Add(-30 * time.Hour), Download: 100})
+	mgr.Add(Result{Time: now.Add(-2 * time.Hour), Download: 100})
+	mgr.Add(Result{Time: now, Download: 100})
+
+	history, err := mgr.History()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != 2 {
+		t.Errorf("Expected 2 results within retention, got %d", len(history))
+	}
+}
+
+func TestManager_Latest(t *testing.T) {
+	storage := NewMemoryStorage()
+	now := time.Now()
+	_ = storage.Add(Result{Time: now.Add(-time.Hour), Download: 50})
+
+	// A manager restarted on top of existing storage still knows the last reading
+	mgr := NewManagerWithStorage(0, storage)
+	r, ok, err := mgr.Latest()
+	if err != nil || !ok || r.Download != 50 {
+		t.Fatalf("Latest() = %v, %v, %v, want the stored result", r, ok, err)
+	}
+
+	mgr.Add(Result{Time: now, Download: 90, AlertSent: true})
+	if r, _, _ := mgr.Latest(); r.Download != 90 || !r.AlertSent {
+		t.Errorf("Latest() = %+v, want the newest result", r)
+	}
+
+	if _, ok, _ := NewManager(0).Latest(); ok {
+		t.Error("expected no result from an empty manager")
+	}
+}
+
+func TestManager_GetAggregate(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	// Ten days back, so the first three fall outside a week
+	for day := range 10 {
+		for i := range 4 {
+			mgr.Add(Result{
+				Time:      now.AddDate(0, 0, -day).Add(-time.Duration(i) * time.Hour),
+				Download:  float64(100 - day),
+				Upload:    20,
+				Ping:      time.Duration(10+i) * time.Millisecond,
+				AlertSent: day == 2 && i == 0,
+			})
+		}
+	}
+	mgr.Add(Result{Time: now.Add(-time.Minute), Error: errors.New("timeout")})
+
+	a := mgr.GetAggregate(now, 7, time.UTC)
+	if a.TotalTests != 29 || a.FailedTests != 1 || a.AlertsCount != 1 || len(a.Daily) != 7 {
+		t.Fatalf("got %d tests, %d failed, %d alerts over %d days", a.TotalTests, a.FailedTests, a.AlertsCount, len(a.Daily))
+	}
+	if a.DownloadP5 != 94 || a.DownloadP50 != 97 || a.DownloadP95 != 100 {
+		t.Errorf("download percentiles = %v/%v/%v, want 94/97/100", a.DownloadP5, a.DownloadP50, a.DownloadP95)
+	}
+	if a.PingP50 != 11*time.Millisecond || a.PingP95 != 13*time.Millisecond {
+		t.Errorf("ping percentiles = %v/%v", a.PingP50, a.PingP95)
+	}
+	if worst := a.WorstDays(3); len(worst) != 3 || worst[0].AvgDownload != 94 || worst[0].Date.Day() != 4 {
+		t.Errorf("unexpected worst days: %+v", worst)
+	}
+	if !strings.Contains(a.String(), "Worst days") {
+		t.Errorf("worst days missing from the summary: %s", a.String())
+	}
+}
+
+func TestManager_EngineBreakdown(t *testing.T) {
+	mgr := NewManager(48 * time.Hour)
+	now := time.Now()
+
+	mgr.Add(Result{Time: now.Add(-3 * time.Hour), Download: 100, Upload: 50, Engine: "ookla"})
+	mgr.Add(Result{Time: now.Add(-2 * time.Hour), Download: 200, Upload: 70, Engine: "ookla"})
+	mgr.Add(Result{Time: now.Add(-1 * time.Hour), Download: 40, Upload: 20, Engine: "cloudflare"})
+	mgr.Add(Result{Time: now.Add(-30 * time.Minute), Error: errors.New("timeout"), Engine: "cloudflare"})
+
+	summary := mgr.GetLast24hSummary(now, 80.0, 100.0, time.UTC)
+
+	ookla := summary.Engines["ookla"]
+	if ookla.TotalTests != 2 || ookla.AvgDownload != 150 || ookla.AvgUpload != 60 {
+		t.Errorf("Unexpected ookla breakdown: %+v", ookla)
+	}
+	cf := summary.Engines["cloudflare"]
+	if cf.TotalTests != 2 || cf.FailedTests != 1 || cf.AvgDownload != 40 {
+		t.Errorf("Unexpected cloudflare breakdown: %+v", cf)
+	}
+}
+
+func TestManager_UploadNotMeasured(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	loc := time.UTC
+	now := time.Date(2024, 5, 8, 20, 30, 0, 0, loc)
+
+	// The http engine without HTTP_PROBE_UPLOAD_URL leaves upload out of every average
+	for day := 1; day <= 3; day++ {
+		mgr.Add(Result{Time: now.AddDate(0, 0, -day), Engine: "ookla", Download: 100, Upload: 40})
+		mgr.Add(Result{Time: now.AddDate(0, 0, -day), Engine: "ookla", Download: 100, Upload: 20})
+		mgr.Add(Result{Time: now.AddDate(0, 0, -day), Engine: "http", Download: 90, NoUpload: true})
+	}
+
+	s := mgr.Summarize(now.AddDate(0, 0, -1).Add(-time.Hour), now, Thresholds{Download: 80, Upload: 10}, loc)
+	if ookla, http := s.Engines["ookla"], s.Engines["http"]; ookla.AvgUpload != 30 || http.UploadTests != 0 || http.AvgDownload != 90 {
+		t.Errorf("Unexpected engine breakdown: ookla %+v, http %+v", ookla, http)
+	}
+	if text := breakdown(s.Engines); !strings.Contains(text, "- http: ▼90.0 Mbps, 0ms (1 tests") {
+		t.Errorf("Breakdown shows upload for http:\n%s", text)
+	}
+	if h := s.Hourly[20]; h.UploadTests != 2 || h.AvgUpload != 30 {
+		t.Errorf("Unexpected 20:00 summary: %+v", h)
+	}
+
+	a := mgr.GetHourAnalysis(now, 7, loc)
+	if len(a.Hours) != 1 || a.Hours[0].AvgUpload != 30 || a.Hours[0].UploadTests != 6 {
+		t.Errorf("Unexpected hour analysis: %+v", a.Hours)
+	}
+
+	b := mgr.GetBaseline("http", now, 7, loc)
+	if b.Samples != 3 || b.UploadSamples != 0 {
+		t.Fatalf("GetBaseline(http) = %+v, want 3 samples without upload", b)
+	}
+	if notes := BelowBaseline(Result{Engine: "http", Download: 90, NoUpload: true}, b, 30); len(notes) != 0 {
+		t.Errorf("unmeasured upload compared with the baseline: %v", notes)
+	}
+}
+
+func TestManager_ServerBreakdown(t *testing.T) {
+	mgr := NewManager(48 * time.Hour)
+	now := time.Now()
+
+	mgr.Add(Result{Time: now.Add(-3 * time.Hour), Download: 30, Upload: 10, ServerID: "1", ServerName: "Acme, Kyiv", AlertSent: true})
+	mgr.Add(Result{Time: now.Add(-2 * time.Hour), Download: 50, Upload: 10, ServerID: "1", ServerName: "Acme, Kyiv", AlertSent: true})
+	mgr.Add(Result{Time: now.Add(-1 * time.Hour), Download: 200, Upload: 90, ServerID: "2"})
+
+	summary := mgr.GetLast24hSummary(now, 80.0, 20.0, time.UTC)
+	if acme := summary.Servers["Acme, Kyiv"]; acme.TotalTests != 2 || acme.AlertsCount != 2 || acme.AvgDownload != 40 {
+		t.Errorf("Unexpected Acme breakdown: %+v", acme)
+	}
+	if s := summary.Servers["#2"]; s.TotalTests != 1 || s.AlertsCount != 0 {
+		t.Errorf("Unexpected breakdown of server 2: %+v", s)
+	}
+	if want := "- Acme, Kyiv: ▼40.0 ▲10.0 Mbps, 0ms (2 tests, 2 alerted)"; !strings.Contains(summary.String(), want) {
+		t.Errorf("Report lacks %q:\n%s", want, summary.String())
+	}
+
+	// Results stored before the server had fields of its own
+	var old Result
+	if err := json.Unmarshal([]byte(`{"time":"2024-05-01T10:00:00Z","meta":{"server_id":"7","sponsor":"Acme","server_name":"Lviv"}}`), &old); err != nil {
+		t.Fatal(err)
+	}
+	if old.ServerID != "7" || old.ServerLabel() != "Acme, Lviv" {
+		t.Errorf("Expected server 7 Acme, Lviv from meta, got %q %q", old.ServerID, old.ServerLabel())
+	}
+}
+
+func TestManager_PingMonitor(t *testing.T) {
+	mgr := NewManager(0)
+	now := time.Now()
+
+	// Probes arrive in time order, one bucket per minute
+	mgr.AddPing(now.Add(-25*time.Hour), 10*time.Millisecond, true) // outside the window
+	mgr.AddPing(now.Add(-2*time.Minute), 20*time.Millisecond, true)
+	mgr.AddPing(now.Add(-2*time.Minute), 40*time.Millisecond, true)
+	mgr.AddPing(now.Add(-time.Minute), 0, false)
+	if len(mgr.pings) != 3 {
+		t.Errorf("Expected 3 ping buckets, got %d", len(mgr.pings))
+	}
+	mgr.AddOutage(Outage{Start: now.Add(-time.Hour), End: now.Add(-time.Hour + 30*time.Second), Target: "1.1.1.1:443"})
+
+	summary := mgr.GetLast24hSummary(now, 80.0, 100.0, time.UTC)
+
+	if summary.MonitorProbes != 3 || summary.MonitorLost != 1 {
+		t.Errorf("Expected 3 probes with 1 lost, got %d/%d", summary.MonitorProbes, summary.MonitorLost)
+	}
+	if summary.MonitorAvgPing != 30*time.Millisecond {
+		t.Errorf("Expected avg monitor ping 30ms, got %v", summary.MonitorAvgPing)
+	}
+	if len(summary.Outages) != 1 || summary.Outages[0].Duration() != 30*time.Second {
+		t.Errorf("Expected one 30s outage, got %v", summary.Outages)
+	}
+}
+
+func TestCompareFamilies(t *testing.T) {
+	results := []Result{
+		{Engine: "ookla", IPVersion: "ipv4", Download: 100, Upload: 50},
+		{Engine: "ookla", IPVersion: "ipv6", Download: 30, Upload: 45},
+		{Engine: "cloudflare", IPVersion: "ipv4", Download: 100, Upload: 50},
+		{Engine: "cloudflare", IPVersion: "ipv6", Download: 90, Upload: 48},
+	}
+	if notes := CompareFamilies(results, 50); len(notes) != 1 {
+		t.Errorf("Expected 1 degraded family, got %v", notes)
+	}
+
+	results[3].Error = errors.New("no route to host")
+	if notes := CompareFamilies(results, 50); len(notes) != 2 {
+		t.Errorf("Expected failed IPv6 to be reported, got %v", notes)
+	}
+}
+
+func TestManager_Compare(t *testing.T) {
+	mgr := NewManager(0)
+	now := time.Now()
+	mgr.Add(Result{Time: now.Add(-30 * time.Hour), Download: 100, Upload: 20, Ping: 10 * time.Millisecond})
+	mgr.Add(Result{Time: now.Add(-2 * time.Hour), Download: 78, Upload: 20, Ping: 30 * time.Millisecond, AlertSent: true})
+
+	c := mgr.Compare(now, 24*time.Hour, time.UTC)
+	if c.Previous.AvgDownload != 100 || c.Current.AvgDownload != 78 {
+		t.Fatalf("unexpected comparison: %+v", c)
+	}
+	msg := c.String()
+	for _, want := range []string{"100.0 → 78.0 Mbps (↘️ −22% 🔴)", "(→ 0%)", "10 → 30ms (↗️ +200% 🔴)", "Alerts: 0 → 1"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("comparison is missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	mgr := NewManager(0)
+	loc := time.UTC
+	now := time.Date(2024, 5, 8, 20, 30, 0, 0, loc)
+
+	// Evenings are usually slower than mornings
+	for day := 1; day <= 3; day++ {
+		mgr.Add(Result{Time: now.AddDate(0, 0, -day), Engine: "ookla", Download: 50, Upload: 20})
+		mgr.Add(Result{Time: now.AddDate(0, 0, -day).Add(-12 * time.Hour), Engine: "ookla", Download: 200, Upload: 80})
+	}
+	mgr.Add(Result{Time: now.AddDate(0, 0, -1), Engine: "librespeed", Download: 500, Upload: 500})
+
+	b := mgr.GetBaseline("ookla", now, 7, loc)
+	if b.Samples != 3 || b.Download != 50 || b.Upload != 20 {
+		t.Fatalf("GetBaseline() = %+v, want 3 samples of 50/20", b)
+	}
+	if notes := BelowBaseline(Result{Download: 45, Upload: 19}, b, 30); len(notes) != 0 {
+		t.Errorf("normal evening speed reported: %v", notes)
+	}
+	if notes := BelowBaseline(Result{Download: 20, Upload: 19}, b, 30); len(notes) != 1 {
+		t.Errorf("expected only download below the baseline, got %v", notes)
+	}
+	if notes := BelowBaseline(Result{Download: 1, Upload: 1}, Baseline{Download: 50, Upload: 20, Samples: 2}, 30); len(notes) != 0 {
+		t.Errorf("baseline with too few samples used: %v", notes)
+	}
+}
+
+func TestManager_JitterPeriods(t *testing.T) {
+	mgr := NewManager(48 * time.Hour)
+	mgr.SetJitterThreshold(30 * time.Millisecond)
+	now := time.Now()
+
+	for i, jitter := range []int{40, 10, 50, 80, 0, 60, 5} {
+		r := Result{Time: now.Add(time.Duration(i-10) * time.Hour), Download: 100, Upload: 100, Jitter: time.Duration(jitter) * time.Millisecond}
+		if jitter == 0 {
+			r.Error = errors.New("timeout") // doesn't end the period
+		}
+		mgr.Add(r)
+	}
+
+	summary := mgr.GetLast24hSummary(now, 80.0, 100.0, time.UTC)
+	if len(summary.JitterPeriods) != 1 {
+		t.Fatalf("Expected one sustained high-jitter period, got %+v", summary.JitterPeriods)
+	}
+	if p := summary.JitterPeriods[0]; p.Tests != 3 || p.Max != 80*time.Millisecond || !p.Start.Equal(now.Add(-8*time.Hour)) {
+		t.Errorf("Unexpected period: %+v", p)
+	}
+	if !strings.Contains(summary.String(), "jitter up to 80ms (3 tests)") {
+		t.Errorf("jitter period missing from the report: %s", summary.String())
+	}
+}
+
+func TestManager_GetSLAReport(t *testing.T) {
+	mgr := NewManager(0)
+	now := time.Now()
+	from, to := now.Add(-10*time.Hour), now.Add(14*time.Hour)
+	target := SLATarget{Download: 100, Upload: 20, Compliance: 90, Uptime: 99}
+
+	mgr.Add(Result{Time: now.Add(-9 * time.Hour), Download: 120, Upload: 30})
+	mgr.Add(Result{Time: now.Add(-8 * time.Hour), Download: 80, Upload: 30})
+	mgr.Add(Result{Time: now.Add(-7 * time.Hour), Error: errors.New("timeout")})
+	mgr.Add(Result{Time: now.Add(-6 * time.Hour), Error: errors.New("timeout")})
+	mgr.Add(Result{Time: now.Add(-5 * time.Hour), Download: 150, Upload: 40})
+	mgr.Add(Result{Time: now.Add(-4 * time.Hour), Error: errors.New("timeout"), Maintenance: true})
+	mgr.Add(Result{Time: now.Add(-3 * time.Hour), Error: errors.New("fluke")})
+	mgr.Add(Result{Time: now.Add(-2 * time.Hour), Download: 110, Upload: 25})
+
+	r := mgr.GetSLAReport(target, from, to, now, time.Hour)
+	if r.Tests != 7 || r.Compliant != 3 || r.Remaining != 14 {
+		t.Errorf("Unexpected counts: %d tests, %d compliant, %d remaining", r.Tests, r.Compliant, r.Remaining)
+	}
+	if r.Downtime != 2*time.Hour {
+		t.Errorf("Expected 2h of downtime from the two failures in a row, got %s", r.Downtime)
+	}
+	// 17 of 21 tests at most, below the 90% promised
+	if !r.SpeedBreached() {
+		t.Errorf("Expected the speed SLA to be breached at %.1f%%", r.BestSpeedCompliance())
+	}
+	// 2h of a day is above the 14.4m 99% allows
+	if !r.UptimeBreached() {
+		t.Errorf("Expected the uptime SLA to be breached, allowed %s", r.AllowedDowntime())
+	}
+
+	target.Compliance, target.Uptime = 50, 90
+	if r := mgr.GetSLAReport(target, from, to, now, time.Hour); r.SpeedBreached() || r.UptimeBreached() {
+		t.Errorf("Unexpected breach: %s", r)
+	}
+}
+
+func TestBillingPeriod(t *testing.T) {
+	from, to := BillingPeriod(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), 15)
+	if !from.Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected period %s – %s", from, to)
+	}
+	from, _ = BillingPeriod(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), 15)
+	if !from.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Billing day should start a new period, got %s", from)
+	}
+}
+
+func TestAlertHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	h, err := NewAlertHistory(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	h.Trigger(AlertEvent{Metric: AlertDownload, Target: "ookla", Value: 42, Threshold: 80, Start: start})
+	// Still alerting keeps the first event
+	h.Trigger(AlertEvent{Metric: AlertDownload, Target: "ookla", Value: 30, Threshold: 80, Start: start.Add(time.Hour)})
+	h.Trigger(AlertEvent{Metric: AlertOutage, Start: start.Add(2 * time.Hour)})
+	h.Recover(AlertDownload, "ookla", start.Add(90*time.Minute))
+	h.Recover(AlertUpload, "ookla", start.Add(90*time.Minute)) // never alerted
+
+	// The events and their recovery survive a restart
+	h, err = NewAlertHistory(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := h.Recent(5)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", events)
+	}
+	if e := events[0]; e.Metric != AlertOutage || !e.Ongoing() {
+		t.Errorf("Expected the ongoing outage first, got %+v", e)
+	}
+	if e := events[1]; e.Value != 42 || e.Duration(start.Add(5*time.Hour)) != 90*time.Minute {
+		t.Errorf("Unexpected download event %+v", e)
+	}
+
+	// New events don't reuse loaded IDs
+	h.Trigger(AlertEvent{Metric: AlertDownload, Target: "ookla", Value: 50, Threshold: 80, Start: start.Add(3 * time.Hour)})
+	if events := h.Recent(1); events[0].ID != 3 {
+		t.Errorf("Expected ID 3, got %d", events[0].ID)
+	}
+}
+
+func TestManager_GetWeeklyRollup(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC) // a Wednesday
+	mgr.Add(Result{Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Download: 100, Upload: 20})
+	mgr.Add(Result{Time: time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC), Download: 50, Upload: 20})
+	mgr.Add(Result{Time: time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC), Download: 110, Upload: 20})
+	mgr.Add(Result{Time: time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC), Download: 10, Upload: 1}) // this week
+
+	r := mgr.GetWeeklyRollup(now, time.UTC)
+	if !r.Current.From.Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)) || !r.Current.To.Equal(time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected week %s – %s", r.Current.From, r.Current.To)
+	}
+	if r.Current.TotalTests != 2 || r.Previous.TotalTests != 1 {
+		t.Fatalf("Expected 2 tests and 1 the week before, got %d and %d", r.Current.TotalTests, r.Previous.TotalTests)
+	}
+	text := r.String()
+	for _, want := range []string{"Download: 80.0 Mbps (↘️ −20% 🔴)", "Best day</b>:\n- Wed 05-08", "Worst day</b>:\n- Mon 05-06"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Roll-up lacks %q:\n%s", want, text)
+		}
+	}
+
+	m := mgr.GetMonthlyRollup(now, time.UTC)
+	if m.Current.Days != 30 || m.Current.TotalTests != 0 || !m.Current.From.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected month: %d days from %s with %d tests", m.Current.Days, m.Current.From, m.Current.TotalTests)
+	}
+}
+
+func TestManager_Summarize(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, dl := range []float64{100, 40, 90, 20} {
+		mgr.Add(Result{Time: from.Add(time.Duration(i*12-6) * time.Hour), Download: dl, Upload: 50})
+	}
+
+	s := mgr.Summarize(from, from.Add(24*time.Hour), Thresholds{Download: 50}, time.UTC)
+	if s.TotalTests != 2 || s.AvgDownload != 65 {
+		t.Errorf("Expected the 2 tests of the day averaging 65 Mbps, got %d at %.1f", s.TotalTests, s.AvgDownload)
+	}
+	if len(s.LowSpeedEvents) != 1 || s.LowSpeedEvents[0].Download != 40 {
+		t.Errorf("Expected one low-speed event, got %+v", s.LowSpeedEvents)
+	}
+	if s.Period != 24*time.Hour || !s.From.Equal(from) {
+		t.Errorf("Unexpected window %s from %s", s.Period, s.From)
+	}
+}
+
+func TestHourlyBreakdown(t *testing.T) {
+	if got := sparkline([]float64{0, 5, 10, math.NaN()}); got != "▁▄█·" {
+		t.Errorf("sparkline = %q, want ▁▄█·", got)
+	}
+
+	// Hours are those of the configured time zone, not the machine's
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 1, 23, 30, 0, 0, loc)
+	for _, r := range []Result{
+		{Time: time.Date(2024, 5, 1, 8, 10, 0, 0, loc), Download: 100},
+		{Time: time.Date(2024, 5, 1, 8, 40, 0, 0, loc), Download: 80},
+		{Time: time.Date(2024, 5, 1, 21, 10, 0, 0, loc), Download: 30},
+		{Time: time.Date(2024, 5, 1, 22, 10, 0, 0, loc), Error: errors.New("timeout")},
+	} {
+		mgr.Add(r)
+	}
+
+	s := mgr.GetLast24hSummary(now, 0, 0, loc)
+	if h := s.Hourly[8]; h.Tests != 2 || h.AvgDownload != 90 {
+		t.Errorf("Unexpected 08:00 hour %+v", h)
+	}
+	if s.Hourly[22].Tests != 0 {
+		t.Error("Failed test counted in the hourly breakdown")
+	}
+	if !strings.Contains(s.String(), "Slowest 21:00 (▼30.0 Mbps), fastest 08:00 (▼90.0 Mbps)") {
+		t.Errorf("Hourly breakdown missing from the report:\n%s", s.String())
+	}
+}
+
+func TestManager_GetTrend(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := range 14 {
+		upload := 50.0
+		if i%2 == 1 {
+			upload = 40 // noisy but flat
+		}
+		mgr.Add(Result{Time: start.AddDate(0, 0, i), Download: 200 - float64(i)*30/13, Upload: upload})
+	}
+	mgr.Add(Result{Time: now.Add(-time.Hour), Download: 10, Upload: 1}) // today doesn't count yet
+
+	tr := mgr.GetTrend(now, 14, time.UTC)
+	if tr.Samples != 14 || math.Abs(tr.Download+15) > 0.01 {
+		t.Fatalf("Expected download -15%% over 14 days, got %.2f%% over %d", tr.Download, tr.Samples)
+	}
+	declines := tr.Declines(10)
+	if len(declines) != 1 || declines[0] != "Download down 15% over 14 days" {
+		t.Errorf("Unexpected declines %q", declines)
+	}
+	if d := tr.Declines(20); len(d) != 0 {
+		t.Errorf("A 15%% drop shouldn't warn at 20%%, got %q", d)
+	}
+	if d := mgr.GetTrend(now, 60, time.UTC).Declines(10); len(d) != 0 {
+		t.Errorf("14 days of 60 are too few to warn, got %q", d)
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	mgr.Add(Result{Time: at(9, 30), Download: 100, Upload: 50})
+	mgr.Add(Result{Time: at(10, 0), Error: errors.New("timeout")})
+	mgr.Add(Result{Time: at(10, 30), Error: errors.New("timeout")})
+	mgr.Add(Result{Time: at(11, 0), Download: 100, Upload: 50})
+	mgr.Add(Result{Time: at(12, 0), Error: errors.New("timeout")}) // a single failure isn't downtime
+	mgr.Add(Result{Time: at(12, 30), Download: 100, Upload: 50})
+	mgr.AddOutage(Outage{Start: at(10, 45), End: at(11, 15)}) // overlaps the failed tests
+	mgr.AddOutage(Outage{Start: at(14, 0), End: at(14, 10)})
+
+	s := mgr.Summarize(day, day.AddDate(0, 0, 1), Thresholds{}, time.UTC)
+	if a := s.Availability; a.Outages != 2 || a.Downtime != 85*time.Minute {
+		t.Fatalf("Expected 2 outages for 1h25m, got %d for %s", a.Outages, a.Downtime)
+	}
+	if want := "Uptime: 94.1% (outages: 2, down 1h25m)"; !strings.Contains(s.String(), want) {
+		t.Errorf("Report lacks %q:\n%s", want, s.String())
+	}
+
+	if a := mgr.Summarize(at(13, 0), at(15, 0), Thresholds{}, time.UTC).Availability; a.Outages != 1 || a.Uptime() < 91.6 || a.Uptime() > 91.7 {
+		t.Errorf("Expected one outage and 91.7%% uptime, got %d and %.2f%%", a.Outages, a.Uptime())
+	}
+}
+
+func TestManager_FailureStreaks(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	fail := func(d time.Duration) { mgr.Add(Result{Time: now.Add(-d), Error: errors.New("timeout")}) }
+	pass := func(d time.Duration) { mgr.Add(Result{Time: now.Add(-d), Download: 100, Upload: 50}) }
+	fail(30 * time.Hour) // longer, but more than a day ago
+	fail(29 * time.Hour)
+	fail(28 * time.Hour)
+	fail(27 * time.Hour)
+	pass(26 * time.Hour)
+	fail(6 * time.Hour)
+	fail(5*time.Hour + 30*time.Minute)
+	fail(5 * time.Hour)
+	pass(4*time.Hour + 30*time.Minute)
+	fail(time.Hour)
+
+	current, longest := mgr.FailureStreaks(now)
+	if current.Tests != 1 || !current.Ongoing || current.Duration() != time.Hour {
+		t.Errorf("Expected an ongoing streak of 1 test for 1h, got %+v", current)
+	}
+	if longest.Tests != 3 || longest.Duration() != 90*time.Minute {
+		t.Errorf("Expected the longest streak of 3 tests for 90m, got %d for %s", longest.Tests, longest.Duration())
+	}
+
+	s := mgr.GetLast24hSummary(now, 80, 20, time.UTC)
+	if want := "Longest failure streak: 3 tests / 1h30m"; !strings.Contains(s.String(), want) {
+		t.Errorf("Report lacks %q:\n%s", want, s.String())
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	mgr := NewManager(0)
+	mgr.SetSpeedBuckets([]float64{50, 100, 200})
+	now := time.Now()
+	for i, dl := range []float64{20, 70, 80, 150, 250} {
+		mgr.Add(Result{Time: now.Add(-time.Duration(i+1) * time.Hour), Download: dl, Upload: 10})
+	}
+	mgr.Add(Result{Time: now.Add(-10 * time.Hour), Error: errors.New("timeout")})
+
+	s := mgr.GetLast24hSummary(now, 80, 5, time.UTC)
+	var counts []int
+	for _, b := range s.Histogram {
+		counts = append(counts, b.Tests)
+	}
+	if len(counts) != 4 || counts[0] != 1 || counts[1] != 2 || counts[2] != 1 || counts[3] != 1 {
+		t.Fatalf("Expected 1, 2, 1 and 1 tests per bucket, got %v", counts)
+	}
+	for _, want := range []string{"<code>&lt;50     ██░░░░░░░░</code> 20% (1)", "<code>50–100  ████░░░░░░</code> 40% (2)", "<code>≥200    ██░░░░░░░░</code>"} {
+		if !strings.Contains(s.String(), want) {
+			t.Errorf("Report lacks %q:\n%s", want, s.String())
+		}
+	}
+}
+
+func TestManager_GetPlanReport(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	mgr.SetPlan(Plan{Download: 500, Upload: 100})
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	for i, dl := range []float64{450, 300, 250, 220} {
+		mgr.Add(Result{Time: from.AddDate(0, 0, i*5), Download: dl, Upload: 90})
+	}
+	mgr.Add(Result{Time: from.AddDate(0, 0, 25), Error: errors.New("timeout")})
+	mgr.Add(Result{Time: from.AddDate(0, 0, 26), Download: 5, Upload: 1, Maintenance: true})
+	mgr.Add(Result{Time: to.Add(time.Hour), Download: 10, Upload: 1}) // next month
+
+	r := mgr.GetPlanReport(Plan{Download: 500, Upload: 100}, from, to, time.UTC)
+	if r.Days != 30 || r.Measured != 4 || r.DownloadReached != 1 || r.UploadReached != 4 {
+		t.Fatalf("Expected 4 of 30 days' tests, 1 and 4 reaching 80%%, got %d, %d, %d and %d", r.Days, r.Measured, r.DownloadReached, r.UploadReached)
+	}
+	text := r.String()
+	for _, want := range []string{"(2024-04-01 – 2024-04-30)", "Automated speed tests: 5 (1 failed)", "avg 305.0 Mbps (61% of advertised)", "25% of tests reached 80%"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Plan report lacks %q:\n%s", want, text)
+		}
+	}
+
+	// Summaries keep maintenance tests in their figures, see Summary.Maintenance
+	s := mgr.Summarize(from, to, Thresholds{}, time.UTC)
+	if want := "Download avg 49% of the advertised 500 Mbps"; !strings.Contains(s.String(), want) {
+		t.Errorf("Summary lacks %q:\n%s", want, s.String())
+	}
+}
+
+func TestManager_GetHourAnalysis(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	for day := 1; day <= 7; day++ {
+		for hour := 8; hour < 24; hour += 2 {
+			dl := 200.0
+			switch {
+			case hour >= 20:
+				dl = 80 // evening congestion every day
+			case hour == 12 && day%2 == 0:
+				dl = 50 // a bad hour now and then
+			}
+			mgr.Add(Result{Time: now.AddDate(0, 0, -day).Add(time.Duration(hour) * time.Hour), Download: dl, Upload: 20})
+		}
+	}
+
+	a := mgr.GetHourAnalysis(now, 14, time.UTC)
+	if len(a.Hours) != 8 || a.Hours[0].Hour != 20 || a.Hours[0].Days != 7 || a.Hours[0].SlowDays != 7 {
+		t.Fatalf("Expected 20:00 slowest on all 7 days, got %+v", a.Hours)
+	}
+	if h := a.Hours[2]; h.Hour != 12 || h.Consistent() {
+		t.Errorf("Expected 12:00 third and not consistently slow, got %+v", h)
+	}
+	text := a.String()
+	for _, want := range []string{"- 20:00: ▼80.0 ▲20.0 Mbps, below the day's average on 7 of 7 days", "Consistently slower than the rest of the day: 20:00, 22:00"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Analysis lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestTraffic(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mgr.Add(Result{Time: now.Add(-2 * time.Hour), Download: 100, Upload: 20, BytesReceived: 1e9, BytesSent: 2e8})
+	mgr.Add(Result{Time: now.Add(-time.Hour), Download: 100, Upload: 20, BytesReceived: 4e8, BytesSent: 1e8})
+	mgr.Add(Result{Time: now.Add(-time.Minute), Lite: true, Download: 90, BytesReceived: 1e8})
+
+	s := mgr.GetLast24hSummary(now, 80, 10, time.UTC)
+	if s.BytesReceived != 1.5e9 || s.BytesSent != 3e8 {
+		t.Fatalf("Expected 1.5 GB down and 300 MB up, got %d and %d", s.BytesReceived, s.BytesSent)
+	}
+	if want := "Test traffic used: 1.50 GB down / 300.0 MB up"; !strings.Contains(s.String(), want) {
+		t.Errorf("Report lacks %q:\n%s", want, s.String())
+	}
+	if m := mgr.GetMonthlyRollup(now.AddDate(0, 1, 0), time.UTC).String(); !strings.Contains(m, "Test traffic used: 1.50 GB down") {
+		t.Errorf("Monthly report lacks the traffic:\n%s", m)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	mgr := NewManagerWithStorage(0, NewMemoryStorage())
+	to := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	for i := range 48 {
+		if i >= 10 && i < 12 {
+			continue // no tests for an hour
+		}
+		mgr.Add(Result{Time: from.Add(time.Duration(i)*30*time.Minute + time.Minute), Download: float64(i), Upload: 10})
+	}
+
+	s := mgr.Summarize(from, to, Thresholds{}, loc)
+	line := []rune(sparkline(s.Timeline.Download))
+	if len(line) != 48 || line[0] != '▁' || line[10] != sparkGap || line[47] != '█' {
+		t.Fatalf("Unexpected timeline %q", string(line))
+	}
+	// Times are those of the configured time zone
+	if want := "11:00 <code>" + string(line) + "</code> 11:00"; !strings.Contains(s.String(), want) {
+		t.Errorf("Report lacks %q:\n%s", want, s.String())
+	}
+}
@@ -0,0 +1,180 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Storage holds results for the Manager. Implementations must be safe for concurrent use.
+type Storage interface {
+	// Add stores a result.
+	Add(r Result) error
+	// Query returns results with from <= Time <= to in chronological order.
+	// A zero from or to leaves that side of the range open.
+	Query(from, to time.Time) ([]Result, error)
+	// Prune deletes results older than before.
+	Prune(before time.Time) error
+	Close() error
+}
+
+// MemoryStorage keeps results in a time-ordered slice. Nothing survives a restart.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	results []Result
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+func (s *MemoryStorage) Add(r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Keep the slice ordered; results nearly always arrive in order so this is an append
+	i := sort.Search(len(s.results), func(i int) bool {
+		return s.results[i].Time.After(r.Time)
+	})
+	s.results = append(s.results, Result{})
+	copy(s.results[i+1:], s.results[i:])
+	s.results[i] = r
+	return nil
+}
+
+func (s *MemoryStorage) Query(from, to time.Time) ([]Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return FilterRange(s.results, from, to), nil
+}
+
+func (s *MemoryStorage) Prune(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.results), func(i int) bool {
+		return !s.results[i].Time.Before(before)
+	})
+	s.results = append([]Result(nil), s.results[i:]...)
+	return nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+// FilterRange returns a copy of the results within [from, to] using the Storage.Query
+// semantics for zero bounds. The input must be in chronological order.
+func FilterRange(results []Result, from, to time.Time) []Result {
+	var out []Result
+	for _, r := range results {
+		if !from.IsZero() && r.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && r.Time.After(to) {
+			break
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// resultJSON is the on-disk representation of a Result.
+// Errors are flattened to their message since error values can't be decoded back.
+type resultJSON struct {
+	Time          time.Time         `json:"time"`
+	Download      float64           `json:"download"`
+	Upload        float64           `json:"upload"`
+	Ping          time.Duration     `json:"ping"`
+	Jitter        time.Duration     `json:"jitter,omitempty"`
+	PingDown      time.Duration     `json:"ping_download,omitempty"`
+	PingUp        time.Duration     `json:"ping_upload,omitempty"`
+	BytesReceived uint64            `json:"bytes_received,omitempty"`
+	BytesSent     uint64            `json:"bytes_sent,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	AlertSent     bool              `json:"alert_sent,omitempty"`
+	Severity      string            `json:"severity,omitempty"`
+	Engine        string            `json:"engine,omitempty"`
+	Server        string            `json:"server,omitempty"`
+	ServerID      string            `json:"server_id,omitempty"`
+	ServerName    string            `json:"server_name,omitempty"`
+	IPVersion     string            `json:"ip_version,omitempty"`
+	Interface     string            `json:"interface,omitempty"`
+	Lite          bool              `json:"lite,omitempty"`
+	NoUpload      bool              `json:"no_upload,omitempty"`
+	Maintenance   bool              `json:"maintenance,omitempty"`
+	ShareURL      string            `json:"share_url,omitempty"`
+	Meta          map[string]string `json:"meta,omitempty"`
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	j := resultJSON{
+		Time:          r.Time,
+		Download:      r.Download,
+		Upload:        r.Upload,
+		Ping:          r.Ping,
+		Jitter:        r.Jitter,
+		PingDown:      r.LoadedPingDown,
+		PingUp:        r.LoadedPingUp,
+		BytesReceived: r.BytesReceived,
+		BytesSent:     r.BytesSent,
+		AlertSent:     r.AlertSent,
+		Severity:      r.Severity,
+		Engine:        r.Engine,
+		Server:        r.Server,
+		ServerID:      r.ServerID,
+		ServerName:    r.ServerName,
+		IPVersion:     r.IPVersion,
+		Interface:     r.Interface,
+		Lite:          r.Lite,
+		NoUpload:      r.NoUpload,
+		Maintenance:   r.Maintenance,
+		ShareURL:      r.ShareURL,
+		Meta:          r.Meta,
+	}
+	if r.Error != nil {
+		j.Error = r.Error.Error()
+	}
+	return json.Marshal(j)
+}
+
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var j resultJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*r = Result{
+		Time:           j.Time,
+		Download:       j.Download,
+		Upload:         j.Upload,
+		Ping:           j.Ping,
+		Jitter:         j.Jitter,
+		LoadedPingDown: j.PingDown,
+		LoadedPingUp:   j.PingUp,
+		BytesReceived:  j.BytesReceived,
+		BytesSent:      j.BytesSent,
+		AlertSent:      j.AlertSent,
+		Severity:       j.Severity,
+		Engine:         j.Engine,
+		Server:         j.Server,
+		ServerID:       j.ServerID,
+		ServerName:     j.ServerName,
+		IPVersion:      j.IPVersion,
+		Interface:      j.Interface,
+		Lite:           j.Lite,
+		NoUpload:       j.NoUpload,
+		Maintenance:    j.Maintenance,
+		ShareURL:       j.ShareURL,
+		Meta:           j.Meta,
+	}
+	if j.Error != "" {
+		r.Error = errors.New(j.Error)
+	}
+	// Older results kept the speedtest.net server in Meta only
+	if r.ServerID == "" && j.Meta["server_id"] != "" {
+		r.ServerID, r.ServerName = j.Meta["server_id"], ServerName(j.Meta["sponsor"], j.Meta["server_name"])
+	}
+	return nil
+}
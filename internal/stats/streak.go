@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Streak is a run of failed tests in a row.
+type Streak struct {
+	Tests   int
+	Start   time.Time // first failed test
+	End     time.Time // test that got through, or the end of the window while Ongoing
+	Ongoing bool
+}
+
+// Duration returns how long the tests kept failing.
+func (s Streak) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// failureStreaks returns the runs of failed tests before end in order. Tests in maintenance
+// windows are skipped.
+func failureStreaks(results []Result, end time.Time) []Streak {
+	var streaks []Streak
+	var cur Streak
+	for _, r := range results {
+		if r.Maintenance || !r.Time.Before(end) {
+			continue
+		}
+		if r.Error != nil {
+			if cur.Tests == 0 {
+				cur.Start = r.Time
+			}
+			cur.Tests++
+			continue
+		}
+		if cur.Tests > 0 {
+			cur.End = r.Time
+			streaks = append(streaks, cur)
+			cur = Streak{}
+		}
+	}
+	if cur.Tests > 0 {
+		cur.End, cur.Ongoing = end, true
+		streaks = append(streaks, cur)
+	}
+	return streaks
+}
+
+// longestStreak returns the streak with the most failed tests, the longer one on a tie.
+func longestStreak(streaks []Streak) Streak {
+	var longest Streak
+	for _, s := range streaks {
+		if s.Tests > longest.Tests || s.Tests == longest.Tests && s.Duration() > longest.Duration() {
+			longest = s
+		}
+	}
+	return longest
+}
+
+// FailureStreaks returns the run of failed tests still going on at now, zero if the last test got
+// through, and the longest run that ended in the 24 hours before now.
+func (m *Manager) FailureStreaks(now time.Time) (current, longest Streak) {
+	// A streak may have started long before the last day
+	results, err := m.storage.Query(now.Add(-DefaultRetention), now)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query results for failure streaks")
+		return Streak{}, Streak{}
+	}
+	streaks := failureStreaks(results, now)
+	if n := len(streaks); n > 0 && streaks[n-1].Ongoing {
+		current = streaks[n-1]
+	}
+	day, i := now.Add(-24*time.Hour), len(streaks)
+	for i > 0 && streaks[i-1].End.After(day) {
+		i--
+	}
+	return current, longestStreak(streaks[i:])
+}
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// trendMinFit is how closely a line must follow the daily averages (R²) for a decline to count
+// as steady rather than a few bad days.
+const trendMinFit = 0.5
+
+// Trend is the linear trend of the daily average speeds over the last completed days.
+type Trend struct {
+	Days    int
+	Samples int // days with results
+	// Change over the window in percent of where the fitted line starts, and how well it fits
+	Download, Upload       float64
+	DownloadFit, UploadFit float64
+}
+
+// GetTrend fits a line through the daily averages of the last days calendar days in loc,
+// today excluded.
+func (m *Manager) GetTrend(now time.Time, days int, loc *time.Location) Trend {
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := midnight.AddDate(0, 0, -days)
+	a := m.aggregate(Aggregate{Days: days, From: from, To: midnight}, loc)
+
+	t := Trend{Days: days, Samples: len(a.Daily)}
+	var xs, downloads, uploads []float64
+	for _, d := range a.Daily {
+		xs = append(xs, d.Date.Sub(from).Hours()/24)
+		downloads = append(downloads, d.AvgDownload)
+		uploads = append(uploads, d.AvgUpload)
+	}
+	t.Download, t.DownloadFit = fitChange(xs, downloads, float64(days-1))
+	t.Upload, t.UploadFit = fitChange(xs, uploads, float64(days-1))
+	return t
+}
+
+// fitChange fits a least-squares line through the points and returns its change from x=0 to
+// x=span in percent of its value at 0, along with R². Fewer than three points fit nothing.
+func fitChange(xs, ys []float64, span float64) (change, r2 float64) {
+	if len(xs) < 3 {
+		return 0, 0
+	}
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, 0
+	}
+	slope := sxy / sxx
+	start := my - slope*mx
+	if start <= 0 {
+		return 0, 0
+	}
+	return slope * span / start * 100, sxy * sxy / (sxx * syy)
+}
+
+// Declines describes the speeds that fell steadily by at least drop percent over the window.
+// Half the days need results, so a short history doesn't raise false alarms.
+func (t Trend) Declines(drop float64) []string {
+	if drop <= 0 || t.Samples < max(3, (t.Days+1)/2) {
+		return nil
+	}
+	var notes []string
+	if t.Download <= -drop && t.DownloadFit >= trendMinFit {
+		notes = append(notes, i18n.T("trend.download", -t.Download, t.Days))
+	}
+	if t.Upload <= -drop && t.UploadFit >= trendMinFit {
+		notes = append(notes, i18n.T("trend.upload", -t.Upload, t.Days))
+	}
+	return notes
+}
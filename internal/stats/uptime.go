@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+)
+
+// outageFailures is how many failed tests in a row count as downtime rather than a fluke.
+const outageFailures = 2
+
+// Availability is how much of a period the connection was up, judging by runs of failed tests
+// and the outages recorded by the ping monitor and the outage tracker.
+type Availability struct {
+	Period   time.Duration // zero when nothing was measured
+	Outages  int           // overlapping outages count once
+	Downtime time.Duration
+}
+
+// Uptime returns the percentage of the period without downtime.
+func (a Availability) Uptime() float64 {
+	if a.Period <= 0 {
+		return 100
+	}
+	return 100 - float64(a.Downtime)/float64(a.Period)*100
+}
+
+// line renders the availability for reports, empty when nothing was measured.
+func (a Availability) line() string {
+	switch {
+	case a.Period <= 0:
+		return ""
+	case a.Outages == 0:
+		return i18n.T("report.uptime", a.Uptime())
+	default:
+		return i18n.T("report.uptime_outages", a.Uptime(), a.Outages, FormatPeriod(a.Downtime.Round(time.Minute)))
+	}
+}
+
+// failureRuns returns the streaks of at least outageFailures failed tests before end as outages.
+func failureRuns(results []Result, end time.Time) []Outage {
+	var runs []Outage
+	for _, s := range failureStreaks(results, end) {
+		if s.Tests >= outageFailures {
+			runs = append(runs, Outage{Start: s.Start, End: s.End})
+		}
+	}
+	return runs
+}
+
+// availability merges the failure runs in results, which span [from, to), with the recorded
+// outages overlapping it.
+func (m *Manager) availability(results []Result, from, to time.Time) Availability {
+	outages := failureRuns(results, to)
+	m.mu.Lock()
+	for _, o := range m.outages {
+		if o.End.After(from) && o.Start.Before(to) {
+			outages = append(outages, o)
+		}
+	}
+	m.mu.Unlock()
+	if len(results) == 0 && len(outages) == 0 {
+		return Availability{}
+	}
+
+	a := Availability{Period: to.Sub(from)}
+	slices.SortFunc(outages, func(x, y Outage) int { return x.Start.Compare(y.Start) })
+	var end time.Time
+	for _, o := range outages {
+		if o.Start.Before(from) {
+			o.Start = from
+		}
+		if o.End.After(to) {
+			o.End = to
+		}
+		// A failed speed test run usually overlaps the outage the monitor saw
+		if a.Outages > 0 && !o.Start.After(end) {
+			if o.End.After(end) {
+				a.Downtime += o.End.Sub(end)
+				end = o.End
+			}
+			continue
+		}
+		a.Outages++
+		a.Downtime += o.Duration()
+		end = o.End
+	}
+	return a
+}
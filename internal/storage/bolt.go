@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+	bolt "go.etcd.io/bbolt"
+)
+
+var resultsBucket = []byte("results")
+
+// BoltStore keeps results in an embedded bbolt database (pure Go, no CGO).
+// Results are keyed by their timestamp so range queries are cursor seeks.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db '%s': %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(resultsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create results bucket: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Add(r stats.Result) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(resultsBucket).Put(timeKey(r.Time), data)
+	})
+}
+
+func (s *BoltStore) Query(from, to time.Time) ([]stats.Result, error) {
+	var results []stats.Result
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(resultsBucket).Cursor()
+
+		k, v := c.First()
+		if !from.IsZero() {
+			k, v = c.Seek(timeKey(from))
+		}
+		var end []byte
+		if !to.IsZero() {
+			end = timeKey(to)
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if end != nil && bytes.Compare(k, end) > 0 {
+				break
+			}
+			var r stats.Result
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("failed to decode result: %w", err)
+			}
+			results = append(results, r)
+		}
+		return nil
+	})
+	return results, err
+}
+
+func (s *BoltStore) Prune(before time.Time) error {
+	limit := timeKey(before)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(resultsBucket)
+		c := b.Cursor()
+
+		// Collect first: deleting while iterating a bolt cursor can skip keys
+		var stale [][]byte
+		for k, _ := c.First(); k != nil && bytes.Compare(k, limit) < 0; k, _ = c.Next() {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// SnapshotBolt returns a consistent copy of the bbolt database at path, written from a read
+// transaction so a bot writing results at the same time can't leave it torn. The database
+// must not be held open by a running bot, whose lock makes this time out.
+func SnapshotBolt(path string) ([]byte, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db '%s': %w", path, err)
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	err = db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(&buf)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy bolt db '%s': %w", path, err)
+	}
+	return buf.Bytes(), nil
+}
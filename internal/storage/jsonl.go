@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// JSONLStore appends every result as one JSON line to a file.
+// The log is replayed into memory on open and queries are served from there;
+// the file is only rewritten when pruning actually drops results.
+type JSONLStore struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	results []stats.Result
+}
+
+func OpenJSONL(path string) (*JSONLStore, error) {
+	results, err := readJSONL(path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Time.Before(results[j].Time)
+	})
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result log '%s': %w", path, err)
+	}
+	return &JSONLStore{path: path, file: f, results: results}, nil
+}
+
+func (s *JSONLStore) Add(r stats.Result) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("failed to append result: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	i := sort.Search(len(s.results), func(i int) bool {
+		return s.results[i].Time.After(r.Time)
+	})
+	s.results = append(s.results, stats.Result{})
+	copy(s.results[i+1:], s.results[i:])
+	s.results[i] = r
+	return nil
+}
+
+func (s *JSONLStore) Query(from, to time.Time) ([]stats.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return stats.FilterRange(s.results, from, to), nil
+}
+
+func (s *JSONLStore) Prune(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.results), func(i int) bool {
+		return !s.results[i].Time.Before(before)
+	})
+	if i == 0 {
+		return nil
+	}
+	kept := append([]stats.Result(nil), s.results[i:]...)
+
+	// Rewrite the log atomically, then reopen it for appending
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite result log: %w", err)
+	}
+	if err := stats.WriteJSONL(f, kept); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to rewrite result log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace result log: %w", err)
+	}
+
+	_ = s.file.Close()
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen result log: %w", err)
+	}
+	s.results = kept
+	return nil
+}
+
+func (s *JSONLStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func readJSONL(path string) ([]stats.Result, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result log: %w", err)
+	}
+	defer f.Close()
+
+	var results []stats.Result
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r stats.Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// A torn write after a crash shouldn't prevent startup
+			log.Warn().Err(err).Int("line", line).Str("path", path).Msg("Skipping malformed result line")
+			continue
+		}
+		results = append(results, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read result log: %w", err)
+	}
+	return results, nil
+}
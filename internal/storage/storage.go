@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+	BackendJSONL  = "jsonl"
+)
+
+// Open returns the result storage selected by config.
+func Open(cfg *config.Config) (stats.Storage, error) {
+	switch cfg.StorageBackend {
+	case "", BackendMemory:
+		return stats.NewMemoryStorage(), nil
+	case BackendBolt:
+		s, err := OpenBolt(cfg.StoragePath)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendJSONL:
+		s, err := OpenJSONL(cfg.StoragePath)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend '%s'", cfg.StorageBackend)
+	}
+}
+
+// IsPersistent reports whether the configured backend keeps results across restarts.
+func IsPersistent(cfg *config.Config) bool {
+	return cfg.StorageBackend != "" && cfg.StorageBackend != BackendMemory
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+func TestBackends(t *testing.T) {
+	dir := t.TempDir()
+	backends := map[string]func() (stats.Storage, error){
+		"memory": func() (stats.Storage, error) { return stats.NewMemoryStorage(), nil },
+		"bolt":   func() (stats.Storage, error) { return OpenBolt(filepath.Join(dir, "tetra.db")) },
+		"jsonl":  func() (stats.Storage, error) { return OpenJSONL(filepath.Join(dir, "tetra.jsonl")) },
+	}
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			s, err := open()
+			if err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+			defer s.Close()
+
+			// Insert out of order to check results come back sorted
+			for _, h := range []int{3, 1, 2, 0} {
+				if err := s.Add(stats.Result{Time: base.Add(time.Duration(h) * time.Hour), Download: float64(h)}); err != nil {
+					t.Fatalf("Add failed: %v", err)
+				}
+			}
+
+			all, err := s.Query(time.Time{}, time.Time{})
+			if err != nil || len(all) != 4 {
+				t.Fatalf("Expected 4 results, got %d (err %v)", len(all), err)
+			}
+			for i, r := range all {
+				if r.Download != float64(i) {
+					t.Errorf("Expected chronological order, got %v at %d", r.Download, i)
+				}
+			}
+
+			ranged, _ := s.Query(base.Add(time.Hour), base.Add(2*time.Hour))
+			if len(ranged) != 2 {
+				t.Errorf("Expected 2 results in range, got %d", len(ranged))
+			}
+
+			if err := s.Prune(base.Add(2 * time.Hour)); err != nil {
+				t.Fatalf("Prune failed: %v", err)
+			}
+			left, _ := s.Query(time.Time{}, time.Time{})
+			if len(left) != 2 || left[0].Download != 2 {
+				t.Errorf("Expected 2 results after prune starting at 2, got %v", left)
+			}
+		})
+	}
+}
+
+func TestJSONLReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tetra.jsonl")
+	now := time.Now()
+
+	s, err := OpenJSONL(path)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	_ = s.Add(stats.Result{Time: now.Add(-time.Hour), Download: 10})
+	_ = s.Add(stats.Result{Time: now, Download: 20})
+	_ = s.Prune(now.Add(-time.Minute))
+	_ = s.Add(stats.Result{Time: now.Add(time.Minute), Download: 30})
+	_ = s.Close()
+
+	s, err = OpenJSONL(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer s.Close()
+	results, _ := s.Query(time.Time{}, time.Time{})
+	if len(results) != 2 || results[0].Download != 20 || results[1].Download != 30 {
+		t.Errorf("Expected replayed results [20 30], got %v", results)
+	}
+}
@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"context"
+	"html"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// callbackAck is the data of the Acknowledge button under alerts.
+const callbackAck = "ack"
+
+// ackKey is an alert condition in a chat, see outgoing.Alert.
+type ackKey struct {
+	chatID int64
+	alert  string
+}
+
+// acked records who acknowledged an ongoing alert condition.
+type acked struct {
+	by string
+	at time.Time
+}
+
+// alertKeyboard is the main keyboard with an Acknowledge button on top.
+func (b *Bot) alertKeyboard() *models.InlineKeyboardMarkup {
+	kb := b.getMainKeyboard()
+	ack := []models.InlineKeyboardButton{{Text: i18n.T("button.ack"), CallbackData: callbackAck}}
+	kb.InlineKeyboard = append([][]models.InlineKeyboardButton{ack}, kb.InlineKeyboard...)
+	return kb
+}
+
+func (b *Bot) isAcked(chatID int64, alert string) bool {
+	b.alertsMu.Lock()
+	defer b.alertsMu.Unlock()
+	_, ok := b.acked[ackKey{chatID, alert}]
+	return ok
+}
+
+// unack ends the acknowledgment of a condition that recovered, so it alerts again next time.
+func (b *Bot) unack(chatID int64, alert string) {
+	b.alertsMu.Lock()
+	defer b.alertsMu.Unlock()
+	delete(b.acked, ackKey{chatID, alert})
+}
+
+// ackHandler serves /ack, which acknowledges the latest alert in the chat.
+func (b *Bot) ackHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	var user models.User
+	if update.Message.From != nil {
+		user = *update.Message.From
+	}
+	if !b.acknowledge(ctx, update.Message.Chat.ID, 0, user) {
+		b.reply(ctx, update.Message.Chat.ID, i18n.T("ack.none"))
+		return
+	}
+	b.reply(ctx, update.Message.Chat.ID, i18n.T("ack.done"))
+}
+
+// acknowledge suppresses repeats of an alert's condition in the chat until it recovers, and notes who
+// acknowledged on the alert, the given message or else the latest one. It reports whether
+// there was an alert to acknowledge; alerts no longer tracked, e.g. after a restart, can't be.
+func (b *Bot) acknowledge(ctx context.Context, chatID int64, messageID int, user models.User) bool {
+	name := user.FirstName
+	if user.Username != "" {
+		name = "@" + user.Username
+	}
+	now := time.Now()
+
+	b.alertsMu.Lock()
+	var alert *sentAlert
+	for i := len(b.alerts) - 1; i >= 0; i-- {
+		a := &b.alerts[i]
+		if a.chatID == chatID && (messageID == 0 || a.messageID == messageID) {
+			alert = a
+			break
+		}
+	}
+	if alert == nil {
+		b.alertsMu.Unlock()
+		return false
+	}
+	b.acked[ackKey{chatID, alert.alert}] = acked{by: name, at: now}
+	alert.text += i18n.T("ack.by", html.EscapeString(name), b.formatTime(now))
+	text, messageID, client := alert.text, alert.messageID, alert.client
+	b.alertsMu.Unlock()
+	log.Info().Int64("chat_id", chatID).Int64("user_id", user.ID).Msg("Alert acknowledged")
+
+	_, err := b.editMessageAs(ctx, client, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Int64("chat_id", chatID).Msg("Failed to mark alert as acknowledged")
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package telegram
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Outcomes of an audited command
+const (
+	auditAllowed = "ok"
+	auditDenied  = "denied"
+)
+
+const (
+	maxAuditCommand  = 64      // characters of a command kept in the audit log
+	maxAuditFileSize = 1 << 20 // bytes the audit file may grow to before only the recent entries are kept
+	maxMessageLength = 4096    // Telegram's limit for a message's text
+)
+
+// auditEntry records one command or button press.
+type auditEntry struct {
+	Time     time.Time `json:"time"`
+	UserID   int64     `json:"user_id"`
+	Username string    `json:"username,omitempty"`
+	ChatID   int64     `json:"chat_id"`
+	Command  string    `json:"command"`
+	Outcome  string    `json:"outcome"`
+}
+
+// auditLog keeps the latest entries in memory for /audit. When a path is configured, every
+// entry is also appended to it as a JSON line, so the full history survives restarts.
+type auditLog struct {
+	mu     sync.Mutex
+	path   string
+	limit  int
+	recent []auditEntry
+}
+
+func newAuditLog(path string, limit int) (*auditLog, error) {
+	a := &auditLog{path: path, limit: limit}
+	if path == "" {
+		return a, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.Warn().Err(err).Msg("Skipping malformed audit log line")
+			continue
+		}
+		a.add(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return a, nil
+}
+
+// add keeps e among the recent entries. Caller must hold the lock or own a.
+func (a *auditLog) add(e auditEntry) {
+	a.recent = append(a.recent, e)
+	if len(a.recent) > a.limit {
+		a.recent = a.recent[len(a.recent)-a.limit:]
+	}
+}
+
+// record stores an entry. Failing to write it to disk is logged, not fatal: the command
+// itself already went through or was rejected.
+func (a *auditLog) record(e auditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.add(e)
+	if a.path == "" {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode audit entry")
+		return
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open audit log")
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		log.Error().Err(err).Msg("Failed to write audit log")
+		return
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > maxAuditFileSize {
+		a.compact()
+	}
+}
+
+// compact rewrites the audit file with the recent entries only, so it doesn't grow without
+// bound. Caller must hold the lock.
+func (a *auditLog) compact() {
+	var buf []byte
+	for _, e := range a.recent {
+		line, err := json.Marshal(e)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to encode audit entry")
+			return
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		log.Error().Err(err).Msg("Failed to compact audit log")
+		return
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		log.Error().Err(err).Msg("Failed to compact audit log")
+		return
+	}
+	log.Info().Int("entries", len(a.recent)).Msg("Audit log compacted")
+}
+
+// last returns up to n of the most recent entries, newest first.
+func (a *auditLog) last(n int) []auditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	n = min(n, len(a.recent))
+	out := make([]auditEntry, 0, n)
+	for i := len(a.recent) - 1; len(out) < n; i-- {
+		out = append(out, a.recent[i])
+	}
+	return out
+}
+
+// audit records a command or button press by user in a chat.
+func (b *Bot) audit(user models.User, chatID int64, command string, allowed bool) {
+	outcome := auditAllowed
+	if !allowed {
+		outcome = auditDenied
+	}
+	b.auditLog.record(auditEntry{
+		Time:     time.Now(),
+		UserID:   user.ID,
+		Username: user.Username,
+		ChatID:   chatID,
+		Command:  auditCommand(command),
+		Outcome:  outcome,
+	})
+}
+
+// auditCommand is the command as recorded: without the setup code of "/start <code>", and
+// cut to maxAuditCommand characters.
+func auditCommand(command string) string {
+	if fields := strings.Fields(command); len(fields) > 1 && (fields[0] == "/start" || strings.HasPrefix(fields[0], "/start@")) {
+		return fields[0]
+	}
+	if runes := []rune(command); len(runes) > maxAuditCommand {
+		return string(runes[:maxAuditCommand]) + "…"
+	}
+	return command
+}
+
+// auditHandler serves /audit, listing the latest commands, "/audit 50" for more.
+func (b *Bot) auditHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	n := 20
+	if args := strings.Fields(update.Message.Text)[1:]; len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 || len(args) > 1 {
+			b.reply(ctx, chatID, i18n.T("audit.usage"))
+			return
+		}
+	}
+
+	entries := b.auditLog.last(min(n, 100))
+	if len(entries) == 0 {
+		b.reply(ctx, chatID, i18n.T("audit.empty"))
+		return
+	}
+	// Long lists are split to stay within Telegram's message size
+	var sb strings.Builder
+	sb.WriteString(i18n.T("audit.title", len(entries)))
+	for _, e := range entries {
+		user := strconv.FormatInt(e.UserID, 10)
+		if e.Username != "" {
+			user = "@" + e.Username
+		}
+		key := "audit.entry"
+		if e.Outcome == auditDenied {
+			key = "audit.entry_denied"
+		}
+		line := i18n.T(key, b.formatTime(e.Time), html.EscapeString(user), html.EscapeString(auditCommand(e.Command)))
+		if sb.Len()+len(line) > maxMessageLength {
+			b.reply(ctx, chatID, sb.String())
+			sb.Reset()
+		}
+		sb.WriteString(line)
+	}
+	b.reply(ctx, chatID, sb.String())
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, err := newAuditLog(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, command := range []string{"/test", "/settings", "/pause"} {
+		a.record(auditEntry{Time: now.Add(time.Duration(i) * time.Second), UserID: 42, Command: command, Outcome: auditAllowed})
+	}
+
+	// Reopening keeps only the newest entries within the limit, newest first
+	a, err = newAuditLog(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := a.last(5)
+	if len(got) != 2 || got[0].Command != "/pause" || got[1].Command != "/settings" {
+		t.Errorf("last(5) = %+v, want /pause then /settings", got)
+	}
+}
+
+func TestAuditCommand(t *testing.T) {
+	long := "/settings " + strings.Repeat("x", 100)
+	for command, want := range map[string]string{
+		"/start 123456":         "/start",
+		"/start@tetra_bot 1234": "/start@tetra_bot",
+		"/start":                "/start",
+		"/test":                 "/test",
+		long:                    long[:maxAuditCommand] + "…",
+	} {
+		if got := auditCommand(command); got != want {
+			t.Errorf("auditCommand(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestAuditLogCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, err := newAuditLog(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, maxAuditFileSize), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a.record(auditEntry{Time: time.Now(), UserID: 42, Command: "/test", Outcome: auditAllowed})
+
+	// Past the size limit only the recent entries are kept
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() >= maxAuditFileSize {
+		t.Errorf("audit log is %d bytes after compaction", info.Size())
+	}
+	if a, err = newAuditLog(path, 3); err != nil || len(a.last(5)) != 1 {
+		t.Errorf("reopened audit log = %+v, %v", a, err)
+	}
+}
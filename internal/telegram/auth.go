@@ -0,0 +1,171 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// role is a permission tier. Viewers can read results, admins can also run tests and change settings.
+type role int
+
+const (
+	roleNone role = iota
+	roleViewer
+	roleAdmin
+)
+
+func (r role) String() string {
+	switch r {
+	case roleAdmin:
+		return "admin"
+	case roleViewer:
+		return "viewer"
+	default:
+		return "none"
+	}
+}
+
+// adminCommands are the text commands that need the admin role, everything else is open to viewers.
+var adminCommands = []string{"/test", "/speed", "/settings", "/setinterval", "/server", "/pause", "/resume", "/mute", "/unmute", "/ack", "/audit", "Test Speed"}
+
+// adminCallbacks are the callback data prefixes that need the admin role.
+var adminCallbacks = []string{callbackTest, callbackSettings, callbackSet, callbackServer, callbackAck}
+
+// roleOf decides what a user may do from a chat. With ALLOWED_USER_IDS set only those users
+// (and admins) are accepted, anywhere; otherwise anyone in one of the configured chats is.
+// ADMIN_USER_IDS narrows who is an admin, by default every accepted user is.
+func (b *Bot) roleOf(userID, chatID int64) role {
+	c := b.conf
+	if slices.Contains(c.AdminUserIDs, userID) {
+		return roleAdmin
+	}
+	if len(c.AllowedUserIDs) > 0 {
+		if !slices.Contains(c.AllowedUserIDs, userID) {
+			return roleNone
+		}
+	} else if !b.isChat(chatID) {
+		return roleNone
+	}
+	if len(c.AdminUserIDs) > 0 {
+		return roleViewer
+	}
+	return roleAdmin
+}
+
+// commandRole returns the role needed for a message, e.g. "/test@tetra_bot now" needs admin.
+func commandRole(text string) role {
+	command, _, _ := strings.Cut(strings.TrimSpace(text), " ")
+	command, _, _ = strings.Cut(command, "@")
+	if slices.Contains(adminCommands, command) || slices.Contains(adminCommands, strings.TrimSpace(text)) {
+		return roleAdmin
+	}
+	return roleViewer
+}
+
+// callbackRole returns the role needed for an inline button.
+func callbackRole(data string) role {
+	for _, prefix := range adminCallbacks {
+		if strings.HasPrefix(data, prefix) {
+			return roleAdmin
+		}
+	}
+	return roleViewer
+}
+
+// authMiddleware enforces roles for every handler, so a stranger who finds the bot
+// can't run tests and burn bandwidth.
+func (b *Bot) authMiddleware(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, bb *bot.Bot, update *models.Update) {
+		switch {
+		case update.Message != nil:
+			msg := update.Message
+			var user models.User
+			if msg.From != nil {
+				user = *msg.From
+			}
+			have, need := b.roleOf(user.ID, msg.Chat.ID), commandRole(msg.Text)
+			// Until a chat is bound, /start is how one gets bound, see bind
+			allowed := have >= need || b.binding() && isStart(msg.Text)
+			if strings.HasPrefix(msg.Text, "/") || need == roleAdmin {
+				b.audit(user, msg.Chat.ID, msg.Text, allowed)
+			}
+			if allowed {
+				next(ctx, bb, update)
+				return
+			}
+			log.Warn().Int64("user_id", user.ID).Str("username", user.Username).Int64("chat_id", msg.Chat.ID).
+				Str("role", have.String()).Str("text", msg.Text).Msg("Unauthorized message")
+			// Only answer commands, other chatter in a group the bot was added to doesn't need a reply
+			if !strings.HasPrefix(msg.Text, "/") && have == roleNone {
+				return
+			}
+			_, err := b.client.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: msg.Chat.ID,
+				Text:   rejection(have),
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to send rejection message")
+			}
+
+		case update.CallbackQuery != nil:
+			query := update.CallbackQuery
+			var chatID int64
+			if m := query.Message.Message; m != nil {
+				chatID = m.Chat.ID
+			} else if m := query.Message.InaccessibleMessage; m != nil {
+				chatID = m.Chat.ID
+			}
+			have, need := b.roleOf(query.From.ID, chatID), callbackRole(query.Data)
+			b.audit(query.From, chatID, "button:"+query.Data, have >= need)
+			if have >= need {
+				next(ctx, bb, update)
+				return
+			}
+			log.Warn().Int64("user_id", query.From.ID).Str("username", query.From.Username).Int64("chat_id", chatID).
+				Str("role", have.String()).Str("data", query.Data).Msg("Unauthorized callback")
+			_, err := b.client.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+				CallbackQueryID: query.ID,
+				Text:            rejection(have),
+				ShowAlert:       true,
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to answer callback query")
+			}
+
+		case update.InlineQuery != nil:
+			query := update.InlineQuery
+			if have := b.inlineRole(ctx, query.From.ID); have >= roleViewer {
+				next(ctx, bb, update)
+				return
+			}
+			log.Warn().Int64("user_id", query.From.ID).Str("username", query.From.Username).Str("query", query.Query).
+				Msg("Unauthorized inline query")
+			// No results rather than an error, the query shows up in whatever chat the user is typing in
+			_, err := b.client.AnswerInlineQuery(ctx, &bot.AnswerInlineQueryParams{
+				InlineQueryID: query.ID,
+				Results:       []models.InlineQueryResult{},
+				IsPersonal:    true,
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to answer inline query")
+			}
+
+		default:
+			// Channel posts, membership changes and the like carry no commands
+			next(ctx, bb, update)
+		}
+	}
+}
+
+func rejection(have role) string {
+	if have == roleNone {
+		return i18n.T("auth.rejected")
+	}
+	return i18n.T("auth.admin_only")
+}
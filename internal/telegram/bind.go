@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// binding reports whether no chat is configured yet, so /start may bind one.
+func (b *Bot) binding() bool {
+	b.chatsMu.RLock()
+	defer b.chatsMu.RUnlock()
+	return len(b.chats) == 0
+}
+
+// offerBinding logs the setup code and deep link for binding a chat, when none is configured.
+func (b *Bot) offerBinding() {
+	if !b.binding() {
+		return
+	}
+	b.setup = rand.Text()
+	link := ""
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if me, err := b.client.GetMe(ctx); err == nil {
+		link = "https://t.me/" + me.Username + "?start=" + b.setup
+	}
+	log.Warn().Str("code", b.setup).Str("link", link).
+		Msg("No CHAT_ID configured: send \"/start <code>\" to the bot from the chat that should get alerts, or open the link")
+}
+
+// bind makes the chat of a /start message the alert destination, if the sender may do so:
+// a user listed in ALLOWED_USER_IDS or ADMIN_USER_IDS, or anyone with the setup code.
+func (b *Bot) bind(msg *models.Message) bool {
+	var userID int64
+	if msg.From != nil {
+		userID = msg.From.ID
+	}
+	listed := slices.Contains(b.conf.AllowedUserIDs, userID) || slices.Contains(b.conf.AdminUserIDs, userID)
+	if len(b.conf.AllowedUserIDs) > 0 && !listed {
+		return false // they couldn't use the bot even from the bound chat
+	}
+	_, code, _ := strings.Cut(msg.Text, " ")
+	if !listed && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(code)), []byte(b.setup)) != 1 {
+		return false
+	}
+
+	b.chatsMu.Lock()
+	defer b.chatsMu.Unlock()
+	if len(b.chats) > 0 {
+		return slices.Contains(b.chats, msg.Chat.ID)
+	}
+	if _, err := b.settings.Update(func(v *config.Values) { v.BoundChat = msg.Chat.ID }); err != nil {
+		log.Error().Err(err).Msg("Failed to save the bound chat")
+		return false
+	}
+	b.chats = []int64{msg.Chat.ID}
+	b.forums[msg.Chat.ID] = msg.Chat.IsForum
+	log.Info().Int64("chat_id", msg.Chat.ID).Int64("user_id", userID).Msg("Chat bound as the alert destination")
+	return true
+}
+
+// isStart reports whether a message is /start, possibly with a deep link parameter.
+func isStart(text string) bool {
+	command, _, _ := strings.Cut(text, " ")
+	command, _, _ = strings.Cut(command, "@")
+	return command == "/start"
+}
+
+// bindingReply tells the sender of /start whether their chat got bound.
+func (b *Bot) bindingReply(ctx context.Context, msg *models.Message, bound bool) {
+	text := i18n.T("bind.done")
+	if !bound {
+		text = i18n.T("bind.code")
+	}
+	if _, err := b.sendMessage(ctx, &bot.SendMessageParams{ChatID: msg.Chat.ID, Text: text, ParseMode: models.ParseModeHTML}); err != nil {
+		log.Error().Err(err).Msg("Failed to send binding reply")
+	}
+}
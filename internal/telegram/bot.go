@@ -1,52 +1,173 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ckayt/tetra/internal/chart"
 	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/notify"
+	"github.com/ckayt/tetra/internal/speed"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/rs/zerolog/log"
 )
 
+// Actions are the callbacks the bot invokes to serve user commands.
+type Actions struct {
+	Test      func(ctx context.Context, verbose bool) (string, []byte)             // callback for /test command, with verbose also returns the raw results as JSON
+	Stats     func(ctx context.Context, chatID int64, period time.Duration) string // callback for /stats command and report buttons, summarizes the given period
+	Range     func(ctx context.Context, chatID int64, from, to time.Time) string   // callback for /stats with dates, summarizes a fixed window
+	Export    func(context.Context) ([]byte, error)                                // callback for /export command, returns CSV
+	Servers   func(context.Context) ([]speed.ServerInfo, error)                    // callback for /server command, lists nearby speedtest.net servers
+	Status    func(context.Context) Status                                         // callback for /status command
+	Last      func(context.Context) string                                         // callback for /last command, formats the latest stored result
+	Alerts    func(ctx context.Context, n int) string                              // callback for /alerts, lists the latest n alert events
+	Report    func(ctx context.Context, chatID int64)                              // callback for /report command, queues the daily report for the chat
+	Compare   func(ctx context.Context, period time.Duration) string               // callback for /compare, compares the period ending now with the one before
+	Aggregate func(ctx context.Context, days int) string                           // callback for /week and /month, summarizes the last days calendar days
+	Hours     func(ctx context.Context, days int) string                           // callback for /hours, ranks the hours of the day over the last days, 0 for the default
+	Graph     func(context.Context, chart.Metric, time.Duration) ([]byte, error)   // callback for /graph, renders a PNG of the period ending now
+}
+
 type Bot struct {
-	client      *bot.Bot
-	conf        *config.Config
-	msgQueue    chan string
-	testAction  func(context.Context) string // callback for /test command
-	statsAction func(context.Context) string // callback for /stats command
+	client   *bot.Bot
+	backup   *bot.Bot // sends queued messages once the primary token keeps failing, nil without TELEGRAM_BACKUP_TOKEN
+	conf     *config.Config
+	settings *config.Settings
+	chatsMu  sync.RWMutex
+	chats    []int64        // every chat alerts and reports are broadcast to
+	channels map[int64]bool // broadcast-only chats, which get no inline keyboard
+	forums   map[int64]bool // chats with topics, where TELEGRAM_TOPICS applies
+	setup    string         // code for binding a chat with /start while none is configured
+	silent   map[Class]bool // classes sent without a notification sound
+	queue    *messageQueue
+	auditLog *auditLog
+	limiter  *rateLimiter
+	pinned   map[int64]int // message pinned with Message.Pin per chat, only touched by the sender loop
+
+	alertsMu sync.Mutex
+	alerts   []sentAlert      // delivered alerts whose condition is ongoing
+	acked    map[ackKey]acked // alert conditions acknowledged in each chat
+	actions  Actions
+
+	failoverMu sync.Mutex
+	failures   int       // sends in a row that failed on the token, see noteSend
+	failedOver time.Time // when sending switched to the backup bot, zero before
 }
 
-func New(cfg *config.Config, testAction func(context.Context) string, statsAction func(context.Context) string) (*Bot, error) {
+func New(cfg *config.Config, settings *config.Settings, actions Actions) (*Bot, error) {
+	queue, err := newMessageQueue(cfg.TelegramQueuePath, 100) // Buffer for burst alerts
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := newAuditLog(cfg.AuditLogPath, 200)
+	if err != nil {
+		return nil, err
+	}
+	silent := map[Class]bool{ClassWarning: true}
+	for _, c := range cfg.SilentNotifications {
+		silent[Class(c)] = true
+	}
+
 	b := &Bot{
-		conf:        cfg,
-		msgQueue:    make(chan string, 100), // Buffer for burst alerts
-		testAction:  testAction,
-		statsAction: statsAction,
+		conf:     cfg,
+		settings: settings,
+		silent:   silent,
+		queue:    queue,
+		auditLog: auditLog,
+		limiter:  newRateLimiter(),
+		pinned:   make(map[int64]int),
+		acked:    make(map[ackKey]acked),
+		actions:  actions,
 	}
 
 	opts := []bot.Option{
 		bot.WithDefaultHandler(b.handler),
+		bot.WithMiddlewares(b.authMiddleware),
 		bot.WithCheckInitTimeout(30 * time.Second),
 	}
 
+	// Route Bot API calls through a proxy, e.g. where api.telegram.org is blocked
+	if cfg.TelegramProxy != "" {
+		proxyURL, err := url.Parse(cfg.TelegramProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_PROXY: %w", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		// Long polling holds requests for up to a minute, leave some headroom
+		opts = append(opts, bot.WithHTTPClient(time.Minute, &http.Client{Transport: transport, Timeout: time.Minute + 10*time.Second}))
+	}
+
+	if cfg.TelegramBackupToken != "" {
+		backup, err := bot.New(cfg.TelegramBackupToken, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup bot: %w", err)
+		}
+		b.backup = backup
+	}
+
 	// Create bot instance
 	tBot, err := bot.New(cfg.TelegramToken, opts...)
+	if err != nil && b.backup != nil {
+		// Start with the backup bot rather than not at all, it then also receives the commands
+		log.Error().Err(err).Msg("Telegram failover: the primary token doesn't work, using TELEGRAM_BACKUP_TOKEN")
+		tBot, err = b.backup, nil
+		b.failedOver = time.Now()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 	b.client = tBot
 
+	if err := b.resolveChats(); err != nil {
+		return nil, err
+	}
+	b.offerBinding()
+
 	// Register commands
-	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, b.startHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "start", bot.MatchTypeCommandStartOnly, b.startHandler)
 	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/help", bot.MatchTypeExact, b.helpHandler)
-	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/test", bot.MatchTypeExact, b.testHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "test", bot.MatchTypeCommandStartOnly, b.testHandler)
 	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/speed", bot.MatchTypeExact, b.testHandler)
-	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/stats", bot.MatchTypeExact, b.statsHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "stats", bot.MatchTypeCommandStartOnly, b.statsHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/export", bot.MatchTypeExact, b.exportHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/settings", bot.MatchTypeExact, b.settingsHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "setinterval", bot.MatchTypeCommandStartOnly, b.setIntervalHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/server", bot.MatchTypeExact, b.serverHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/pause", bot.MatchTypeExact, b.pauseHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/ack", bot.MatchTypeExact, b.ackHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "mute", bot.MatchTypeCommandStartOnly, b.muteHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "unmute", bot.MatchTypeCommandStartOnly, b.muteHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "notify", bot.MatchTypeCommandStartOnly, b.notifyHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "audit", bot.MatchTypeCommandStartOnly, b.auditHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/resume", bot.MatchTypeExact, b.pauseHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/status", bot.MatchTypeExact, b.statusHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/last", bot.MatchTypeExact, b.lastHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "alerts", bot.MatchTypeCommandStartOnly, b.alertsHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/report", bot.MatchTypeExact, b.reportHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "compare", bot.MatchTypeCommandStartOnly, b.compareHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/week", bot.MatchTypeExact, b.aggregateHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "/month", bot.MatchTypeExact, b.aggregateHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "hours", bot.MatchTypeCommandStartOnly, b.hoursHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "graph", bot.MatchTypeCommandStartOnly, b.graphHandler)
+	tBot.RegisterHandler(bot.HandlerTypeCallbackQueryData, "", bot.MatchTypePrefix, b.callbackHandler)
+	tBot.RegisterHandlerMatchFunc(func(update *models.Update) bool { return update.InlineQuery != nil }, b.inlineHandler)
+
+	// Chats that still show the old reply keyboard keep working until /start replaces it
 	tBot.RegisterHandler(bot.HandlerTypeMessageText, "Test Speed", bot.MatchTypeExact, b.testHandler)
-	tBot.RegisterHandler(bot.HandlerTypeMessageText, "Get Stats", bot.MatchTypeExact, b.statsHandler)
+	tBot.RegisterHandler(bot.HandlerTypeMessageText, "Get Stats", bot.MatchTypeExact, b.legacyStatsHandler)
 	tBot.RegisterHandler(bot.HandlerTypeMessageText, "Help", bot.MatchTypeExact, b.helpHandler)
 
 	return b, nil
@@ -55,111 +176,378 @@ func New(cfg *config.Config, testAction func(context.Context) string, statsActio
 func (b *Bot) Start(ctx context.Context) {
 	// Start message sender routine
 	go b.senderLoop(ctx)
+	go b.muteLoop(ctx)
+	go b.alertCleanupLoop(ctx)
+
+	if b.conf.TelegramWebhookURL != "" {
+		b.startWebhook(ctx)
+		return
+	}
+
+	// A webhook left over from webhook mode would make polling fail
+	if _, err := b.client.DeleteWebhook(ctx, &bot.DeleteWebhookParams{}); err != nil {
+		log.Warn().Err(err).Msg("Failed to delete Telegram webhook")
+	}
 
 	// Start polling
 	log.Info().Msg("Starting Telegram bot polling...")
 	b.client.Start(ctx)
 }
 
-func (b *Bot) Send(msg string) {
-	select {
-	case b.msgQueue <- msg:
-	default:
+// Send queues a message for every configured chat.
+func (b *Bot) Send(class Class, msg string) {
+	b.SendTo(class, msg, b.Chats()...)
+}
+
+// SendTo queues a message for the given chats.
+func (b *Bot) SendTo(class Class, msg string, chatIDs ...int64) {
+	if !b.queue.push(outgoing{Class: class, Text: msg}, chatIDs) {
 		log.Warn().Msg("Telegram message queue full, dropping message")
 	}
 }
 
+// SendPhotoTo queues a PNG image with an HTML caption for the given chats.
+func (b *Bot) SendPhotoTo(class Class, png []byte, caption string, chatIDs ...int64) {
+	if !b.queue.push(outgoing{Class: class, Text: caption, Photo: png}, chatIDs) {
+		log.Warn().Msg("Telegram message queue full, dropping photo")
+	}
+}
+
+// Held is an alert held back by quiet hours, see Bot.Hold.
+type Held struct {
+	Time time.Time
+	Text string
+}
+
+// Hold keeps an alert for a chat in the message queue, where it is not delivered but survives
+// restarts until TakeHeld collects it for the quiet hours digest. Held alerts never fill the queue
+// for live messages; beyond maxHeldPerChat per chat the oldest are dropped.
+func (b *Bot) Hold(chatID int64, text string, at time.Time) {
+	b.queue.hold(outgoing{Class: ClassAlert, Text: text, Held: at}, chatID)
+}
+
+// TakeHeld removes the alerts kept by Hold and returns them per chat, oldest first.
+func (b *Bot) TakeHeld() map[int64][]Held {
+	held := make(map[int64][]Held)
+	for chatID, msgs := range b.queue.takeHeld() {
+		for _, m := range msgs {
+			held[chatID] = append(held[chatID], Held{Time: m.Held, Text: m.Text})
+		}
+	}
+	return held
+}
+
+// errQueueFull is returned by Notify when the message queue is full.
+var errQueueFull = errors.New("telegram message queue full")
+
+// Name and Notify make the bot a notify.ChatNotifier.
+func (b *Bot) Name() string {
+	return "telegram"
+}
+
+// Notify queues a message for the chats it lists, where it is retried until delivered.
+// Critical alerts mention ALERT_MENTIONS in groups, so they get through even where the group
+// is muted. They usually mean the connection is down, so they are held until Telegram can be
+// reached again. Pinned messages replace the previous pin.
+func (b *Bot) Notify(ctx context.Context, m notify.Message) error {
+	msg := outgoing{Class: Class(m.Class), Text: m.Text, Pin: m.Pin, Alert: m.Alert}
+	if m.Critical && msg.Class == ClassAlert {
+		msg.Mention, msg.Hold = true, true
+	}
+	if !b.queue.push(msg, m.Chats) {
+		return errQueueFull
+	}
+	return nil
+}
+
+// location returns the configured time zone, falling back to UTC.
+func (b *Bot) location() *time.Location {
+	loc, err := time.LoadLocation(b.conf.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Chats returns the IDs of every chat alerts and reports go to.
+func (b *Bot) Chats() []int64 {
+	b.chatsMu.RLock()
+	defer b.chatsMu.RUnlock()
+	return slices.Clone(b.chats)
+}
+
+// isChat reports whether alerts and reports go to the chat.
+func (b *Bot) isChat(chatID int64) bool {
+	b.chatsMu.RLock()
+	defer b.chatsMu.RUnlock()
+	return slices.Contains(b.chats, chatID)
+}
+
+// isChannel reports whether the chat is a broadcast-only channel.
+func (b *Bot) isChannel(chatID int64) bool {
+	b.chatsMu.RLock()
+	defer b.chatsMu.RUnlock()
+	return b.channels[chatID]
+}
+
+// resolveChats looks up the configured chats, turning @usernames into IDs and noting which are channels.
+// Unknown numeric IDs are kept, since the bot may not have been added to the chat yet.
+func (b *Bot) resolveChats() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	b.chats = append([]int64(nil), b.conf.ChatIDs...)
+	b.channels = make(map[int64]bool)
+	b.forums = make(map[int64]bool)
+	// Without CHAT_ID, alerts go to the chat bound with /start, if any
+	if len(b.conf.ChatIDs) == 0 && len(b.conf.ChatUsernames) == 0 {
+		if id := b.settings.Get().BoundChat; id != 0 {
+			b.chats = append(b.chats, id)
+		}
+	}
+	for _, id := range b.chats {
+		chat, err := b.client.GetChat(ctx, &bot.GetChatParams{ChatID: id})
+		if err != nil {
+			log.Warn().Err(err).Int64("chat_id", id).Msg("Failed to look up chat")
+			continue
+		}
+		b.channels[id] = chat.Type == models.ChatTypeChannel
+		b.forums[id] = chat.IsForum
+	}
+	for _, name := range b.conf.ChatUsernames {
+		chat, err := b.client.GetChat(ctx, &bot.GetChatParams{ChatID: name})
+		if err != nil {
+			return fmt.Errorf("failed to resolve chat %s: %w", name, err)
+		}
+		if !slices.Contains(b.chats, chat.ID) {
+			b.chats = append(b.chats, chat.ID)
+		}
+		b.channels[chat.ID] = chat.Type == models.ChatTypeChannel
+		b.forums[chat.ID] = chat.IsForum
+		log.Info().Str("chat", name).Int64("chat_id", chat.ID).Msg("Resolved chat")
+	}
+	return nil
+}
+
+// Backoff between passes over a message that couldn't be delivered to every chat yet
+const (
+	redeliverBackoff    = 30 * time.Second
+	maxRedeliverBackoff = 10 * time.Minute
+)
+
 func (b *Bot) senderLoop(ctx context.Context) {
+	backoff := redeliverBackoff
 	for {
+		for msg := b.queue.peek(); msg != nil; msg = b.queue.peek() {
+			done := b.deliver(ctx, msg)
+			if ctx.Err() != nil {
+				// Leave the message queued so it is replayed after restart
+				return
+			}
+			if done {
+				backoff = redeliverBackoff
+				continue
+			}
+			// Keep the message, and everything after it, queued until Telegram takes it
+			log.Warn().Int64("id", msg.ID).Dur("retry_in", backoff).Msg("Telegram message not delivered, keeping it queued")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, maxRedeliverBackoff)
+		}
+
 		select {
 		case <-ctx.Done():
 			return
-		case msg := <-b.msgQueue:
-			b.sendMessageWithRetry(ctx, msg)
+		case <-b.queue.wake:
+		}
+	}
+}
+
+// deliver sends a queued message to every chat it is still pending for and dequeues it once
+// none is left. Chats that rejected the message for good are dropped; done is false while
+// others still have to get it.
+func (b *Bot) deliver(ctx context.Context, msg *outgoing) (done bool) {
+	done = true
+	for _, chatID := range msg.Pending {
+		if msg.Class.alerting() && b.isAcked(chatID, msg.Alert) {
+			log.Info().Int64("chat_id", chatID).Str("alert", msg.Alert).Msg("Alert condition acknowledged, skipping repeat alert")
+			b.queue.delivered(msg.ID, chatID)
+			continue
+		}
+		if msg.Class == ClassRecovery && msg.Alert != "" {
+			b.unack(chatID, msg.Alert)
+		}
+		sent, err := b.sendMessageWithRetry(ctx, chatID, msg)
+		switch {
+		case err == nil:
+			b.queue.delivered(msg.ID, chatID)
+			if msg.Pin {
+				b.pin(ctx, chatID, sent)
+			}
+			if msg.Class.alerting() {
+				b.trackAlert(b.sender(), chatID, sent, msg.Alert, msg.Text)
+			}
+		case rejected(err):
+			log.Error().Err(err).Int64("chat_id", chatID).Msg("Telegram rejected the message, dropping it for this chat")
+			b.queue.delivered(msg.ID, chatID)
+		default:
+			done = false
+		}
+		if ctx.Err() != nil {
+			return false
 		}
 	}
+	if done {
+		b.queue.remove(msg.ID)
+	}
+	return done
+}
+
+// rejected reports whether Telegram refused a message in a way retrying won't change, such as
+// a malformed message (400) or a chat that blocked or removed the bot (403).
+func rejected(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) || errors.Is(err, bot.ErrorForbidden)
 }
 
-func (b *Bot) getMainKeyboard() *models.ReplyKeyboardMarkup {
-	return &models.ReplyKeyboardMarkup{
-		Keyboard: [][]models.KeyboardButton{
+// Callback data carried by the inline keyboard buttons
+const (
+	callbackTest     = "test"
+	callbackStats    = "stats:" // followed by the period, e.g. "stats:24h"
+	callbackSettings = "settings"
+)
+
+func (b *Bot) getMainKeyboard() *models.InlineKeyboardMarkup {
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
 			{
-				{Text: "Test Speed"},
-				{Text: "Get Stats"},
+				{Text: i18n.T("button.test"), CallbackData: callbackTest},
 			},
 			{
-				{Text: "Help"},
+				{Text: i18n.T("button.last_24h"), CallbackData: callbackStats + "24h"},
+				{Text: i18n.T("button.last_7d"), CallbackData: callbackStats + "168h"},
+			},
+			{
+				{Text: i18n.T("button.settings"), CallbackData: callbackSettings},
 			},
 		},
-		ResizeKeyboard: true,
 	}
 }
 
-func (b *Bot) sendMessageWithRetry(ctx context.Context, text string) {
-	baseBackoff := time.Second
+// sendMessageWithRetry sends msg to chatID, retrying with backoff, and returns the last error
+// once it gives up.
+func (b *Bot) sendMessageWithRetry(ctx context.Context, chatID int64, msg *outgoing) (messageID int, err error) {
+	backoff := time.Second
 	maxBackoff := 30 * time.Second
 	maxRetries := 5
 
-	for _, chatID := range b.conf.ChatIDs {
-		// Reset retry logic for each chat ID
-		backoff := baseBackoff
-		sent := false
-
-		for i := 0; i < maxRetries; i++ {
-			_, err := b.client.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID:      chatID,
-				Text:        text,
-				ParseMode:   models.ParseModeHTML,
-				ReplyMarkup: b.getMainKeyboard(),
-			})
-			if err == nil {
-				sent = true
-				break
-			}
-
-			log.Error().Err(err).Int64("chat_id", chatID).Msgf("Failed to send telegram message (attempt %d/%d). Retrying in %v...", i+1, maxRetries, backoff)
+	for i := 0; i < maxRetries; i++ {
+		if err := b.limiter.wait(ctx, chatID); err != nil {
+			return 0, err
+		}
 
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(backoff):
+		var sent *models.Message
+		client := b.sender()
+		if msg.Photo != nil {
+			sent, err = b.sendPhotoAs(ctx, client, &bot.SendPhotoParams{
+				ChatID:              chatID,
+				MessageThreadID:     b.topic(chatID, msg.Class),
+				Caption:             msg.Text,
+				DisableNotification: b.silent[msg.Class],
+			}, msg.Photo)
+		} else {
+			params := &bot.SendMessageParams{
+				ChatID:              chatID,
+				MessageThreadID:     b.topic(chatID, msg.Class),
+				Text:                msg.Text,
+				ParseMode:           models.ParseModeHTML,
+				DisableNotification: b.silent[msg.Class],
 			}
-
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
+			channel := b.isChannel(chatID)
+			if msg.Mention && len(b.conf.AlertMentions) > 0 && chatID < 0 && !channel {
+				params.Text = html.EscapeString(strings.Join(b.conf.AlertMentions, " ")) + "\n" + params.Text
+				params.DisableNotification = false // a mention is meant to be noticed
 			}
+			// Channel posts are read-only logs, buttons there would only invite strangers to run tests
+			switch {
+			case channel:
+			case msg.Class.alerting():
+				params.ReplyMarkup = b.alertKeyboard()
+			default:
+				params.ReplyMarkup = b.getMainKeyboard()
+			}
+			sent, err = b.sendMessageAs(ctx, client, params)
+		}
+		b.noteSend(err)
+		if err == nil {
+			return sent.ID, nil
 		}
-		if !sent {
-			log.Error().Int64("chat_id", chatID).Msg("Failed to send telegram message after max retries")
+		if rejected(err) {
+			return 0, err
+		}
+
+		// Telegram says how long to back off when we still hit a limit
+		wait := backoff
+		var tooMany *bot.TooManyRequestsError
+		if errors.As(err, &tooMany) && tooMany.RetryAfter > 0 {
+			wait = time.Duration(tooMany.RetryAfter) * time.Second
+		}
+		log.Error().Err(err).Int64("chat_id", chatID).Msgf("Failed to send telegram message (attempt %d/%d). Retrying in %v...", i+1, maxRetries, wait)
+		// Held messages don't use up their attempts while the connection is down
+		if msg.Hold && unreachable(err) {
+			i--
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
 		}
 	}
+
+	log.Error().Int64("chat_id", chatID).Msg("Failed to send telegram message after max retries")
+	return 0, err
 }
 
 func (b *Bot) startHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
-	msg := "👋 <b>Hello!</b> I am Tetra, your internet connection monitor.\n\n" +
-		"I will periodically check your internet speed and notify you if it drops below the configured thresholds.\n" +
-		"Use /help to see available commands."
-	_, err := b.client.SendMessage(ctx, &bot.SendMessageParams{
+	if b.binding() {
+		bound := b.bind(update.Message)
+		b.bindingReply(ctx, update.Message, bound)
+		if !bound {
+			return
+		}
+	}
+	// Drop the reply keyboard of older versions, then offer the inline one
+	_, err := b.sendMessage(ctx, &bot.SendMessageParams{
 		ChatID:      update.Message.Chat.ID,
-		Text:        msg,
+		Text:        i18n.T("start.welcome"),
 		ParseMode:   models.ParseModeHTML,
-		ReplyMarkup: b.getMainKeyboard(),
+		ReplyMarkup: &models.ReplyKeyboardRemove{RemoveKeyboard: true},
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to send start message")
 	}
+	_, err = b.client.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        i18n.T("start.prompt"),
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send keyboard message")
+	}
 }
 
 func (b *Bot) helpHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
-	msg := "📋 <b>Available Commands:</b>\n" +
-		"/test - Run an immediate speed test\n" +
-		"/stats - Get statistics for the last 24h\n" +
-		"/help - Show this help message\n" +
-		"/start - Welcome message"
-	_, err := b.client.SendMessage(ctx, &bot.SendMessageParams{
+	_, err := b.sendMessage(ctx, &bot.SendMessageParams{
 		ChatID:      update.Message.Chat.ID,
-		Text:        msg,
+		Text:        i18n.T("help"),
 		ParseMode:   models.ParseModeHTML,
 		ReplyMarkup: b.getMainKeyboard(),
 	})
@@ -169,10 +557,18 @@ func (b *Bot) helpHandler(ctx context.Context, bb *bot.Bot, update *models.Updat
 }
 
 func (b *Bot) testHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	// "/test verbose" also attaches the raw results
+	_, arg, _ := strings.Cut(update.Message.Text, " ")
+	b.runTest(ctx, update.Message.Chat.ID, strings.TrimSpace(arg) == "verbose")
+}
+
+// runTest runs a manual test, editing the starting message as the test moves through its
+// phases and finally replacing it with the result.
+func (b *Bot) runTest(ctx context.Context, chatID int64, verbose bool) {
 	// Notify user test started
-	_, err := b.client.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:    update.Message.Chat.ID,
-		Text:      "🚀 <b>Starting manual speed test...</b> Please wait.",
+	started, err := b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      i18n.T("test.starting"),
 		ParseMode: models.ParseModeHTML,
 	})
 	if err != nil {
@@ -180,10 +576,41 @@ func (b *Bot) testHandler(ctx context.Context, bb *bot.Bot, update *models.Updat
 	}
 
 	// Execute test
-	resultMsg := b.testAction(ctx)
+	testCtx := ctx
+	if started != nil {
+		testCtx = speed.WithProgress(ctx, func(engine string, phase speed.Phase) {
+			_, err := b.editMessage(ctx, &bot.EditMessageTextParams{
+				ChatID:    chatID,
+				MessageID: started.ID,
+				Text:      i18n.T("test.running", i18n.T("test.phase."+string(phase), html.EscapeString(engine))),
+				ParseMode: models.ParseModeHTML,
+			})
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to update test progress")
+			}
+		})
+	}
+	resultMsg, raw := b.actions.Test(testCtx, verbose)
+	if raw != nil {
+		defer b.sendRawResults(ctx, chatID, raw)
+	}
 
-	_, err = b.client.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:      update.Message.Chat.ID,
+	if started != nil {
+		_, err = b.editMessage(ctx, &bot.EditMessageTextParams{
+			ChatID:      chatID,
+			MessageID:   started.ID,
+			Text:        resultMsg,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: b.getMainKeyboard(),
+		})
+		if err == nil {
+			return
+		}
+		log.Warn().Err(err).Msg("Failed to replace progress message, sending the result separately")
+	}
+
+	_, err = b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
 		Text:        resultMsg,
 		ParseMode:   models.ParseModeHTML,
 		ReplyMarkup: b.getMainKeyboard(),
@@ -193,17 +620,101 @@ func (b *Bot) testHandler(ctx context.Context, bb *bot.Bot, update *models.Updat
 	}
 }
 
-func (b *Bot) statsHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
-	resultMsg := b.statsAction(ctx)
+// sendRawResults attaches the JSON of a verbose test below its result.
+func (b *Bot) sendRawResults(ctx context.Context, chatID int64, raw []byte) {
+	_, err := b.client.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: chatID,
+		Document: &models.InputFileUpload{
+			Filename: fmt.Sprintf("tetra_test_%s.json", time.Now().Format("20060102_1504")),
+			Data:     bytes.NewReader(raw),
+		},
+		Caption: i18n.T("test.raw_caption"),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send raw test results")
+	}
+}
 
-	_, err := b.client.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:      update.Message.Chat.ID,
-		Text:        resultMsg,
-		ParseMode:   models.ParseModeHTML,
+// callbackHandler serves the inline keyboard buttons.
+func (b *Bot) callbackHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+
+	// Stop the button's loading spinner right away, tests take a while. Acknowledging answers itself.
+	if query.Data != callbackAck {
+		b.answerCallback(ctx, query.ID, "")
+	}
+
+	var chatID int64
+	switch {
+	case query.Message.Message != nil:
+		chatID = query.Message.Message.Chat.ID
+	case query.Message.InaccessibleMessage != nil:
+		chatID = query.Message.InaccessibleMessage.Chat.ID
+	default:
+		return
+	}
+
+	switch {
+	case query.Data == callbackTest:
+		b.runTest(ctx, chatID, false)
+	case strings.HasPrefix(query.Data, callbackStats):
+		period, err := time.ParseDuration(strings.TrimPrefix(query.Data, callbackStats))
+		if err != nil || period <= 0 {
+			log.Warn().Str("data", query.Data).Msg("Invalid stats callback")
+			return
+		}
+		b.sendStats(ctx, chatID, period)
+	case query.Data == callbackSettings:
+		b.sendSettings(ctx, chatID)
+	case strings.HasPrefix(query.Data, callbackSet):
+		b.adjustSetting(ctx, chatID, query)
+	case strings.HasPrefix(query.Data, callbackServer):
+		b.pinServer(ctx, chatID, query)
+	case query.Data == callbackAck:
+		if query.Message.Message != nil && b.acknowledge(ctx, chatID, query.Message.Message.ID, query.From) {
+			b.answerCallback(ctx, query.ID, "")
+		} else {
+			b.answerCallback(ctx, query.ID, i18n.T("ack.untracked"))
+		}
+	default:
+		log.Warn().Str("data", query.Data).Msg("Unknown callback")
+	}
+}
+
+// answerCallback stops a button's loading spinner, showing text as a notice unless it is empty.
+func (b *Bot) answerCallback(ctx context.Context, queryID, text string) {
+	_, err := b.client.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: queryID, Text: text})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to answer callback query")
+	}
+}
+
+func (b *Bot) exportHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	data, err := b.actions.Export(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to export results")
+		_, err = b.sendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    update.Message.Chat.ID,
+			Text:      i18n.T("export.failed"),
+			ParseMode: models.ParseModeHTML,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to send export error message")
+		}
+		return
+	}
+
+	_, err = b.client.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: update.Message.Chat.ID,
+		Document: &models.InputFileUpload{
+			Filename: fmt.Sprintf("tetra_results_%s.csv", time.Now().Format("20060102_1504")),
+			Data:     bytes.NewReader(data),
+		},
+		Caption:     i18n.T("export.caption"),
 		ReplyMarkup: b.getMainKeyboard(),
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to send stats message")
+		log.Error().Err(err).Msg("Failed to send export document")
 	}
 }
 
@@ -0,0 +1,37 @@
+package telegram
+
+// Class is the kind of an outgoing message, which decides how it is delivered.
+// The names match the values accepted by SILENT_NOTIFICATIONS and TELEGRAM_TOPICS.
+type Class string
+
+const (
+	ClassAlert    Class = "alert"    // critical quality alerts and lost connections
+	ClassWarning  Class = "warning"  // quality alerts above the critical thresholds, always silent
+	ClassRecovery Class = "recovery" // connections and endpoints that came back
+	ClassReport   Class = "report"   // daily reports and their charts
+	ClassInfo     Class = "info"     // other notices, e.g. data budget or endpoint changes
+)
+
+// alerting reports whether messages of the class are alerts, which can be acknowledged and cleaned up.
+func (c Class) alerting() bool {
+	return c == ClassAlert || c == ClassWarning
+}
+
+// topic returns the forum topic a message of the given class goes to, 0 for the general thread.
+// Topic IDs are only used in forum chats, other chats would reject them.
+func (b *Bot) topic(chatID int64, class Class) int {
+	b.chatsMu.RLock()
+	forum := b.forums[chatID]
+	b.chatsMu.RUnlock()
+	if !forum {
+		return 0
+	}
+	if id, ok := b.conf.TelegramTopics[string(class)]; ok {
+		return id
+	}
+	// Warnings go with the alerts unless they have a topic of their own
+	if id, ok := b.conf.TelegramTopics[string(ClassAlert)]; ok && class == ClassWarning {
+		return id
+	}
+	return b.conf.TelegramTopics["default"]
+}
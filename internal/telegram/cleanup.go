@@ -0,0 +1,119 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// sentAlert is an alert message that may be deleted or marked resolved later.
+type sentAlert struct {
+	chatID    int64
+	messageID int
+	sent      time.Time
+	alert     string // condition, see outgoing.Alert
+	text      string
+	client    *bot.Bot // the bot that sent it, the only one that may edit or delete it
+}
+
+// maxTrackedAlerts bounds the alerts kept for acknowledgment and cleanup.
+const maxTrackedAlerts = 100
+
+// trackAlert remembers a delivered alert until its condition is resolved. Alerts are only
+// tracked in memory, so those sent before a restart can't be acknowledged or cleaned up.
+func (b *Bot) trackAlert(client *bot.Bot, chatID int64, messageID int, alert, text string) {
+	b.alertsMu.Lock()
+	defer b.alertsMu.Unlock()
+	b.alerts = append(b.alerts, sentAlert{chatID: chatID, messageID: messageID, sent: time.Now(), alert: alert, text: text, client: client})
+	if len(b.alerts) > maxTrackedAlerts {
+		b.alerts = b.alerts[len(b.alerts)-maxTrackedAlerts:]
+	}
+}
+
+// ResolveAlerts ends the alert condition in the given chats, e.g. after a test passed again:
+// acknowledgments are reset and, with ALERT_CLEANUP, the alerts are cleaned up.
+func (b *Bot) ResolveAlerts(chatIDs ...int64) {
+	resolved := make(map[int64]bool)
+	b.alertsMu.Lock()
+	for _, id := range chatIDs {
+		resolved[id] = true
+	}
+	for key := range b.acked {
+		if resolved[key.chatID] {
+			delete(b.acked, key)
+		}
+	}
+	b.alertsMu.Unlock()
+
+	stale := b.takeAlerts(func(a sentAlert) bool { return resolved[a.chatID] })
+	if len(stale) > 0 && b.conf.AlertCleanup != "off" {
+		go b.cleanAlerts(context.Background(), stale, time.Now())
+	}
+}
+
+// alertCleanupLoop cleans up alerts older than ALERT_TTL.
+func (b *Bot) alertCleanupLoop(ctx context.Context) {
+	if b.conf.AlertCleanup == "off" || b.conf.AlertTTL <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired := b.takeAlerts(func(a sentAlert) bool { return now.Sub(a.sent) >= b.conf.AlertTTL })
+			b.cleanAlerts(ctx, expired, time.Time{})
+		}
+	}
+}
+
+// takeAlerts removes and returns the tracked alerts matching fn.
+func (b *Bot) takeAlerts(fn func(sentAlert) bool) []sentAlert {
+	b.alertsMu.Lock()
+	defer b.alertsMu.Unlock()
+	var taken, kept []sentAlert
+	for _, a := range b.alerts {
+		if fn(a) {
+			taken = append(taken, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+	b.alerts = kept
+	return taken
+}
+
+// cleanAlerts deletes the alerts or, with ALERT_CLEANUP=edit, strikes them through.
+// A zero resolved time means they expired rather than recovered.
+func (b *Bot) cleanAlerts(ctx context.Context, alerts []sentAlert, resolved time.Time) {
+	for _, a := range alerts {
+		var err error
+		if b.conf.AlertCleanup == "delete" {
+			_, err = a.client.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: a.chatID, MessageID: a.messageID})
+		} else {
+			text := i18n.T("alert.expired", a.text)
+			if !resolved.IsZero() {
+				text = i18n.T("alert.resolved", b.formatTime(resolved), a.text)
+			}
+			_, err = b.editMessageAs(ctx, a.client, &bot.EditMessageTextParams{
+				ChatID:    a.chatID,
+				MessageID: a.messageID,
+				Text:      text,
+				ParseMode: models.ParseModeHTML,
+			})
+		}
+		// Telegram doesn't allow deleting messages older than 48h, those just stay
+		if err != nil {
+			log.Warn().Err(err).Int64("chat_id", a.chatID).Int("message_id", a.messageID).Msg("Failed to clean up alert")
+		}
+	}
+	if len(alerts) > 0 {
+		log.Info().Int("alerts", len(alerts)).Str("mode", b.conf.AlertCleanup).Msg("Cleaned up stale alerts")
+	}
+}
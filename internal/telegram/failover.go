@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/rs/zerolog/log"
+)
+
+// failoverAfter is how many sends in a row must fail on the token or connection before the
+// sender switches to TELEGRAM_BACKUP_TOKEN.
+const failoverAfter = 5
+
+// tokenFailure reports whether err means the token or the Bot API itself is unusable, e.g. a
+// revoked token or a blocked api.telegram.org, rather than one chat rejecting a message.
+func tokenFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var syntaxErr *json.SyntaxError // an HTML page from a filtering proxy instead of the API
+	return errors.Is(err, bot.ErrorUnauthorized) || errors.Is(err, bot.ErrorNotFound) ||
+		unreachable(err) || errors.As(err, &syntaxErr)
+}
+
+// unreachable reports whether err means the Bot API couldn't be reached at all.
+func unreachable(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+}
+
+// sender returns the client queued messages are sent with, the backup bot after a failover.
+func (b *Bot) sender() *bot.Bot {
+	b.failoverMu.Lock()
+	defer b.failoverMu.Unlock()
+	if !b.failedOver.IsZero() {
+		return b.backup
+	}
+	return b.client
+}
+
+// noteSend counts sends failing on the token and fails over to the backup bot once there
+// are failoverAfter of them in a row. There is no way back until a restart, so alerts don't
+// flip between two bots.
+func (b *Bot) noteSend(err error) {
+	b.failoverMu.Lock()
+	defer b.failoverMu.Unlock()
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	if !tokenFailure(err) {
+		return
+	}
+	b.failures++
+	if b.backup == nil || !b.failedOver.IsZero() || b.failures < failoverAfter {
+		return
+	}
+	b.failedOver = time.Now()
+	log.Error().Err(err).Int("failures", b.failures).
+		Msg("Telegram failover: the primary token keeps failing, sending messages with TELEGRAM_BACKUP_TOKEN from now on")
+}
+
+// FailedOver returns when messages started going through the backup bot, zero if they don't.
+func (b *Bot) FailedOver() time.Time {
+	b.failoverMu.Lock()
+	defer b.failoverMu.Unlock()
+	return b.failedOver
+}
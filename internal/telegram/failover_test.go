@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestNoteSendFailsOver(t *testing.T) {
+	primary, backup := &bot.Bot{}, &bot.Bot{}
+	b := &Bot{client: primary, backup: backup}
+	revoked := fmt.Errorf("%w, Unauthorized", bot.ErrorUnauthorized)
+	blocked := fmt.Errorf("%w, Forbidden: bot was blocked by the user", bot.ErrorForbidden)
+
+	// Errors of a single chat don't count, and a success resets the streak
+	for range failoverAfter - 1 {
+		b.noteSend(revoked)
+	}
+	b.noteSend(blocked)
+	b.noteSend(nil)
+	b.noteSend(&url.Error{Op: "Post", URL: "https://api.telegram.org", Err: errors.New("connection reset")})
+	if b.sender() != primary {
+		t.Fatal("failed over before the primary token failed repeatedly")
+	}
+
+	for range failoverAfter - 1 {
+		b.noteSend(revoked)
+	}
+	if b.sender() != backup || b.FailedOver().IsZero() {
+		t.Fatalf("still on the primary bot after %d token failures", failoverAfter)
+	}
+}
@@ -0,0 +1,200 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Messages are written in Telegram's HTML subset and converted to the parse mode chosen with
+// TELEGRAM_PARSE_MODE right before sending. Dynamic content must be escaped with html.EscapeString.
+
+// render converts an HTML message to the configured parse mode.
+func (b *Bot) render(text string) (string, models.ParseMode) {
+	switch b.conf.TelegramParseMode {
+	case "markdownv2":
+		return convertHTML(text, true), models.ParseModeMarkdown
+	case "plain":
+		return convertHTML(text, false), ""
+	default:
+		return text, models.ParseModeHTML
+	}
+}
+
+// isParseError reports whether Telegram rejected a message because of its markup.
+func isParseError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "can't parse entities")
+}
+
+// sendMessage sends an HTML message (params with ParseModeHTML) in the configured parse mode.
+// If Telegram can't parse the markup, the message is sent again as plain text instead of getting lost.
+func (b *Bot) sendMessage(ctx context.Context, params *bot.SendMessageParams) (*models.Message, error) {
+	return b.sendMessageAs(ctx, b.client, params)
+}
+
+// sendMessageAs is sendMessage through the given client, e.g. the backup bot.
+func (b *Bot) sendMessageAs(ctx context.Context, client *bot.Bot, params *bot.SendMessageParams) (*models.Message, error) {
+	if params.ParseMode != models.ParseModeHTML {
+		return client.SendMessage(ctx, params)
+	}
+	source := params.Text
+	params.Text, params.ParseMode = b.render(source)
+	msg, err := client.SendMessage(ctx, params)
+	if isParseError(err) && params.ParseMode != "" {
+		log.Warn().Err(err).Msg("Telegram rejected message markup, sending it as plain text")
+		params.Text, params.ParseMode = convertHTML(source, false), ""
+		msg, err = client.SendMessage(ctx, params)
+	}
+	return msg, err
+}
+
+// editMessage is sendMessage for EditMessageText.
+func (b *Bot) editMessage(ctx context.Context, params *bot.EditMessageTextParams) (*models.Message, error) {
+	return b.editMessageAs(ctx, b.client, params)
+}
+
+// editMessageAs is editMessage through the given client, which must be the one that sent the message.
+func (b *Bot) editMessageAs(ctx context.Context, client *bot.Bot, params *bot.EditMessageTextParams) (*models.Message, error) {
+	if params.ParseMode != models.ParseModeHTML {
+		return client.EditMessageText(ctx, params)
+	}
+	source := params.Text
+	params.Text, params.ParseMode = b.render(source)
+	msg, err := client.EditMessageText(ctx, params)
+	if isParseError(err) && params.ParseMode != "" {
+		log.Warn().Err(err).Msg("Telegram rejected message markup, editing it as plain text")
+		params.Text, params.ParseMode = convertHTML(source, false), ""
+		msg, err = client.EditMessageText(ctx, params)
+	}
+	return msg, err
+}
+
+// sendPhotoAs is sendMessageAs for a photo with an HTML caption.
+func (b *Bot) sendPhotoAs(ctx context.Context, client *bot.Bot, params *bot.SendPhotoParams, png []byte) (*models.Message, error) {
+	source := params.Caption
+	params.Caption, params.ParseMode = b.render(source)
+	params.Photo = &models.InputFileUpload{Filename: "chart.png", Data: bytes.NewReader(png)}
+	msg, err := client.SendPhoto(ctx, params)
+	if isParseError(err) && params.ParseMode != "" {
+		log.Warn().Err(err).Msg("Telegram rejected caption markup, sending it as plain text")
+		params.Caption, params.ParseMode = convertHTML(source, false), ""
+		// The upload reader was used up by the first attempt
+		params.Photo = &models.InputFileUpload{Filename: "chart.png", Data: bytes.NewReader(png)}
+		msg, err = client.SendPhoto(ctx, params)
+	}
+	return msg, err
+}
+
+// markdownTags maps HTML tags to their MarkdownV2 markers.
+var markdownTags = map[string]string{
+	"b": "*", "strong": "*",
+	"i": "_", "em": "_",
+	"u": "__", "ins": "__",
+	"s": "~", "strike": "~", "del": "~",
+	"tg-spoiler": "||",
+}
+
+// convertHTML turns Telegram HTML into MarkdownV2, or into plain text with markdown false.
+// Tags MarkdownV2 has no equivalent for are dropped, keeping their text.
+func convertHTML(s string, markdown bool) string {
+	var sb strings.Builder
+	var inPre, inCode bool
+	var links []string // href of every open <a>
+
+	write := func(text string) {
+		switch {
+		case !markdown:
+			sb.WriteString(text)
+		case inPre || inCode:
+			sb.WriteString(escapeMarkdown(text, "`\\"))
+		default:
+			sb.WriteString(escapeMarkdown(text, "_*[]()~`>#+-=|{}.!\\"))
+		}
+	}
+
+	for s != "" {
+		i := strings.IndexAny(s, "<&")
+		if i < 0 {
+			write(s)
+			break
+		}
+		write(s[:i])
+		s = s[i:]
+
+		if s[0] == '&' {
+			end := strings.IndexByte(s, ';')
+			if end < 0 || end > 10 {
+				write("&")
+				s = s[1:]
+				continue
+			}
+			write(html.UnescapeString(s[:end+1]))
+			s = s[end+1:]
+			continue
+		}
+
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			write(s)
+			break
+		}
+		tag := s[1:end]
+		s = s[end+1:]
+		if !markdown {
+			continue
+		}
+
+		closing := strings.HasPrefix(tag, "/")
+		name, attrs, _ := strings.Cut(strings.TrimPrefix(tag, "/"), " ")
+		name = strings.ToLower(name)
+		switch {
+		case markdownTags[name] != "" && !inPre && !inCode:
+			sb.WriteString(markdownTags[name])
+		case name == "pre":
+			inPre = !closing
+			if closing {
+				sb.WriteString("\n```")
+			} else {
+				sb.WriteString("```\n")
+			}
+		case name == "code" && !inPre:
+			inCode = !closing
+			sb.WriteString("`")
+		case name == "a" && !closing:
+			links = append(links, hrefOf(attrs))
+			sb.WriteString("[")
+		case name == "a" && len(links) > 0:
+			href := links[len(links)-1]
+			links = links[:len(links)-1]
+			sb.WriteString("](" + escapeMarkdown(href, ")\\") + ")")
+		}
+	}
+	return sb.String()
+}
+
+// escapeMarkdown puts a backslash before every character of s found in special.
+func escapeMarkdown(s, special string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// hrefOf returns the href attribute of an <a> tag.
+func hrefOf(attrs string) string {
+	_, rest, ok := strings.Cut(attrs, `href="`)
+	if !ok {
+		return ""
+	}
+	href, _, _ := strings.Cut(rest, `"`)
+	return html.UnescapeString(href)
+}
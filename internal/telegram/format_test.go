@@ -0,0 +1,23 @@
+package telegram
+
+import "testing"
+
+func TestConvertHTML(t *testing.T) {
+	for _, tc := range []struct {
+		in, markdown, plain string
+	}{
+		{"<b>Speed</b>: 95.5 Mbps", `*Speed*: 95\.5 Mbps`, "Speed: 95.5 Mbps"},
+		{"<i>note</i> (1-2)", `_note_ \(1\-2\)`, "note (1-2)"},
+		{"error: dial tcp &lt;nil&gt; &amp; more", `error: dial tcp <nil\> & more`, "error: dial tcp <nil> & more"},
+		{"<code>a_b`c</code>", "`a_b\\`c`", "a_b`c"},
+		{"<pre>1.1.1.1 *</pre>", "```\n1.1.1.1 *\n```", "1.1.1.1 *"},
+		{`<a href="https://example.com/r?a=1&amp;b=(2)">result</a>`, `[result](https://example.com/r?a=1&b=(2\))`, "result"},
+	} {
+		if got := convertHTML(tc.in, true); got != tc.markdown {
+			t.Errorf("markdown %q = %q, want %q", tc.in, got, tc.markdown)
+		}
+		if got := convertHTML(tc.in, false); got != tc.plain {
+			t.Errorf("plain %q = %q, want %q", tc.in, got, tc.plain)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// inlineRole is roleOf for inline queries, which come without a chat. Unless ALLOWED_USER_IDS
+// is set, the user must be in one of the configured chats, or have it as their private chat.
+func (b *Bot) inlineRole(ctx context.Context, userID int64) role {
+	if r := b.roleOf(userID, userID); r != roleNone || len(b.conf.AllowedUserIDs) > 0 {
+		return r
+	}
+	for _, id := range b.Chats() {
+		// Channel subscribers are an audience, not the people the bot works for
+		if id > 0 || b.isChannel(id) {
+			continue
+		}
+		member, err := b.client.GetChatMember(ctx, &bot.GetChatMemberParams{ChatID: id, UserID: userID})
+		if err != nil {
+			log.Warn().Err(err).Int64("chat_id", id).Msg("Failed to look up chat member")
+			continue
+		}
+		switch member.Type {
+		case models.ChatMemberTypeOwner, models.ChatMemberTypeAdministrator, models.ChatMemberTypeMember, models.ChatMemberTypeRestricted:
+			return b.roleOf(userID, id)
+		}
+	}
+	return roleNone
+}
+
+// inlineHandler answers inline queries such as "@tetrabot stats 7d" or "@tetrabot last" with
+// summaries that can be posted to any chat. An empty query offers the last 24h and the latest result.
+func (b *Bot) inlineHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	query := update.InlineQuery
+	args := strings.Fields(strings.ToLower(query.Query))
+	if len(args) > 0 && args[0] == "stats" {
+		args = args[1:]
+	}
+
+	results := []models.InlineQueryResult{} // Telegram rejects a null results list
+	switch {
+	case len(args) == 0:
+		results = append(results, b.inlineStats(ctx, query.From.ID, 24*time.Hour), b.inlineLast(ctx))
+	case len(args) == 1 && args[0] == "last":
+		results = append(results, b.inlineLast(ctx))
+	case len(args) == 1:
+		if period, err := parsePeriod(args[0]); err == nil {
+			results = append(results, b.inlineStats(ctx, query.From.ID, period))
+		}
+	}
+
+	_, err := b.client.AnswerInlineQuery(ctx, &bot.AnswerInlineQueryParams{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     60,
+		IsPersonal:    true, // thresholds in the summaries are the user's own
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to answer inline query")
+	}
+}
+
+func (b *Bot) inlineStats(ctx context.Context, userID int64, period time.Duration) models.InlineQueryResult {
+	p := stats.FormatPeriod(period)
+	return b.inlineArticle("stats:"+period.String(), i18n.T("inline.stats", p), i18n.T("inline.stats_desc", p),
+		b.actions.Stats(ctx, userID, period))
+}
+
+func (b *Bot) inlineLast(ctx context.Context) models.InlineQueryResult {
+	return b.inlineArticle("last", i18n.T("inline.last"), i18n.T("inline.last_desc"), b.actions.Last(ctx))
+}
+
+// inlineArticle wraps an HTML message as an inline result, rendered in the configured parse mode.
+func (b *Bot) inlineArticle(id, title, description, text string) models.InlineQueryResult {
+	text, mode := b.render(text)
+	return &models.InlineQueryResultArticle{
+		ID:                  id,
+		Title:               title,
+		Description:         description,
+		InputMessageContent: &models.InputTextMessageContent{MessageText: text, ParseMode: mode},
+	}
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// muteHandler serves "/mute 3h", which silences alerts and notices in the chat for a while,
+// and "/mute off" or "/unmute" to end it early. Tests keep running and results are recorded.
+func (b *Bot) muteHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)
+	command, _, _ := strings.Cut(args[0], "@")
+
+	var until time.Time
+	switch {
+	case command == "/unmute" || len(args) == 2 && args[1] == "off":
+	case len(args) == 2:
+		d, err := parsePeriod(args[1])
+		if err != nil {
+			b.reply(ctx, chatID, i18n.T("mute.usage"))
+			return
+		}
+		until = time.Now().Add(d).Round(0)
+	default:
+		b.reply(ctx, chatID, i18n.T("mute.usage"))
+		return
+	}
+
+	_, err := b.settings.UpdateChat(chatID, func(v *config.ChatValues) {
+		v.MutedUntil = until
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update settings")
+		b.reply(ctx, chatID, i18n.T("settings.save_failed"))
+		return
+	}
+	log.Info().Int64("user_id", update.Message.From.ID).Int64("chat_id", chatID).Time("muted_until", until).Msg("Chat mute changed from the bot")
+
+	switch {
+	case until.IsZero():
+		b.reply(ctx, chatID, i18n.T("mute.ended"))
+	case !b.isChat(chatID):
+		b.reply(ctx, chatID, i18n.T("mute.set", b.formatTime(until))+i18n.T("mute.no_alerts"))
+	default:
+		b.reply(ctx, chatID, i18n.T("mute.set", b.formatTime(until)))
+	}
+}
+
+// muteLoop lifts expired mutes and tells the chat that alerts are back on.
+func (b *Bot) muteLoop(ctx context.Context) {
+	for {
+		changed := b.settings.Changed()
+		now := time.Now()
+		var next time.Time
+		for _, id := range b.Chats() {
+			until := b.settings.ForChat(id).MutedUntil
+			switch {
+			case until.IsZero():
+			case !now.Before(until):
+				b.unmute(id)
+			case next.IsZero() || until.Before(next):
+				next = until
+			}
+		}
+
+		var expired <-chan time.Time
+		if !next.IsZero() {
+			expired = time.After(next.Sub(now))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-expired:
+		}
+	}
+}
+
+func (b *Bot) unmute(chatID int64) {
+	_, err := b.settings.UpdateChat(chatID, func(v *config.ChatValues) {
+		v.MutedUntil = time.Time{}
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to lift mute")
+		return
+	}
+	log.Info().Int64("chat_id", chatID).Msg("Mute expired")
+	b.SendTo(ClassInfo, i18n.T("mute.expired"), chatID)
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// notifyHandler serves /notify, which sets what the sender gets in their private chat with the
+// bot: "/notify outages", "/notify reports", "/notify all" or "/notify off". Without an
+// argument it shows the current choice.
+func (b *Bot) notifyHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	if update.Message.From == nil {
+		return
+	}
+	userID := update.Message.From.ID
+	args := strings.Fields(update.Message.Text)[1:]
+	if len(args) == 0 {
+		current := b.settings.ForUser(userID).Notify
+		if current == "" {
+			current = config.NotifyOff
+			if b.isChat(userID) {
+				current = config.NotifyAll // a private chat in CHAT_ID gets everything by default
+			}
+		}
+		b.reply(ctx, chatID, i18n.T("notify.current", i18n.T("notify."+current))+i18n.T("notify.usage"))
+		return
+	}
+
+	choice := strings.ToLower(args[0])
+	if len(args) > 1 || !slices.Contains(config.NotifyOptions, choice) {
+		b.reply(ctx, chatID, i18n.T("notify.usage"))
+		return
+	}
+	if err := b.settings.SetUser(userID, config.UserValues{Notify: choice}); err != nil {
+		log.Error().Err(err).Msg("Failed to save notification preference")
+		b.reply(ctx, chatID, i18n.T("settings.save_failed"))
+		return
+	}
+	log.Info().Int64("user_id", userID).Str("notify", choice).Msg("Notification preference changed")
+
+	msg := i18n.T("notify.set", i18n.T("notify."+choice))
+	// Bots can only write to users who started a private chat with them
+	if chatID != userID && choice != config.NotifyOff {
+		msg += i18n.T("notify.start_private")
+	}
+	b.reply(ctx, chatID, msg)
+}
@@ -0,0 +1,38 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/rs/zerolog/log"
+)
+
+// pin pins a message, unpinning the one pinned before it. After a restart the previous pin
+// is only known if it is the chat's latest pin and was sent by the bot; channel posts carry
+// no sender, so there an old pin may stay until it is unpinned by hand.
+func (b *Bot) pin(ctx context.Context, chatID int64, messageID int) {
+	client := b.sender() // the bot that sent the message
+	previous, ok := b.pinned[chatID]
+	if !ok {
+		chat, err := client.GetChat(ctx, &bot.GetChatParams{ChatID: chatID})
+		if err == nil && chat.PinnedMessage != nil && chat.PinnedMessage.From != nil && chat.PinnedMessage.From.ID == client.ID() {
+			previous = chat.PinnedMessage.ID
+		}
+	}
+	if previous != 0 {
+		if _, err := client.UnpinChatMessage(ctx, &bot.UnpinChatMessageParams{ChatID: chatID, MessageID: previous}); err != nil {
+			log.Warn().Err(err).Int64("chat_id", chatID).Msg("Failed to unpin previous message")
+		}
+	}
+
+	_, err := client.PinChatMessage(ctx, &bot.PinChatMessageParams{
+		ChatID:              chatID,
+		MessageID:           messageID,
+		DisableNotification: true,
+	})
+	if err != nil {
+		log.Warn().Err(err).Int64("chat_id", chatID).Msg("Failed to pin message, does the bot have the pin right?")
+		return
+	}
+	b.pinned[chatID] = messageID
+}
@@ -0,0 +1,229 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// outgoing is a queued message and the chats it still has to be delivered to.
+type outgoing struct {
+	ID      int64     `json:"id"`
+	Class   Class     `json:"class,omitempty"`
+	Text    string    `json:"text"`
+	Photo   []byte    `json:"photo,omitempty"`   // PNG sent with Text as its caption
+	Pin     bool      `json:"pin,omitempty"`     // pin the message once sent, replacing the previous pin
+	Mention bool      `json:"mention,omitempty"` // mention ALERT_MENTIONS in group chats
+	Hold    bool      `json:"hold,omitempty"`    // keep retrying while Telegram is unreachable
+	Alert   string    `json:"alert,omitempty"`   // condition of an alert or recovery, see notify.Message
+	Held    time.Time `json:"held,omitzero"`     // held back by quiet hours at that time, see Bot.Hold
+	Pending []int64   `json:"pending"`
+}
+
+// maxHeldPerChat caps the alerts held for one chat's quiet hours digest; older ones are dropped.
+const maxHeldPerChat = 50
+
+// messageQueue is the FIFO of outgoing messages. When a path is configured,
+// the queue is mirrored to disk on every change so pending alerts survive restarts.
+type messageQueue struct {
+	mu     sync.Mutex
+	path   string
+	limit  int
+	items  []*outgoing
+	nextID int64
+	wake   chan struct{}
+}
+
+func newMessageQueue(path string, limit int) (*messageQueue, error) {
+	q := &messageQueue{
+		path:  path,
+		limit: limit,
+		wake:  make(chan struct{}, 1),
+	}
+	if path == "" {
+		return q, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message queue: %w", err)
+	}
+	if err := json.Unmarshal(data, &q.items); err != nil {
+		return nil, fmt.Errorf("failed to decode message queue: %w", err)
+	}
+	for _, m := range q.items {
+		if m.ID >= q.nextID {
+			q.nextID = m.ID + 1
+		}
+	}
+	if len(q.items) > 0 {
+		log.Info().Int("messages", len(q.items)).Msg("Replaying pending Telegram messages from disk")
+		q.signal()
+	}
+	return q, nil
+}
+
+// push appends a message for the given chats. It returns false if the queue is full;
+// held messages don't count towards the limit.
+func (q *messageQueue) push(msg outgoing, chatIDs []int64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.pending() >= q.limit {
+		return false
+	}
+	msg.ID = q.nextID
+	msg.Pending = slices.Clone(chatIDs)
+	q.items = append(q.items, &msg)
+	q.nextID++
+	q.persist()
+	q.signal()
+	return true
+}
+
+// hold appends a message held back for chatID, dropping the oldest held for it beyond maxHeldPerChat.
+func (q *messageQueue) hold(msg outgoing, chatID int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	msg.ID = q.nextID
+	msg.Pending = []int64{chatID}
+	q.items = append(q.items, &msg)
+	q.nextID++
+
+	held := 0
+	for i := len(q.items) - 1; i >= 0; i-- {
+		m := q.items[i]
+		if m.Held.IsZero() || !slices.Equal(m.Pending, msg.Pending) {
+			continue
+		}
+		if held++; held > maxHeldPerChat {
+			q.items = slices.Delete(q.items, i, i+1)
+		}
+	}
+	q.persist()
+}
+
+// len returns the number of messages waiting for delivery, not counting held ones.
+func (q *messageQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending()
+}
+
+// pending is len for callers holding the lock.
+func (q *messageQueue) pending() int {
+	n := 0
+	for _, m := range q.items {
+		if m.Held.IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+// peek returns a copy of the oldest message that isn't held, or nil if there is none.
+func (q *messageQueue) peek() *outgoing {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, m := range q.items {
+		if !m.Held.IsZero() {
+			continue
+		}
+		c := *m
+		c.Pending = append([]int64(nil), c.Pending...)
+		return &c
+	}
+	return nil
+}
+
+// takeHeld removes the held messages and returns them per chat, oldest first.
+func (q *messageQueue) takeHeld() map[int64][]*outgoing {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	held := make(map[int64][]*outgoing)
+	q.items = slices.DeleteFunc(q.items, func(m *outgoing) bool {
+		if m.Held.IsZero() {
+			return false
+		}
+		for _, chatID := range m.Pending {
+			held[chatID] = append(held[chatID], m)
+		}
+		return true
+	})
+	if len(held) > 0 {
+		q.persist()
+	}
+	return held
+}
+
+// delivered records that message id reached chatID, so a replay won't send it there again.
+func (q *messageQueue) delivered(id, chatID int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, m := range q.items {
+		if m.ID != id {
+			continue
+		}
+		for i, c := range m.Pending {
+			if c == chatID {
+				m.Pending = append(m.Pending[:i], m.Pending[i+1:]...)
+				break
+			}
+		}
+	}
+	q.persist()
+}
+
+// remove drops message id from the queue once processing has finished.
+func (q *messageQueue) remove(id int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, m := range q.items {
+		if m.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			break
+		}
+	}
+	q.persist()
+}
+
+func (q *messageQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// persist writes the queue atomically. Caller must hold the lock.
+func (q *messageQueue) persist() {
+	if q.path == "" {
+		return
+	}
+	data, err := json.Marshal(q.items)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode message queue")
+		return
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Error().Err(err).Msg("Failed to write message queue")
+		return
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		log.Error().Err(err).Msg("Failed to replace message queue")
+	}
+}
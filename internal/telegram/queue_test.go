@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueueKeepsHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := newMessageQueue(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	q.hold(outgoing{Class: ClassAlert, Text: "night", Held: at}, 1)
+	q.push(outgoing{Class: ClassReport, Text: "report"}, []int64{1})
+
+	// Held alerts survive a restart and are skipped by delivery
+	q, err = newMessageQueue(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := q.peek(); m == nil || m.Text != "report" || q.len() != 1 {
+		t.Fatalf("peek = %+v, len = %d, want only the report", m, q.len())
+	}
+	q.remove(q.peek().ID)
+
+	held := q.takeHeld()
+	if len(held[1]) != 1 || held[1][0].Text != "night" || !held[1][0].Held.Equal(at) {
+		t.Fatalf("takeHeld = %+v", held)
+	}
+	if len(q.takeHeld()) != 0 || q.peek() != nil {
+		t.Error("held alert still queued after takeHeld")
+	}
+}
+
+func TestQueueHeldDontFillIt(t *testing.T) {
+	q, err := newMessageQueue("", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	for i := range maxHeldPerChat + 10 {
+		for _, chatID := range []int64{1, 2} {
+			q.hold(outgoing{Class: ClassAlert, Text: fmt.Sprint(i), Held: at}, chatID)
+		}
+	}
+
+	// Live messages still fit, and each chat keeps its newest held alerts
+	if !q.push(outgoing{Class: ClassAlert, Text: "down"}, []int64{1}) || !q.push(outgoing{Class: ClassAlert, Text: "down"}, []int64{2}) {
+		t.Fatal("held alerts filled the queue")
+	}
+	if q.push(outgoing{Class: ClassAlert, Text: "full"}, []int64{1}) {
+		t.Error("queue took more than its limit")
+	}
+	held := q.takeHeld()
+	if len(held[1]) != maxHeldPerChat || held[1][0].Text != "10" || len(held[2]) != maxHeldPerChat {
+		t.Errorf("held %d and %d alerts, first %q", len(held[1]), len(held[2]), held[1][0].Text)
+	}
+}
@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Telegram's documented limits: about 30 messages per second overall, one per second to a private
+// chat and 20 per minute to a group or channel. Going over them gets the bot a 429 and a pause.
+const (
+	globalRate  = 30
+	privateRate = 1
+	groupRate   = 20.0 / 60
+	groupBurst  = 5
+)
+
+// bucket is a token bucket refilled at rate tokens per second up to burst tokens.
+type bucket struct {
+	rate, burst float64
+	tokens      float64
+	last        time.Time
+}
+
+func newBucket(rate, burst float64) *bucket {
+	return &bucket{rate: rate, burst: burst, tokens: burst}
+}
+
+// refill adds the tokens earned since the last call.
+func (b *bucket) refill(now time.Time) {
+	if now.Before(b.last) {
+		return
+	}
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// delay returns how long until a token is available.
+func (b *bucket) delay() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// rateLimiter paces outgoing messages so bursts of alerts stay within the limits
+// instead of running into 429s.
+type rateLimiter struct {
+	mu     sync.Mutex
+	global *bucket
+	chats  map[int64]*bucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		global: newBucket(globalRate, globalRate),
+		chats:  make(map[int64]*bucket),
+	}
+}
+
+// reserve takes a token for chatID if both its bucket and the global one have one,
+// otherwise it returns how long to wait before trying again.
+func (l *rateLimiter) reserve(chatID int64, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	chat, ok := l.chats[chatID]
+	if !ok {
+		// Group, supergroup and channel IDs are negative
+		if chatID < 0 {
+			chat = newBucket(groupRate, groupBurst)
+		} else {
+			chat = newBucket(privateRate, 1)
+		}
+		l.chats[chatID] = chat
+	}
+	l.global.refill(now)
+	chat.refill(now)
+
+	if d := max(l.global.delay(), chat.delay()); d > 0 {
+		return d
+	}
+	l.global.tokens--
+	chat.tokens--
+	return 0
+}
+
+// wait blocks until a message may be sent to chatID.
+func (l *rateLimiter) wait(ctx context.Context, chatID int64) error {
+	for {
+		d := l.reserve(chatID, time.Now())
+		if d == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
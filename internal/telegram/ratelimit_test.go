@@ -0,0 +1,43 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := newRateLimiter()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A group gets a short burst, then one message every three seconds
+	for i := range groupBurst {
+		if d := l.reserve(-100, now); d != 0 {
+			t.Fatalf("message %d to the group delayed by %v", i+1, d)
+		}
+	}
+	if d := l.reserve(-100, now); d != 3*time.Second {
+		t.Errorf("message after the burst delayed by %v, want 3s", d)
+	}
+	if d := l.reserve(-100, now.Add(3*time.Second)); d != 0 {
+		t.Errorf("message after refill delayed by %v", d)
+	}
+
+	// Private chats allow one message per second, independently of the group
+	if d := l.reserve(42, now); d != 0 {
+		t.Errorf("first private message delayed by %v", d)
+	}
+	if d := l.reserve(42, now.Add(500*time.Millisecond)); d != 500*time.Millisecond {
+		t.Errorf("second private message delayed by %v, want 500ms", d)
+	}
+
+	// The global limit applies across chats
+	g := newRateLimiter()
+	for id := range int64(globalRate) {
+		if d := g.reserve(id+1, now); d != 0 {
+			t.Fatalf("message to chat %d delayed by %v", id+1, d)
+		}
+	}
+	if d := g.reserve(1000, now); d == 0 {
+		t.Error("message over the global limit was not delayed")
+	}
+}
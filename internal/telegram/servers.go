@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// callbackServer is followed by the server ID to pin, or "auto" for the closest server.
+const callbackServer = "server:"
+
+// serverHandler lists nearby speedtest.net servers with buttons to pin one.
+func (b *Bot) serverHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	servers, err := b.actions.Servers(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list speedtest servers")
+		_, err = b.sendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      i18n.T("servers.failed"),
+			ParseMode: models.ParseModeHTML,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to send server list error message")
+		}
+		return
+	}
+
+	pinned := b.settings.Get().PinnedServer()
+	var sb strings.Builder
+	sb.WriteString(i18n.T("servers.title"))
+	var rows [][]models.InlineKeyboardButton
+	for _, s := range servers {
+		mark := ""
+		if s.ID == pinned {
+			mark = " 📌"
+		}
+		latency := i18n.T("servers.timeout")
+		if s.Latency > 0 {
+			latency = i18n.T("servers.latency", s.Latency.Milliseconds())
+		}
+		sb.WriteString(i18n.T("servers.line",
+			html.EscapeString(s.ID), html.EscapeString(s.Sponsor), html.EscapeString(s.Name),
+			html.EscapeString(s.Country), s.Distance, latency, mark))
+		rows = append(rows, []models.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("📌 %s, %s", s.Sponsor, s.Name),
+			CallbackData: callbackServer + s.ID,
+		}})
+	}
+	if pinned == "" {
+		sb.WriteString(i18n.T("servers.current_auto"))
+	} else {
+		sb.WriteString(i18n.T("servers.current_pinned", html.EscapeString(pinned)))
+	}
+	rows = append(rows, []models.InlineKeyboardButton{{Text: i18n.T("servers.button_auto"), CallbackData: callbackServer + config.ServerAuto}})
+
+	_, err = b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        sb.String(),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: rows},
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send server list")
+	}
+}
+
+// pinServer applies a server button. The choice is kept with the runtime settings.
+func (b *Bot) pinServer(ctx context.Context, chatID int64, query *models.CallbackQuery) {
+	id := strings.TrimPrefix(query.Data, callbackServer)
+	v, err := b.settings.Update(func(v *config.Values) {
+		v.ServerID = id
+	})
+	id = v.PinnedServer()
+
+	text := i18n.T("servers.auto")
+	switch {
+	case err != nil:
+		log.Error().Err(err).Msg("Failed to update settings")
+		text = i18n.T("settings.save_failed")
+	case id != "":
+		text = i18n.T("servers.pinned", html.EscapeString(id))
+	}
+	if err == nil {
+		log.Info().Str("server_id", id).Int64("user_id", query.From.ID).Msg("Speedtest server pinned from the bot")
+	}
+
+	_, err = b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send server message")
+	}
+}
@@ -0,0 +1,267 @@
+package telegram
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// callbackSet is followed by "<setting>:<step>", e.g. "set:dl:+10".
+const callbackSet = "set:"
+
+// Step sizes of the settings menu buttons
+const (
+	thresholdStep = 10.0
+	intervalStep  = 5 * time.Minute
+)
+
+func (b *Bot) settingsHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	b.sendSettings(ctx, update.Message.Chat.ID)
+}
+
+// pauseHandler serves /pause and /resume, which stop and restart scheduled tests for everyone.
+func (b *Bot) pauseHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	paused := update.Message.Text == "/pause"
+	_, err := b.settings.Update(func(v *config.Values) {
+		v.Paused = paused
+	})
+
+	text := i18n.T("pause.resumed")
+	switch {
+	case err != nil:
+		log.Error().Err(err).Msg("Failed to update settings")
+		text = i18n.T("settings.save_failed")
+	case paused:
+		text = i18n.T("pause.paused")
+	}
+	if err == nil {
+		log.Info().Bool("paused", paused).Int64("user_id", update.Message.From.ID).Msg("Monitoring pause changed from the bot")
+	}
+
+	_, err = b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send pause message")
+	}
+}
+
+// setIntervalHandler serves "/setinterval 15m", which reschedules tests right away.
+func (b *Bot) setIntervalHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)[1:]
+	if len(args) != 1 {
+		b.reply(ctx, chatID, i18n.T("interval.usage"))
+		return
+	}
+	interval, err := parsePeriod(args[0])
+	if err != nil {
+		b.reply(ctx, chatID, i18n.T("interval.usage"))
+		return
+	}
+
+	v, err := b.settings.Update(func(v *config.Values) {
+		v.CheckInterval = interval
+	})
+	if err != nil {
+		log.Warn().Err(err).Dur("check_interval", interval).Msg("Failed to change check interval")
+		b.reply(ctx, chatID, i18n.T("interval.invalid", html.EscapeString(err.Error())))
+		return
+	}
+	log.Info().Int64("user_id", update.Message.From.ID).Dur("check_interval", v.CheckInterval).Msg("Check interval changed from the bot")
+
+	text := i18n.T("interval.set", stats.FormatPeriod(v.CheckInterval))
+	if b.conf.SettingsPath == "" {
+		text += i18n.T("interval.not_saved")
+	}
+	b.reply(ctx, chatID, text)
+}
+
+func (b *Bot) sendSettings(ctx context.Context, chatID int64) {
+	_, err := b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        b.settingsText(chatID),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.settingsKeyboard(chatID),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send settings message")
+	}
+}
+
+// settingsText shows the chat's own preferences and the global schedule.
+func (b *Bot) settingsText(chatID int64) string {
+	c := b.conf
+	v := b.settings.Get()
+	cv := b.settings.ForChat(chatID)
+	msg := i18n.T("settings.title") +
+		i18n.T("settings.download", cv.DownloadThreshold) +
+		i18n.T("settings.upload", cv.UploadThreshold) +
+		i18n.T("settings.report", cv.DailyReportHour, html.EscapeString(c.TimeZone)) +
+		i18n.T("settings.alerts", verbosityName(cv.Verbosity)) +
+		i18n.T("settings.charts", chartsName(cv.TextCharts)) +
+		i18n.T("settings.interval", stats.FormatPeriod(v.CheckInterval)) +
+		pausedLine(v.Paused) +
+		b.mutedLine(cv) +
+		i18n.T("settings.engines", html.EscapeString(strings.Join(c.SpeedtestEngines, ", ")))
+	if c.JitterThreshold > 0 {
+		msg += i18n.T("settings.jitter", c.JitterThreshold)
+	}
+	if len(b.Chats()) > 1 {
+		msg += i18n.T("settings.per_chat")
+	}
+	return msg
+}
+
+func (b *Bot) mutedLine(cv config.ChatValues) string {
+	if !cv.Muted(time.Now()) {
+		return ""
+	}
+	return i18n.T("settings.muted", b.formatTime(cv.MutedUntil))
+}
+
+func (b *Bot) settingsKeyboard(chatID int64) *models.InlineKeyboardMarkup {
+	row := func(label, key, down, up string) []models.InlineKeyboardButton {
+		return []models.InlineKeyboardButton{
+			{Text: label + " " + down, CallbackData: callbackSet + key + ":" + down},
+			{Text: label + " " + up, CallbackData: callbackSet + key + ":" + up},
+		}
+	}
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			row("⬇️", "dl", "-10", "+10"),
+			row("⬆️", "ul", "-10", "+10"),
+			row("📊", "hour", "-1", "+1"),
+			{{Text: i18n.T("settings.switch_alerts", verbosityName(nextVerbosity(b.settings.ForChat(chatID).Verbosity))), CallbackData: callbackSet + "verbosity:next"}},
+			{{Text: i18n.T("settings.switch_charts", chartsName(!b.settings.ForChat(chatID).TextCharts)), CallbackData: callbackSet + "charts:next"}},
+			row("⏱", "interval", "-5m", "+5m"),
+		},
+	}
+}
+
+// nextVerbosity cycles full → short → off.
+func nextVerbosity(v string) string {
+	switch v {
+	case config.VerbosityFull:
+		return config.VerbosityShort
+	case config.VerbosityShort:
+		return config.VerbosityOff
+	default:
+		return config.VerbosityFull
+	}
+}
+
+func verbosityName(v string) string {
+	return i18n.T("verbosity." + v)
+}
+
+// chartsName names how reports show speed over time: chart images or sparklines in the text.
+func chartsName(text bool) string {
+	if text {
+		return i18n.T("charts.text")
+	}
+	return i18n.T("charts.image")
+}
+
+// adjustSetting applies a settings menu button and updates the menu message in place.
+func (b *Bot) adjustSetting(ctx context.Context, chatID int64, query *models.CallbackQuery) {
+	// Only the configured chats may change settings, not anyone who finds the bot
+	if !b.isChat(chatID) {
+		log.Warn().Int64("chat_id", chatID).Int64("user_id", query.From.ID).Msg("Unauthorized settings change")
+		return
+	}
+
+	key, step, ok := strings.Cut(strings.TrimPrefix(query.Data, callbackSet), ":")
+	if !ok {
+		log.Warn().Str("data", query.Data).Msg("Invalid settings callback")
+		return
+	}
+	sign := 1
+	if strings.HasPrefix(step, "-") {
+		sign = -1
+	}
+
+	var changed bool
+	var err error
+	if key == "interval" {
+		before := b.settings.Get()
+		var v config.Values
+		v, err = b.settings.Update(func(v *config.Values) {
+			v.CheckInterval = max(intervalStep, v.CheckInterval+time.Duration(sign)*intervalStep)
+		})
+		changed = v != before
+		if changed {
+			log.Info().Int64("user_id", query.From.ID).Dur("check_interval", v.CheckInterval).Msg("Check interval changed from the bot")
+		}
+	} else {
+		before := b.settings.ForChat(chatID)
+		var v config.ChatValues
+		v, err = b.settings.UpdateChat(chatID, func(v *config.ChatValues) {
+			switch key {
+			case "dl":
+				v.DownloadThreshold = max(0, v.DownloadThreshold+float64(sign)*thresholdStep)
+			case "ul":
+				v.UploadThreshold = max(0, v.UploadThreshold+float64(sign)*thresholdStep)
+			case "hour":
+				v.DailyReportHour = (v.DailyReportHour + sign + 24) % 24
+			case "verbosity":
+				v.Verbosity = nextVerbosity(v.Verbosity)
+			case "charts":
+				v.TextCharts = !v.TextCharts
+			}
+		})
+		changed = v != before
+		if changed {
+			log.Info().
+				Int64("chat_id", chatID).
+				Int64("user_id", query.From.ID).
+				Float64("download_threshold", v.DownloadThreshold).
+				Float64("upload_threshold", v.UploadThreshold).
+				Int("daily_report_hour", v.DailyReportHour).
+				Str("verbosity", v.Verbosity).
+				Bool("text_charts", v.TextCharts).
+				Msg("Chat settings changed from the bot")
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update settings")
+		return
+	}
+	if !changed {
+		// Already at a limit; Telegram rejects edits that don't change the message
+		return
+	}
+
+	if query.Message.Message == nil {
+		b.sendSettings(ctx, chatID)
+		return
+	}
+	_, err = b.editMessage(ctx, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   query.Message.Message.ID,
+		Text:        b.settingsText(chatID),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.settingsKeyboard(chatID),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update settings message")
+	}
+}
+
+func pausedLine(paused bool) string {
+	if paused {
+		return i18n.T("settings.paused")
+	}
+	return ""
+}
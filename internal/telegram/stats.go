@@ -0,0 +1,193 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/chart"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+const dateLayout = "2006-01-02"
+
+// statsHandler serves /stats with an optional window: "/stats 7d", "/stats 12h",
+// "/stats 2024-05-01" or "/stats 2024-05-01 2024-05-07".
+func (b *Bot) statsHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	period, from, to, err := parseStatsRange(strings.Fields(update.Message.Text)[1:], b.location())
+	switch {
+	case err != nil:
+		b.reply(ctx, chatID, i18n.T("stats.usage"))
+	case period > 0:
+		b.sendStats(ctx, chatID, period)
+	default:
+		b.reply(ctx, chatID, b.actions.Range(ctx, chatID, from, to))
+	}
+}
+
+// reportHandler sends the daily report right away, chart included.
+func (b *Bot) reportHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	log.Info().Int64("chat_id", update.Message.Chat.ID).Msg("Daily report requested from the bot")
+	b.actions.Report(ctx, update.Message.Chat.ID)
+}
+
+// compareHandler serves "/compare", "/compare day", "/compare week" or "/compare 12h".
+func (b *Bot) compareHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)[1:]
+	period := 24 * time.Hour
+	switch {
+	case len(args) == 0 || args[0] == "day" && len(args) == 1:
+	case len(args) == 1 && args[0] == "week":
+		period = 7 * 24 * time.Hour
+	case len(args) == 1:
+		var err error
+		if period, err = parsePeriod(args[0]); err == nil {
+			break
+		}
+		fallthrough
+	default:
+		b.reply(ctx, chatID, i18n.T("compare.usage"))
+		return
+	}
+	b.reply(ctx, chatID, b.actions.Compare(ctx, period))
+}
+
+// aggregateHandler serves /week and /month.
+func (b *Bot) aggregateHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	days := 7
+	if update.Message.Text == "/month" {
+		days = 30
+	}
+	b.reply(ctx, update.Message.Chat.ID, b.actions.Aggregate(ctx, days))
+}
+
+// hoursHandler serves "/hours" or "/hours 30d", ranking the hours of the day by speed.
+func (b *Bot) hoursHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	days := 0
+	if args := strings.Fields(update.Message.Text)[1:]; len(args) > 0 {
+		period, err := parsePeriod(args[0])
+		if days = int(period / (24 * time.Hour)); err != nil || days == 0 || len(args) > 1 {
+			b.reply(ctx, chatID, i18n.T("hours.usage"))
+			return
+		}
+	}
+	b.reply(ctx, chatID, b.actions.Hours(ctx, days))
+}
+
+// graphHandler serves /graph [metric] [period], e.g. "/graph download 7d". Both are optional
+// and may come in either order; the default is every metric over the last 24h.
+func (b *Bot) graphHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	metric, period := chart.MetricAll, 24*time.Hour
+	for _, arg := range strings.Fields(update.Message.Text)[1:] {
+		if m := chart.Metric(strings.ToLower(arg)); slices.Contains(chart.Metrics, m) {
+			metric = m
+			continue
+		}
+		var err error
+		if period, err = parsePeriod(arg); err != nil {
+			b.reply(ctx, chatID, i18n.T("graph.usage"))
+			return
+		}
+	}
+
+	png, err := b.actions.Graph(ctx, metric, period)
+	switch {
+	case errors.Is(err, chart.ErrNotEnoughData):
+		b.reply(ctx, chatID, i18n.T("graph.empty", stats.FormatPeriod(period)))
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("Failed to render graph")
+		b.reply(ctx, chatID, i18n.T("graph.failed"))
+		return
+	}
+	_, err = b.sendPhotoAs(ctx, b.client, &bot.SendPhotoParams{
+		ChatID:      chatID,
+		Caption:     i18n.T("graph.caption", i18n.T("graph.metric."+string(metric)), stats.FormatPeriod(period)),
+		ReplyMarkup: b.getMainKeyboard(),
+	}, png)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send graph")
+	}
+}
+
+// legacyStatsHandler serves the "Get Stats" button of the old reply keyboard.
+func (b *Bot) legacyStatsHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	b.sendStats(ctx, update.Message.Chat.ID, 24*time.Hour)
+}
+
+func (b *Bot) sendStats(ctx context.Context, chatID int64, period time.Duration) {
+	b.reply(ctx, chatID, b.actions.Stats(ctx, chatID, period))
+}
+
+func (b *Bot) reply(ctx context.Context, chatID int64, text string) {
+	_, err := b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send reply")
+	}
+}
+
+// parseStatsRange turns /stats arguments into either a period ending now or a fixed window.
+// No arguments mean the last 24h; dates cover whole days in loc, both ends included.
+func parseStatsRange(args []string, loc *time.Location) (period time.Duration, from, to time.Time, err error) {
+	switch len(args) {
+	case 0:
+		return 24 * time.Hour, time.Time{}, time.Time{}, nil
+	case 1:
+		if period, err = parsePeriod(args[0]); err == nil {
+			return period, time.Time{}, time.Time{}, nil
+		}
+		args = append(args, args[0])
+	case 2:
+	default:
+		return 0, time.Time{}, time.Time{}, errors.New("too many arguments")
+	}
+
+	if from, err = time.ParseInLocation(dateLayout, args[0], loc); err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	last, err := time.ParseInLocation(dateLayout, args[1], loc)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	if last.Before(from) {
+		return 0, time.Time{}, time.Time{}, errors.New("end date before start date")
+	}
+	return 0, from, last.AddDate(0, 0, 1).Add(-time.Second), nil
+}
+
+// parsePeriod accepts Go durations like "12h" and whole days like "7d".
+func parsePeriod(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, errors.New("period must be positive")
+	}
+	return d, nil
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStatsRange(t *testing.T) {
+	loc := time.FixedZone("EET", 2*60*60)
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, s, loc)
+		return d
+	}
+
+	for _, tc := range []struct {
+		args     []string
+		period   time.Duration
+		from, to time.Time
+		wantErr  bool
+	}{
+		{args: nil, period: 24 * time.Hour},
+		{args: []string{"7d"}, period: 7 * 24 * time.Hour},
+		{args: []string{"12h"}, period: 12 * time.Hour},
+		{args: []string{"2024-05-01"}, from: day("2024-05-01"), to: day("2024-05-02").Add(-time.Second)},
+		{args: []string{"2024-05-01", "2024-05-07"}, from: day("2024-05-01"), to: day("2024-05-08").Add(-time.Second)},
+		{args: []string{"2024-05-07", "2024-05-01"}, wantErr: true},
+		{args: []string{"-3d"}, wantErr: true},
+		{args: []string{"soon"}, wantErr: true},
+		{args: []string{"1d", "2d", "3d"}, wantErr: true},
+	} {
+		period, from, to, err := parseStatsRange(tc.args, loc)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%v: expected an error", tc.args)
+			}
+			continue
+		}
+		if err != nil || period != tc.period || !from.Equal(tc.from) || !to.Equal(tc.to) {
+			t.Errorf("%v: got %v, %v, %v, %v", tc.args, period, from, to, err)
+		}
+	}
+}
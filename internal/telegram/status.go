@@ -0,0 +1,105 @@
+package telegram
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/notify"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Status is the process health shown by /status.
+type Status struct {
+	Version     string
+	Started     time.Time
+	LastSuccess time.Time // zero if no test succeeded since start
+	NextTest    time.Time // zero while scheduled tests are paused
+	Failing     []notify.Failure
+	// Failed tests in a row now and the longest run in the last 24h, zero without failures
+	FailureStreak, LongestStreak stats.Streak
+}
+
+func (b *Bot) statusHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	_, err := b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        b.statusText(b.actions.Status(ctx), chatID, time.Now()),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send status message")
+	}
+}
+
+// lastHandler shows the latest stored result without running a test.
+func (b *Bot) lastHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	_, err := b.sendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        b.actions.Last(ctx),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: b.getMainKeyboard(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send last result")
+	}
+}
+
+// alertsHandler serves /alerts, listing the latest alert events with how long they lasted, "/alerts 30" for more.
+func (b *Bot) alertsHandler(ctx context.Context, bb *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	n := 10
+	if args := strings.Fields(update.Message.Text)[1:]; len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 || len(args) > 1 {
+			b.reply(ctx, chatID, i18n.T("alerts.usage"))
+			return
+		}
+	}
+	b.reply(ctx, chatID, b.actions.Alerts(ctx, min(n, 50))) // a longer list would exceed Telegram's message size
+}
+
+func (b *Bot) statusText(st Status, chatID int64, now time.Time) string {
+	cv := b.settings.ForChat(chatID)
+	var sb strings.Builder
+	sb.WriteString(i18n.T("status.title"))
+	sb.WriteString(i18n.T("status.version", html.EscapeString(st.Version)))
+	sb.WriteString(i18n.T("status.uptime", stats.FormatPeriod(now.Sub(st.Started).Round(time.Minute))))
+	if st.LastSuccess.IsZero() {
+		sb.WriteString(i18n.T("status.last_none"))
+	} else {
+		sb.WriteString(i18n.T("status.last", b.formatTime(st.LastSuccess), stats.FormatPeriod(now.Sub(st.LastSuccess).Round(time.Minute))))
+	}
+	if st.NextTest.IsZero() {
+		sb.WriteString(i18n.T("status.next_paused"))
+	} else {
+		sb.WriteString(i18n.T("status.next", b.formatTime(st.NextTest)))
+	}
+	if s := st.FailureStreak; s.Tests > 0 {
+		sb.WriteString(i18n.T("status.streak", s.Tests, b.formatTime(s.Start)))
+	}
+	if s := st.LongestStreak; s.Tests > 0 {
+		sb.WriteString(i18n.T("status.longest", s.Tests, stats.FormatPeriod(s.Duration().Round(time.Minute))))
+	}
+	sb.WriteString(i18n.T("status.queue", b.queue.len()))
+	if t := b.FailedOver(); !t.IsZero() {
+		sb.WriteString(i18n.T("status.failover", b.formatTime(t)))
+	}
+	for _, f := range st.Failing {
+		sb.WriteString(i18n.T("status.notifier", f.Backend, b.formatTime(f.Since), f.Dropped, html.EscapeString(f.Err.Error())))
+	}
+	sb.WriteString(i18n.T("status.thresholds", cv.DownloadThreshold, cv.UploadThreshold))
+	return sb.String()
+}
+
+// formatTime renders a time in the configured time zone.
+func (b *Bot) formatTime(t time.Time) string {
+	return t.In(b.location()).Format("2006-01-02 15:04")
+}
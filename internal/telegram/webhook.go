@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/rs/zerolog/log"
+)
+
+// WebhookPath returns the path the webhook handler should be mounted on, "" in polling mode.
+func (b *Bot) WebhookPath() string {
+	if b.conf.TelegramWebhookURL == "" {
+		return ""
+	}
+	u, err := url.Parse(b.conf.TelegramWebhookURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// WebhookHandler accepts updates posted by Telegram. Requests without the configured
+// secret token are rejected, so nobody else can feed the bot fake updates.
+func (b *Bot) WebhookHandler() http.Handler {
+	handler := b.client.WebhookHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(b.conf.TelegramWebhookSecret)) != 1 {
+			log.Warn().Str("remote", r.RemoteAddr).Msg("Webhook request with an invalid secret token")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// startWebhook registers the webhook with Telegram and processes the updates it delivers.
+func (b *Bot) startWebhook(ctx context.Context) {
+	for {
+		_, err := b.client.SetWebhook(ctx, &bot.SetWebhookParams{
+			URL:         b.conf.TelegramWebhookURL,
+			SecretToken: b.conf.TelegramWebhookSecret,
+		})
+		if err == nil {
+			break
+		}
+		log.Error().Err(err).Msg("Failed to set Telegram webhook, retrying in 30s")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(30 * time.Second):
+		}
+	}
+
+	log.Info().Str("path", b.WebhookPath()).Msg("Starting Telegram bot in webhook mode...")
+	b.client.StartWebhook(ctx)
+}
@@ -0,0 +1,130 @@
+// Package templates lets users replace the wording of alerts, test results, recoveries and
+// daily reports with text/template files, without changing the code. Templates render
+// Telegram HTML, like the built-in messages they replace.
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// Name identifies a message template, loaded from <name>.tmpl.
+type Name string
+
+const (
+	Alert    Name = "alert"    // scheduled test alerts, data: AlertData
+	Result   Name = "result"   // a single test result, in alerts and /test replies, data: stats.Result
+	Recovery Name = "recovery" // connection, speed or endpoint back up, data: RecoveryData
+	Report   Name = "report"   // daily report, data: stats.Summary
+)
+
+// AlertData is what the alert template gets.
+type AlertData struct {
+	Results []stats.Result // results of the test cycle
+	Details []string       // probe reports (IP family, DNS), already formatted
+	Notices []string       // status changes sent along with the alert, already formatted
+	// Bad tests in a row that confirmed the alert, see ALERT_AFTER_FAILURES
+	Confirmations int
+	Severity      string // "warning" or "critical", see CRITICAL_*_THRESHOLD
+	Body          string // the built-in alert text without its heading
+}
+
+// RecoveryData is what the recovery template gets.
+type RecoveryData struct {
+	Target   string        // ping monitor host, endpoint URL or the label of the recovering speed test
+	Downtime time.Duration // how long the connection was lost or slow, zero for endpoints
+	Latency  time.Duration // endpoint response time, zero otherwise
+	Start    time.Time     // when the outage began, zero for endpoints
+	End      time.Time     // when it was over
+	Text     string        // the built-in message
+}
+
+// samples are used to check templates at startup, so mistakes surface before the first alert.
+var samples = map[Name]any{
+	Alert:    AlertData{Results: []stats.Result{{Time: time.Now()}}, Details: []string{""}, Notices: []string{""}},
+	Result:   stats.Result{Time: time.Now()},
+	Recovery: RecoveryData{Start: time.Now(), End: time.Now()},
+	Report:   stats.Summary{Engines: map[string]stats.EngineSummary{}},
+}
+
+var funcs = template.FuncMap{
+	"escape": html.EscapeString,
+	"join":   strings.Join,
+	"ms":     func(d time.Duration) int64 { return d.Milliseconds() },
+	"t":      i18n.T,
+}
+
+var (
+	mu     sync.RWMutex
+	loaded = map[Name]*template.Template{}
+)
+
+// Load reads every <name>.tmpl in dir and checks it against sample data. Messages without
+// a template keep their built-in wording. An empty dir clears the loaded templates.
+func Load(dir string) error {
+	set := map[Name]*template.Template{}
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			name := Name(strings.TrimSuffix(filepath.Base(file), ".tmpl"))
+			sample, ok := samples[name]
+			if !ok {
+				return fmt.Errorf("unknown template %s (available: alert, result, recovery, report)", file)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			tmpl, err := template.New(string(name)).Funcs(funcs).Option("missingkey=error").Parse(string(data))
+			if err != nil {
+				return err
+			}
+			if err := tmpl.Execute(io.Discard, sample); err != nil {
+				return fmt.Errorf("template %s: %w", file, err)
+			}
+			set[name] = tmpl
+		}
+		if len(set) == 0 {
+			return errors.New("no *.tmpl files in " + dir)
+		}
+	}
+
+	mu.Lock()
+	loaded = set
+	mu.Unlock()
+	return nil
+}
+
+// Render executes the named template with data, or returns fallback if there is no such
+// template or it fails.
+func Render(name Name, data any, fallback string) string {
+	mu.RLock()
+	tmpl := loaded[name]
+	mu.RUnlock()
+	if tmpl == nil {
+		return fallback
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Error().Err(err).Str("template", string(name)).Msg("Failed to render message template, using the built-in text")
+		return fallback
+	}
+	return strings.TrimSpace(buf.String())
+}
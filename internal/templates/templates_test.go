@@ -0,0 +1,45 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ckayt/tetra/internal/stats"
+)
+
+func writeTemplate(t *testing.T, dir, name, text string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAndRender(t *testing.T) {
+	defer Load("")
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "result.tmpl", `{{if .Error}}failed: {{escape .Error.Error}}{{else}}DL {{printf "%.0f" .Download}} / ping {{ms .Ping}}ms{{end}}`+"\n")
+	if err := Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := Render(Result, stats.Result{Download: 93.4, Ping: 12e6}, "builtin"); got != "DL 93 / ping 12ms" {
+		t.Errorf("Render() = %q", got)
+	}
+	if got := Render(Report, stats.Summary{}, "builtin"); got != "builtin" {
+		t.Errorf("Render() without a template = %q, want the fallback", got)
+	}
+
+	for _, tc := range []struct{ file, text string }{
+		{"result.tmpl", "{{.Speed}}"},
+		{"result.tmpl", "{{if}}"},
+		{"summary.tmpl", "{{.TotalTests}}"},
+		{"recovery.tmpl", "{{.Target | bogus}}"},
+	} {
+		bad := t.TempDir()
+		writeTemplate(t, bad, tc.file, tc.text)
+		if err := Load(bad); err == nil {
+			t.Errorf("Load() accepted %s: %s", tc.file, tc.text)
+		}
+	}
+}
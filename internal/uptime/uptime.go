@@ -0,0 +1,112 @@
+package uptime
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ckayt/tetra/internal/config"
+	"github.com/ckayt/tetra/internal/i18n"
+	"github.com/ckayt/tetra/internal/stats"
+	"github.com/ckayt/tetra/internal/templates"
+)
+
+// Checker checks a list of URLs for reachability and response time each cycle,
+// turning tetra into a small uptime monitor for the router, a NAS or public sites.
+type Checker struct {
+	client *http.Client
+	urls   []string
+	down   map[string]bool // last known state per URL
+}
+
+func New(cfg *config.Config) *Checker {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.EndpointCheckInsecure {
+		// Router admin pages and NAS UIs usually have self-signed certificates
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &Checker{
+		client: &http.Client{Transport: transport, Timeout: cfg.EndpointCheckTimeout},
+		urls:   cfg.EndpointChecks,
+		down:   make(map[string]bool),
+	}
+}
+
+// Run checks every URL sequentially. Status codes of 400 and above count as down.
+func (c *Checker) Run(ctx context.Context) []stats.EndpointResult {
+	results := make([]stats.EndpointResult, 0, len(c.urls))
+	for _, u := range c.urls {
+		results = append(results, c.check(ctx, u))
+	}
+	return results
+}
+
+func (c *Checker) check(ctx context.Context, u string) stats.EndpointResult {
+	res := stats.EndpointResult{Time: time.Now(), URL: u}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		res.Error = err
+		return res
+	}
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		res.Error = err
+		return res
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	res.Duration = time.Since(start)
+	res.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 400 {
+		res.Error = fmt.Errorf("returned %s", resp.Status)
+	}
+	return res
+}
+
+// Changes returns messages listing the endpoints that went down and those that came back up
+// since the previous call. Either is empty if there is nothing to report.
+func (c *Checker) Changes(results []stats.EndpointResult) (down, up string) {
+	var downLines, upLines []string
+	for _, r := range results {
+		isDown := r.Error != nil
+		if isDown == c.down[r.URL] {
+			continue
+		}
+		c.down[r.URL] = isDown
+		if isDown {
+			downLines = append(downLines, i18n.T("endpoints.down", html.EscapeString(r.URL), html.EscapeString(r.Error.Error())))
+		} else {
+			line := i18n.T("endpoints.up", html.EscapeString(r.URL), r.Duration.Milliseconds())
+			data := templates.RecoveryData{Target: r.URL, Latency: r.Duration, End: r.Time, Text: line}
+			upLines = append(upLines, templates.Render(templates.Recovery, data, line))
+		}
+	}
+	return formatChanges(downLines), formatChanges(upLines)
+}
+
+func formatChanges(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return i18n.T("endpoints.title") + "\n" + strings.Join(lines, "\n")
+}
+
+// Format lists every result for a manual test reply.
+func Format(results []stats.EndpointResult) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("endpoints.title"))
+	for _, r := range results {
+		if r.Error != nil {
+			sb.WriteString("\n" + i18n.T("endpoints.failed", html.EscapeString(r.URL), html.EscapeString(r.Error.Error())))
+		} else {
+			sb.WriteString("\n" + i18n.T("endpoints.ok", html.EscapeString(r.URL), r.Duration.Milliseconds()))
+		}
+	}
+	return sb.String()
+}